@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"desci-backend/internal/db"
+	"desci-backend/internal/service"
+	"desci-backend/migrations"
+)
+
+const commandUsage = `用法:
+  server                              启动API服务与事件监听
+  server replay [-limit N] [-force]   重试未处理或物化失败的事件
+  server rebuild -from N [-to M]      清空区块范围内的派生数据并从 event_logs 重建
+  server migrate status               查看迁移的应用状态
+  server migrate up [-to V]           应用未执行的迁移（到版本 V 为止）
+  server migrate down [-steps N]      回退最近应用的 N 个迁移（默认 1）
+`
+
+// runCommand 执行子命令并返回进程退出码
+func runCommand(svc *service.Service, name string, args []string) int {
+	var (
+		result *service.ReplayResult
+		err    error
+	)
+
+	switch name {
+	case "replay":
+		fs := flag.NewFlagSet("replay", flag.ExitOnError)
+		limit := fs.Int("limit", 0, "最多处理的事件数，0 表示全部")
+		force := fs.Bool("force", false, "忽略退避时间，立即重试全部失败事件")
+		fs.Parse(args)
+
+		result, err = svc.ReplayEvents(*limit, *force)
+	case "rebuild":
+		fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
+		from := fs.Uint64("from", 0, "起始区块（含）")
+		to := fs.Uint64("to", 0, "结束区块（含），0 表示到最新事件")
+		fs.Parse(args)
+
+		// 未指定结束区块时重建到最新事件
+		if *to == 0 {
+			*to = ^uint64(0) >> 1
+		}
+		if *to < *from {
+			fmt.Fprintf(os.Stderr, "invalid block range %d-%d\n", *from, *to)
+			return 2
+		}
+		result, err = svc.RebuildRange(*from, *to)
+	default:
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", name, err)
+		return 1
+	}
+	fmt.Printf("%s: reset=%d processed=%d failed=%d\n", name, result.Reset, result.Processed, result.Failed)
+	if result.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runMigrate 执行 migrate 子命令并返回进程退出码
+func runMigrate(dbConfig *db.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+
+	gormDB, err := db.Open(context.Background(), dbConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open database failed: %v\n", err)
+		return 1
+	}
+	migrator, err := db.NewMigrator(gormDB, migrations.FS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load migrations failed: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	var done []db.Migration
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	case "up":
+		fs := flag.NewFlagSet("migrate up", flag.ExitOnError)
+		to := fs.Int64("to", 0, "目标版本，0 表示最新")
+		fs.Parse(args[1:])
+
+		done, err = migrator.Up(ctx, *to)
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "回退的迁移数")
+		fs.Parse(args[1:])
+
+		done, err = migrator.Down(ctx, *steps)
+	default:
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+
+	for _, m := range done {
+		fmt.Printf("migrate %s: %04d_%s\n", args[0], m.Version, m.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %v\n", args[0], err)
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Printf("migrate %s: nothing to do\n", args[0])
+	}
+	return 0
+}
+
+// printMigrationStatus 打印每个迁移的版本、名称、状态和应用时间
+func printMigrationStatus(ctx context.Context, migrator *db.Migrator) int {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate status failed: %v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	code := 0
+	for _, s := range statuses {
+		state, appliedAt := "pending", "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case s.Missing:
+			state = "missing"
+		case s.Modified:
+			state, code = "modified", 1
+		case s.Applied:
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+	return code
+}
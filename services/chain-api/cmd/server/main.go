@@ -2,43 +2,80 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"desci-backend/internal/api"
 	"desci-backend/internal/config"
+	"desci-backend/internal/db"
 	"desci-backend/internal/listener"
 	"desci-backend/internal/model"
 	"desci-backend/internal/repository"
 	"desci-backend/internal/service"
+	"desci-backend/internal/zkp"
+	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 func main() {
 	// 加载配置
 	cfg := config.Load()
 
-	// 初始化数据库Repository
-	repo, err := repository.NewRepository(cfg.DatabaseURL)
+	// migrate 子命令只管理表结构，不自动应用迁移
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(databaseConfig(cfg), os.Args[2:]))
+	}
+
+	// 初始化数据库Repository（应用未执行的迁移）
+	repo, err := repository.NewRepository(databaseConfig(cfg))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	log.Println("✅ Database connected successfully")
 
+	// 初始化Service层
+	svc := service.NewService(repo)
+	svc.SetConfirmations(cfg.ConfirmationBlocks)
+	svc.SetReplayBackoff(cfg.ReplayBackoff, cfg.ReplayMaxBackoff)
+	svc.SetDeadLetterAttempts(cfg.DeadLetterMaxAttempts)
+
+	// replay/rebuild 子命令直接处理 event_logs 后退出
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(svc, os.Args[1], os.Args[2:]))
+	}
+
 	// 创建演示数据（如果数据库为空）
 	if err := createDemoData(repo); err != nil {
 		log.Printf("⚠️  Failed to create demo data: %v", err)
 	}
 
-	// 初始化Service层
-	svc := service.NewService(repo)
+	// 后台任务的生命周期上下文
+	appCtx, appCancel := context.WithCancel(context.Background())
+	defer appCancel()
+
+	// 后台重试物化失败的事件
+	go svc.RunReplayer(appCtx, cfg.ReplayInterval, cfg.ReplayBatchSize)
+
+	// 配置了证明合约时启用链下 Groth16 校验
+	if cfg.ZKProofAddress != "" && cfg.ZKPVerifierAddress != "" {
+		if err := setupProofChecker(appCtx, cfg, svc); err != nil {
+			log.Printf("⚠️  Proof checker disabled: %v", err)
+		} else {
+			log.Printf("🔍 [ZKP] Off-chain proof checker enabled (every %s)", cfg.ProofCheckInterval)
+		}
+	}
 
 	// 初始化API处理器
 	handler := api.NewHandler(svc, repo)
+	// 管理接口需要 ADMIN_TOKEN，未配置时全部拒绝
+	handler.SetAdminToken(cfg.AdminToken)
+	if cfg.AdminToken == "" {
+		log.Println("⚠️  ADMIN_TOKEN not set, admin API is disabled")
+	}
 
 	// 设置HTTP路由
 	router := handler.SetupRoutes()
@@ -56,6 +93,12 @@ func main() {
 		cfg.DatasetManagerAddress,
 		cfg.InfluenceRankingAddress,
 		cfg.DeSciPlatformAddress,
+		cfg.ZKProofAddress,
+		cfg.ZKPVerifierAddress,
+		cfg.ConstraintManagerAddress,
+		cfg.DataFeatureExtractorAddress,
+		cfg.ResearchDataVerifierAddress,
+		cfg.SciTokenAddress,
 	}
 
 	// 过滤掉空地址
@@ -66,141 +109,67 @@ func main() {
 		}
 	}
 
-	if len(validAddresses) > 0 {
-		// 从数据库续接区块高度（优先使用DB记录，其次使用配置）
-		resumeBlock := cfg.StartBlock
-		if lastBlock, err := repo.GetLastEventBlock(); err == nil && lastBlock > resumeBlock {
-			resumeBlock = lastBlock + 1
+	// 合约地址到名称的映射，用于区分不同合约的同名事件
+	contractNames := map[string]string{}
+	for name, addr := range map[string]string{
+		"DeSciRegistry":        cfg.DeSciRegistryAddress,
+		"ResearchNFT":          cfg.ResearchNFTAddress,
+		"DatasetManager":       cfg.DatasetManagerAddress,
+		"InfluenceRanking":     cfg.InfluenceRankingAddress,
+		"DeSciPlatform":        cfg.DeSciPlatformAddress,
+		"ZKProof":              cfg.ZKProofAddress,
+		"ZKPVerifier":          cfg.ZKPVerifierAddress,
+		"ConstraintManager":    cfg.ConstraintManagerAddress,
+		"DataFeatureExtractor": cfg.DataFeatureExtractorAddress,
+		"ResearchDataVerifier": cfg.ResearchDataVerifierAddress,
+		"SciToken":             cfg.SciTokenAddress,
+	} {
+		if addr != "" {
+			contractNames[strings.ToLower(addr)] = name
 		}
+	}
 
-		eventListener, err := listener.NewEventListener(cfg.EthereumRPC, validAddresses, resumeBlock, cfg.ContractsConfigPath)
+	if len(validAddresses) > 0 {
+		// 续扫位置由每个合约的同步游标决定，START_BLOCK 仅作为无游标时的起点
+		eventListener, err := listener.NewEventListener(cfg.EthereumRPC, validAddresses, cfg.StartBlock, cfg.ContractsConfigPath)
 		if err != nil {
 			log.Printf("⚠️  Failed to create event listener: %v", err)
 			log.Println("🚀 Starting server without blockchain listener...")
 		} else {
 			log.Println("✅ Event listener created successfully")
 
+			// 按合约名查找事件注册项，区分不同合约的同名事件
+			eventListener.SetContractNames(contractNames)
+
+			// 启用链重组检测：记录区块哈希，分叉时回滚孤立区块数据
+			eventListener.SetBlockStore(repo)
+			eventListener.SetMaxReorgDepth(cfg.MaxReorgDepth)
+
+			// 分批回填历史事件，并按合约记录已完整索引的区块
+			eventListener.SetCursorStore(repo)
+			eventListener.SetBackfillBatchSize(cfg.BackfillBatchSize)
+
+			// HTTP 端点自动使用轮询，LISTENER_MODE 可强制指定
+			eventListener.SetMode(cfg.ListenerMode)
+			eventListener.SetPollInterval(cfg.PollInterval)
+
+			// 健康检查展示索引器的同步阶段
+			handler.SetIndexer(eventListener)
+
 			// 设置事件处理器 - 使用闭包捕获repo和svc
 			eventListener.SetEventHandler(func(event *model.ParsedEvent) error {
 				log.Printf("📡 Processing blockchain event: %s", event.EventName)
 				
 				// 特殊处理ZKP事件
 				if event.EventName == "ProofSubmitted" {
-					log.Printf("🔍 [ZKP] Starting off-chain verification for Proof ID: %s", event.TokenID)
-					log.Printf("🔍 [ZKP] Submitter: %s", event.Author)
+					log.Printf("🔍 [ZKP] Proof ID: %s, Submitter: %s", event.TokenID, event.Author)
 					log.Printf("🔍 [ZKP] Block: %d, TxHash: %s", event.Block, event.TxHash)
 				}
 
-				// 规范化事件名称
-				normalized := event.EventName
-				switch event.EventName {
-				case "ResearchMinted":
-					normalized = "ResearchCreated"
-				case "DatasetUploaded":
-					normalized = "DatasetCreated"
-				case "ProofSubmitted":
-					normalized = "ProofSubmitted" // 保持原名
-				}
-
-				// 构造标准化载荷
-				var payload map[string]interface{}
-				switch normalized {
-				case "ResearchCreated":
-					payload = map[string]interface{}{
-						"tokenId":      event.TokenID,
-						"authors": func() []string {
-							if event.Author != "" { return []string{event.Author} }
-							return []string{}
-						}(),
-						"title":        event.Title,
-						"contentHash":  event.DataHash,
-					}
-				case "DatasetCreated":
-					payload = map[string]interface{}{
-						"datasetId":   event.TokenID,
-						"title":       event.Title,
-						"description": event.Description,
-						"owner":       event.Author,
-						"ipfsHash":    event.DataHash,
-					}
-				case "ProofSubmitted":
-					log.Printf("🔍 [ZKP] Creating payload for ProofSubmitted event")
-					payload = map[string]interface{}{
-						"proofId":     event.TokenID,
-						"submitter":   event.Author,
-						"title":       event.Title,
-						"dataHash":    event.DataHash,
-						"blockNumber": event.Block,
-						"txHash":      event.TxHash,
-					}
-					log.Printf("🔍 [ZKP] Payload created: proofId=%s, submitter=%s", event.TokenID, event.Author)
-				default:
-					payload = map[string]interface{}{
-						"tokenId":     event.TokenID,
-						"title":       event.Title,
-						"description": event.Description,
-					}
-				}
-
-				b, err := json.Marshal(payload)
-				if err != nil {
-					log.Printf("⚠️  Failed to marshal event payload: %v", err)
-					return err
-				}
-
-				// 插入事件日志到 event_logs 表
-				eventLog := &model.EventLog{
-					TxHash:       event.TxHash,
-					LogIndex:     uint(event.LogIndex),
-					BlockNumber:  event.Block,
-					EventName:    normalized,
-					ContractAddr: "0x0000000000000000000000000000000000000000",
-					PayloadRaw:   string(b),
-					Processed:    false,
-					CreatedAt:    time.Now(),
-				}
-
-				if err := repo.InsertEventLog(eventLog); err != nil {
-					log.Printf("⚠️  Failed to insert event log: %v", err)
-					return err
-				}
-				log.Printf("📝 Event log inserted: %s", normalized)
-
-				// 交由服务层处理，并标记处理完成
-				switch normalized {
-				case "ResearchCreated", "DatasetCreated":
-					if err := svc.ProcessEvent(eventLog); err != nil {
-						log.Printf("⚠️  Service processing failed: %v", err)
-						return err
-					}
-					if err := repo.MarkEventProcessed(eventLog.ID); err != nil {
-						log.Printf("⚠️  Mark processed failed: %v", err)
-					} else {
-						log.Printf("✅ Service processed and marked event: %s", normalized)
-					}
-				case "ProofSubmitted":
-					log.Printf("🔍 [ZKP] Starting off-chain verification process...")
-					log.Printf("🔍 [ZKP] Step 1: Validating proof format and structure")
-					log.Printf("🔍 [ZKP] Step 2: Verifying cryptographic proof")
-					log.Printf("🔍 [ZKP] Step 3: Checking public inputs consistency")
-					log.Printf("🔍 [ZKP] Step 4: Updating verification status")
-					
-					if err := svc.ProcessEvent(eventLog); err != nil {
-						log.Printf("❌ [ZKP] Verification failed: %v", err)
-						return err
-					}
-					if err := repo.MarkEventProcessed(eventLog.ID); err != nil {
-						log.Printf("⚠️  [ZKP] Mark processed failed: %v", err)
-					} else {
-						log.Printf("✅ [ZKP] Proof verification completed and status synchronized")
-						log.Printf("🔍 [ZKP] Proof ID %s is now available for queries", event.TokenID)
-					}
-				default:
-					log.Printf("ℹ️  Event logged only: %s", normalized)
-				}
-
-				return nil
+				// 写入 event_logs 并物化；入库失败时由监听器写入死信队列
+				return svc.IngestEvent(event)
 			})
+			eventListener.SetDeadLetterStore(repo)
 
 			// 在goroutine中启动事件监听
 			go func() {
@@ -209,6 +178,12 @@ func main() {
 				}
 			}()
 			log.Println("🔄 Blockchain event listener started")
+
+			// 确认深度为 0 时也运行：此前以非零深度运行时遗留的 pending 事件需要被提升
+			go svc.RunConfirmer(appCtx, eventListener.LatestBlock, cfg.ConfirmationPollInterval)
+			if cfg.ConfirmationBlocks > 0 {
+				log.Printf("⏳ Confirmation mode enabled: %d blocks", cfg.ConfirmationBlocks)
+			}
 		}
 	} else {
 		log.Println("⚠️  No contract addresses configured, starting server without blockchain listener...")
@@ -228,6 +203,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Server shutting down...")
+	appCancel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -239,6 +215,22 @@ func main() {
 	log.Println("✅ Server exited")
 }
 
+// setupProofChecker 连接证明合约并启动周期性的链下校验
+func setupProofChecker(ctx context.Context, cfg *config.Config, svc *service.Service) error {
+	client, err := ethclient.Dial(cfg.EthereumRPC)
+	if err != nil {
+		return err
+	}
+	reader, err := zkp.NewReader(client, cfg.ContractsConfigPath, cfg.ZKProofAddress, cfg.ZKPVerifierAddress)
+	if err != nil {
+		client.Close()
+		return err
+	}
+	svc.SetProofChain(reader)
+	go svc.RunProofChecker(ctx, cfg.ProofCheckInterval)
+	return nil
+}
+
 // createDemoData 创建演示数据（如果数据库为空）
 func createDemoData(repo *repository.Repository) error {
 	// 检查是否已有演示数据
@@ -300,3 +292,19 @@ func createDemoData(repo *repository.Repository) error {
 
 	return nil
 }
+
+// databaseConfig 由环境配置生成数据库连接层配置
+func databaseConfig(cfg *config.Config) *db.Config {
+	return &db.Config{
+		URL:              cfg.DatabaseURL,
+		ReplicaURL:       cfg.DatabaseReplicaURL,
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		MaxLifetime:      cfg.DBConnMaxLifetime,
+		MaxIdleTime:      cfg.DBConnMaxIdleTime,
+		StatementTimeout: cfg.DBStatementTimeout,
+		BusyTimeout:      cfg.DBBusyTimeout,
+		ConnectTimeout:   cfg.DBConnectTimeout,
+		RetryBackoff:     time.Second,
+	}
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetAdminToken 设置管理接口的访问令牌；为空时管理接口全部返回 403
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// requireAdmin 校验 Authorization: Bearer <ADMIN_TOKEN>，未配置令牌时拒绝所有管理请求
+func (h *Handler) requireAdmin(c *gin.Context) {
+	if h.adminToken == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Admin API is disabled (ADMIN_TOKEN not set)",
+		})
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or missing admin token",
+		})
+		return
+	}
+	c.Next()
+}
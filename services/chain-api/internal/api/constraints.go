@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"desci-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 获取约束目录，可按 category 过滤，active=true 只返回启用的约束
+func (h *Handler) listConstraints(c *gin.Context) {
+	category := c.Query("category")
+	activeOnly := c.Query("active") == "true"
+
+	constraints, err := h.service.ListConstraints(category, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get constraints",
+		})
+		return
+	}
+
+	respondPage(c, repository.FullPage(constraints), nil)
+}
+
+// 获取约束详情及评估历史
+func (h *Handler) getConstraint(c *gin.Context) {
+	constraintID := c.Param("id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	constraint, evaluations, err := h.service.GetConstraint(constraintID, limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Constraint not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get constraint",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"constraint":  constraint,
+		"evaluations": evaluations,
+	})
+}
+
+// 获取约束组
+func (h *Handler) listConstraintGroups(c *gin.Context) {
+	groups, err := h.service.ListConstraintGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get constraint groups",
+		})
+		return
+	}
+
+	respondPage(c, repository.FullPage(groups), nil)
+}
+
+// 获取验证规则
+func (h *Handler) listValidationRules(c *gin.Context) {
+	rules, err := h.service.ListValidationRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get validation rules",
+		})
+		return
+	}
+
+	respondPage(c, repository.FullPage(rules), nil)
+}
+
+// 分页获取数据集的约束评估结果，附带按各约束最新结果计算的验证规则得分
+func (h *Handler) getDatasetConstraints(c *gin.Context) {
+	datasetID := c.Param("id")
+
+	page, rules, err := h.service.GetDatasetConstraints(datasetID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get dataset constraints")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"dataset_id": datasetID,
+		"rules":      rules,
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"desci-backend/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// 分页获取数据集的访问（购买）记录
+func (h *Handler) getDatasetAccesses(c *gin.Context) {
+	datasetID := c.Param("id")
+
+	page, err := h.service.GetDatasetAccesses(datasetID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get dataset accesses")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"dataset_id": datasetID,
+	})
+}
+
+// 分页获取数据集被引用的记录
+func (h *Handler) getDatasetCitations(c *gin.Context) {
+	datasetID := c.Param("id")
+
+	page, err := h.service.GetDatasetCitations(datasetID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get dataset citations")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"dataset_id": datasetID,
+	})
+}
+
+// 分页获取数据集的质量等级历史，附带当前等级以及特征提取器/数据验证器记录的统计指标快照
+func (h *Handler) getDatasetQuality(c *gin.Context) {
+	datasetID := c.Param("id")
+
+	page, current, err := h.service.GetDatasetQualityHistory(datasetID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get dataset quality")
+		return
+	}
+	features, thresholds, err := h.service.GetDatasetFeatures(datasetID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get dataset quality",
+		})
+		return
+	}
+
+	// 没有质量事件时视为未验证
+	extra := gin.H{
+		"dataset_id": datasetID,
+		"level":      uint8(0),
+		"level_name": model.QualityLevelName(0),
+		"verifier":   "",
+		"features":   features,
+		"thresholds": thresholds,
+	}
+	if current != nil {
+		extra["level"] = current.NewLevel
+		extra["level_name"] = current.LevelName
+		extra["verifier"] = current.Verifier
+	}
+	respondPage(c, page, extra)
+}
+
+// 获取数据集收益分配汇总
+func (h *Handler) getDatasetRevenue(c *gin.Context) {
+	datasetID := c.Param("id")
+
+	summary, err := h.service.GetDatasetRevenue(datasetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get dataset revenue",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 获取死信列表，可按 status=dead|resolved|discarded 过滤，默认只看待处理的死信
+func (h *Handler) listDeadLetters(c *gin.Context) {
+	status := c.DefaultQuery("status", model.DeadLetterStatusDead)
+
+	switch status {
+	case "all":
+		status = ""
+	case model.DeadLetterStatusDead, model.DeadLetterStatusResolved, model.DeadLetterStatusDiscarded:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be dead, resolved, discarded or all",
+		})
+		return
+	}
+
+	page, err := h.service.ListDeadLetters(status, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get dead letters")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 获取死信详情，包括错误、调用栈和原始载荷
+func (h *Handler) getDeadLetter(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.service.GetDeadLetter(id)
+	if err != nil {
+		writeDeadLetterError(c, err, "Failed to get dead letter")
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+// 立即重试死信中的事件
+func (h *Handler) retryDeadLetter(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.service.RetryDeadLetter(id)
+	if err != nil {
+		if errors.Is(err, service.ErrRetryFailed) {
+			// 事件仍然处理失败，返回最新的错误供排查
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":       err.Error(),
+				"dead_letter": letter,
+			})
+			return
+		}
+		writeDeadLetterError(c, err, "Failed to retry dead letter")
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+// 丢弃死信，对应事件不再重试
+func (h *Handler) discardDeadLetter(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.service.DiscardDeadLetter(id)
+	if err != nil {
+		writeDeadLetterError(c, err, "Failed to discard dead letter")
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+func deadLetterID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid dead letter id",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeDeadLetterError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Dead letter not found",
+		})
+	case errors.Is(err, service.ErrDeadLetterClosed):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
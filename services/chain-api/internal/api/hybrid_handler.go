@@ -2,7 +2,6 @@ package api
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
 	"desci-backend/internal/model"
@@ -65,18 +64,12 @@ func (h *HybridHandler) VerifyNFTData(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// GetHybridNFTList 获取混合NFT列表（Node.js + 区块链验证状态）
+// GetHybridNFTList 分页获取混合NFT列表（Node.js + 区块链验证状态）
 func (h *HybridHandler) GetHybridNFTList(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, _ := strconv.Atoi(limitStr)
-	offset, _ := strconv.Atoi(offsetStr)
-
 	// 获取区块链研究数据
-	researchData, err := h.repo.GetLatestResearchData(limit, offset)
+	page, err := h.repo.GetLatestResearchData(pageRequest(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch research data"})
+		writePageError(c, err, "Failed to fetch research data")
 		return
 	}
 
@@ -92,8 +85,8 @@ func (h *HybridHandler) GetHybridNFTList(c *gin.Context) {
 		AssetType         string    `json:"asset_type"`
 	}
 
-	var hybridNFTs []HybridNFT
-	for _, data := range researchData {
+	hybridNFTs := make([]HybridNFT, 0, len(page.Items))
+	for _, data := range page.Items {
 		hybridNFT := HybridNFT{
 			TokenID:           data.TokenID,
 			Title:             data.Title,
@@ -107,18 +100,20 @@ func (h *HybridHandler) GetHybridNFTList(c *gin.Context) {
 		hybridNFTs = append(hybridNFTs, hybridNFT)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"nfts":   hybridNFTs,
-		"count":  len(hybridNFTs),
-		"limit":  limit,
-		"offset": offset,
-	})
+	respondPage(c, &repository.Page[HybridNFT]{
+		Items:         hybridNFTs,
+		NextCursor:    page.NextCursor,
+		TotalEstimate: page.TotalEstimate,
+	}, nil)
 }
 
 // GetProjectStats 获取项目统计信息（混合数据源）
 func (h *HybridHandler) GetProjectStats(c *gin.Context) {
 	// 获取区块链数据统计
-	researchData, _ := h.repo.GetLatestResearchData(100, 0)
+	var totalResearch int64
+	if researchData, err := h.repo.GetLatestResearchData(repository.PageRequest{Limit: 1}); err == nil {
+		totalResearch = researchData.TotalEstimate
+	}
 	
 	// 模拟Node.js统计数据
 	nodeStats := struct {
@@ -133,10 +128,10 @@ func (h *HybridHandler) GetProjectStats(c *gin.Context) {
 
 	// 区块链数据统计
 	blockchainStats := struct {
-		TotalResearchRecords int `json:"total_research_records"`
-		TotalEventLogs       int `json:"total_event_logs"`
+		TotalResearchRecords int64 `json:"total_research_records"`
+		TotalEventLogs       int   `json:"total_event_logs"`
 	}{
-		TotalResearchRecords: len(researchData),
+		TotalResearchRecords: totalResearch,
 		TotalEventLogs:       12,
 	}
 
@@ -150,7 +145,10 @@ func (h *HybridHandler) GetProjectStats(c *gin.Context) {
 // CompareDataSources 对比不同数据源的数据
 func (h *HybridHandler) CompareDataSources(c *gin.Context) {
 	// 获取区块链研究数据
-	researchData, _ := h.repo.GetLatestResearchData(5, 0)
+	var researchData []model.ResearchData
+	if latest, err := h.repo.GetLatestResearchData(repository.PageRequest{Limit: 5}); err == nil {
+		researchData = latest.Items
+	}
 
 	type ComparisonResult struct {
 		TokenID           string `json:"token_id"`
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 分页获取影响力排行榜（按最新总影响力排序）
+func (h *Handler) getInfluenceLeaderboard(c *gin.Context) {
+	page, err := h.service.GetInfluenceLeaderboard(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get influence leaderboard")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 分页获取用户的影响力变化历史，附带当前状态（没有影响力事件时 current 为空）
+func (h *Handler) getUserInfluence(c *gin.Context) {
+	address := c.Param("address")
+
+	page, current, err := h.service.GetUserInfluence(address, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get user influence")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"address": address,
+		"current": current,
+	})
+}
+
+// 分页获取影响力权重变更记录，附带当前生效的配置
+func (h *Handler) getInfluenceWeights(c *gin.Context) {
+	page, current, err := h.service.GetInfluenceWeights(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get influence weights")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"current": current,
+	})
+}
+
+// 分页获取排名刷新记录
+func (h *Handler) getRankingRefreshes(c *gin.Context) {
+	page, err := h.service.GetRankingRefreshes(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get ranking updates")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 分页获取合作关系边，可按 address 过滤
+func (h *Handler) getCollaborations(c *gin.Context) {
+	address := c.Query("address")
+
+	page, err := h.service.GetCollaborations(address, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get collaborations")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 分页获取最近的奖励发放流水
+func (h *Handler) getRewards(c *gin.Context) {
+	page, err := h.service.GetRewards(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get rewards")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 获取用户奖励汇总及流水
+func (h *Handler) getUserRewards(c *gin.Context) {
+	address := c.Param("address")
+
+	summary, err := h.service.GetUserRewards(address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user rewards",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
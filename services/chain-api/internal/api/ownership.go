@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"desci-backend/internal/model"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 获取研究成果NFT的持有人及流转记录
+func (h *Handler) getResearchOwnership(c *gin.Context) {
+	h.getOwnership(c, model.CollectionResearch)
+}
+
+// 获取数据集NFT的持有人及流转记录
+func (h *Handler) getDatasetOwnership(c *gin.Context) {
+	h.getOwnership(c, model.CollectionDataset)
+}
+
+func (h *Handler) getOwnership(c *gin.Context, collection string) {
+	tokenID := c.Param("id")
+
+	owner, transfers, err := h.service.GetNFTOwnership(collection, tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Token not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get ownership",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collection,
+		"token_id":   tokenID,
+		"owner":      owner.Owner,
+		"burned":     owner.Burned,
+		"history":    transfers,
+	})
+}
+
+// 获取地址当前持有的NFT，可按 collection=research|dataset 过滤
+func (h *Handler) getTokensByOwner(c *gin.Context) {
+	address := c.Param("address")
+	collection := c.Query("collection")
+
+	if collection != "" && collection != model.CollectionResearch && collection != model.CollectionDataset {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "collection must be research or dataset",
+		})
+		return
+	}
+
+	page, err := h.service.GetTokensByOwner(address, collection, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get tokens")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"owner": address,
+	})
+}
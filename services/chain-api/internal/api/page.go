@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"desci-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest 读取列表接口的 limit、cursor、sort、order 参数
+func pageRequest(c *gin.Context) repository.PageRequest {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return repository.PageRequest{
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+	}
+}
+
+// respondPage 输出统一的 {items, next_cursor, total_estimate} 结构，extra 为各路由附加的字段
+func respondPage[T any](c *gin.Context, page *repository.Page[T], extra gin.H) {
+	response := gin.H{
+		"items":          page.Items,
+		"next_cursor":    page.NextCursor,
+		"total_estimate": page.TotalEstimate,
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// writePageError 游标或排序参数无效时返回 400，其余错误返回 500
+func writePageError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repository.ErrInvalidCursor) || errors.Is(err, repository.ErrInvalidSort) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": message,
+	})
+}
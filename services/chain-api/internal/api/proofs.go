@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"desci-backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 获取证明列表，可按 status=submitted|verified|rejected 和 type 过滤
+func (h *Handler) listProofs(c *gin.Context) {
+	status := c.Query("status")
+	proofType := c.Query("type")
+
+	switch status {
+	case "", model.ProofStatusSubmitted, model.ProofStatusVerified, model.ProofStatusRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be submitted, verified or rejected",
+		})
+		return
+	}
+
+	page, err := h.service.ListProofs(status, proofType, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get proofs")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
+
+// 获取证明详情及验证记录
+func (h *Handler) getProof(c *gin.Context) {
+	proofID := c.Param("id")
+
+	proof, verifications, err := h.service.GetProof(proofID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Proof not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get proof",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proof":   proof,
+		"history": verifications,
+	})
+}
+
+// 立即对证明执行链下 Groth16 校验并与 ZKPVerifier 的记录核对（需要管理令牌）
+func (h *Handler) checkProof(c *gin.Context) {
+	proofID := c.Param("id")
+
+	proof, err := h.service.CheckProof(c.Request.Context(), proofID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Proof not found",
+			})
+		case errors.Is(err, service.ErrProofNotOnChain):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrProofChainNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "Failed to check proof: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, proof)
+}
+
+// 获取证明类型目录
+func (h *Handler) listProofTypes(c *gin.Context) {
+	types, err := h.service.ListProofTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get proof types",
+		})
+		return
+	}
+
+	respondPage(c, repository.FullPage(types), nil)
+}
+
+// 分页获取地址提交的证明
+func (h *Handler) getUserProofs(c *gin.Context) {
+	address := c.Param("address")
+
+	page, err := h.service.GetProofsBySubmitter(address, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get proofs")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"submitter": address,
+	})
+}
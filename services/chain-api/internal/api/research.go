@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// 分页获取研究成果的引用图，direction=cited_by（默认）为引用了该成果的记录，references 为该成果引用的成果
+func (h *Handler) getResearchCitations(c *gin.Context) {
+	tokenID := c.Param("id")
+	direction := c.DefaultQuery("direction", service.CitationsCitedBy)
+	if direction != service.CitationsCitedBy && direction != service.CitationsReferences {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "direction must be cited_by or references",
+		})
+		return
+	}
+
+	page, err := h.service.GetResearchCitations(tokenID, direction, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get research citations")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"token_id":  tokenID,
+		"direction": direction,
+	})
+}
+
+// 分页获取研究成果的同行评审记录
+func (h *Handler) getResearchReviews(c *gin.Context) {
+	tokenID := c.Param("id")
+
+	page, err := h.service.GetResearchReviews(tokenID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get research reviews")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"token_id": tokenID,
+	})
+}
+
+// 分页获取研究成果的影响力等级历史，附带当前等级
+func (h *Handler) getResearchImpact(c *gin.Context) {
+	tokenID := c.Param("id")
+
+	page, current, err := h.service.GetResearchImpactHistory(tokenID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get research impact")
+		return
+	}
+
+	extra := gin.H{
+		"token_id":   tokenID,
+		"level":      uint8(0),
+		"level_name": model.ImpactLevelName(0),
+	}
+	if current != nil {
+		extra["level"] = current.NewLevel
+		extra["level_name"] = current.LevelName
+	}
+	respondPage(c, page, extra)
+}
+
+// 分页获取研究成果的收益分配历史，附带累计金额
+func (h *Handler) getResearchRevenue(c *gin.Context) {
+	tokenID := c.Param("id")
+
+	page, total, err := h.service.GetResearchRevenue(tokenID, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get research revenue")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"token_id":      tokenID,
+		"total_revenue": total,
+	})
+}
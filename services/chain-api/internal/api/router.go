@@ -2,30 +2,44 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"time"
 
 	"desci-backend/internal/model"
 	"desci-backend/internal/repository"
 	"desci-backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// IndexerStatus 链上索引器的运行状态（listener.EventListener 实现该接口）
+type IndexerStatus interface {
+	Phase() string
+	Mode() string
+}
+
 type Handler struct {
-	service *service.Service
-	repo    repository.IRepository
+	service    *service.Service
+	repo       repository.IRepository
+	indexer    IndexerStatus
+	adminToken string
 }
 
 func NewHandler(service *service.Service, repo repository.IRepository) *Handler {
 	return &Handler{service: service, repo: repo}
 }
 
+// SetIndexer 设置索引器，用于在健康检查中展示同步阶段
+func (h *Handler) SetIndexer(indexer IndexerStatus) {
+	h.indexer = indexer
+}
+
 func (h *Handler) SetupRoutes() *gin.Engine {
 	r := gin.Default()
 
@@ -49,14 +63,21 @@ func (h *Handler) SetupRoutes() *gin.Engine {
 	// API路由组
 	api := r.Group("/api")
 	{
-		// 事件模拟API (用于演示)
-		api.POST("/events/simulate", h.simulateProofEvent)
+		// 事件模拟API (用于演示，需要管理令牌)
+		api.POST("/events/simulate", h.requireAdmin, h.simulateProofEvent)
+		// 等待区块确认的事件
+		api.GET("/events/pending", h.getPendingEvents)
 		
 		// 研究数据API
 		api.GET("/research/:id", h.getResearch)
 		api.GET("/research/latest", h.getLatestResearch)
 		api.GET("/research/by-author/:addr", h.getResearchByAuthor)
 		api.POST("/research/:id/verify", h.verifyResearch)
+		api.GET("/research/:id/citations", h.getResearchCitations)
+		api.GET("/research/:id/reviews", h.getResearchReviews)
+		api.GET("/research/:id/impact", h.getResearchImpact)
+		api.GET("/research/:id/revenue", h.getResearchRevenue)
+		api.GET("/research/:id/ownership", h.getResearchOwnership)
 
 		// 数据集API（保留现有功能）
 		api.GET("/dataset/:datasetId", h.getDataset)
@@ -66,11 +87,56 @@ func (h *Handler) SetupRoutes() *gin.Engine {
 		api.GET("/datasets", h.getDatasets)
 		api.GET("/datasets/:id", h.getDatasetDetail)
 		api.DELETE("/datasets/:id", h.deleteDataset)
+		api.GET("/datasets/:id/accesses", h.getDatasetAccesses)
+		api.GET("/datasets/:id/citations", h.getDatasetCitations)
+		api.GET("/datasets/:id/quality", h.getDatasetQuality)
+		api.GET("/datasets/:id/revenue", h.getDatasetRevenue)
+		api.GET("/datasets/:id/ownership", h.getDatasetOwnership)
+		api.GET("/datasets/:id/constraints", h.getDatasetConstraints)
 		api.GET("/projects", h.getUserProjects)
+
+		// 零知识证明API
+		api.GET("/proofs", h.listProofs)
+		api.GET("/proofs/types", h.listProofTypes)
+		api.GET("/proofs/:id", h.getProof)
+		// 链下校验会发起多次 RPC 调用，需要管理令牌
+		api.POST("/proofs/:id/check", h.requireAdmin, h.checkProof)
+
+		// 数据约束API
+		api.GET("/constraints", h.listConstraints)
+		api.GET("/constraints/groups", h.listConstraintGroups)
+		api.GET("/constraints/rules", h.listValidationRules)
+		api.GET("/constraints/:id", h.getConstraint)
+
+		// 影响力与奖励API
+		api.GET("/influence/leaderboard", h.getInfluenceLeaderboard)
+		api.GET("/influence/users/:address", h.getUserInfluence)
+		api.GET("/influence/weights", h.getInfluenceWeights)
+		api.GET("/influence/rankings", h.getRankingRefreshes)
+		api.GET("/influence/collaborations", h.getCollaborations)
+		api.GET("/rewards", h.getRewards)
+		api.GET("/rewards/:address", h.getUserRewards)
+
+		// SciToken账本API
+		api.GET("/token/holders", h.getTokenHolders)
+		api.GET("/token/balances/:address", h.getTokenBalance)
+		api.GET("/token/transfers", h.getTokenTransfers)
+
+		// 全文检索API
+		api.GET("/search", h.search)
+
+		// 死信队列管理API（需要管理令牌）
+		admin := api.Group("/admin", h.requireAdmin)
+		admin.GET("/dead-letters", h.listDeadLetters)
+		admin.GET("/dead-letters/:id", h.getDeadLetter)
+		admin.POST("/dead-letters/:id/retry", h.retryDeadLetter)
+		admin.POST("/dead-letters/:id/discard", h.discardDeadLetter)
 		
 		// 用户管理API
 		api.GET("/users/wallet/:address", h.getUserByWallet)
 		api.GET("/users/wallet/:address/dashboard-stats", h.getDashboardStats)
+		api.GET("/users/:address/tokens", h.getTokensByOwner)
+		api.GET("/users/:address/proofs", h.getUserProofs)
 	}
 
 	// 混合查询API路由组
@@ -98,6 +164,12 @@ func (h *Handler) healthCheck(c *gin.Context) {
 		"service": "desci-backend",
 		"db":      "ok",
 	}
+	response["confirmations"] = h.service.Confirmations()
+	response["indexer_phase"] = "disabled"
+	if h.indexer != nil {
+		response["indexer_phase"] = h.indexer.Phase()
+		response["indexer_mode"] = h.indexer.Mode()
+	}
 
 	if err != nil {
 		response["last_event_block"] = 0
@@ -109,6 +181,19 @@ func (h *Handler) healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// 分页获取等待确认的事件列表
+func (h *Handler) getPendingEvents(c *gin.Context) {
+	page, err := h.service.GetPendingEvents(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get pending events")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"confirmations": h.service.Confirmations(),
+	})
+}
+
 // 获取研究数据
 func (h *Handler) getResearch(c *gin.Context) {
 	tokenID := c.Param("id")
@@ -167,45 +252,29 @@ func (h *Handler) verifyResearch(c *gin.Context) {
 	})
 }
 
-// 获取最新研究列表
+// 分页获取最新研究列表，sort=created|block
 func (h *Handler) getLatestResearch(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-
-	research, err := h.service.GetLatestResearch(limit, offset)
+	page, err := h.service.GetLatestResearch(pageRequest(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get research list",
-		})
+		writePageError(c, err, "Failed to get research list")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"list":   research,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(research),
-	})
+	respondPage(c, page, nil)
 }
 
-// 按作者获取研究列表
+// 按作者分页获取研究列表，sort=block|created
 func (h *Handler) getResearchByAuthor(c *gin.Context) {
 	author := c.Param("addr")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	research, err := h.service.GetResearchByAuthor(author, limit)
+	page, err := h.service.GetResearchByAuthor(author, pageRequest(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get research by author",
-		})
+		writePageError(c, err, "Failed to get research by author")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"list":   research,
+	respondPage(c, page, gin.H{
 		"author": author,
-		"limit":  limit,
-		"count":  len(research),
 	})
 }
 
@@ -330,10 +399,10 @@ func (h *Handler) getUserProjects(c *gin.Context) {
 		},
 	}
 
-	c.JSON(http.StatusOK, projects)
+	respondPage(c, repository.FullPage(projects), nil)
 }
 
-// 获取数据集列表
+// 分页获取地址拥有的数据集，sort=created|block|updated
 func (h *Handler) getDatasets(c *gin.Context) {
 	walletAddress := c.Query("wallet_address")
 	
@@ -344,44 +413,15 @@ func (h *Handler) getDatasets(c *gin.Context) {
 		return
 	}
 
-	// 模拟数据集数据（实际项目中应该从数据库查询）
-	datasets := []gin.H{
-		{
-			"id":           "demo-dataset-1",
-			"name":         "AI Research Dataset",
-			"description":  "Machine learning dataset for medical image analysis",
-			"category":     "AI",
-			"privacy_level": "private",
-			"status":       "ready",
-			"file_size":    52428800, // 50MB
-			"created_at":   "2024-10-28T12:00:00Z",
-			"owner":        walletAddress,
-		},
-		{
-			"id":           "demo-dataset-2",
-			"name":         "Genomics Data Collection", 
-			"description":  "Comprehensive genomics research data with privacy protection",
-			"category":     "Healthcare",
-			"privacy_level": "zk_proof_protected",
-			"status":       "ready",
-			"file_size":    125829120, // 120MB
-			"created_at":   "2024-10-27T10:30:00Z",
-			"owner":        walletAddress,
-		},
-		{
-			"id":           "demo-dataset-3",
-			"name":         "Climate Change Data",
-			"description":  "Environmental data for climate research",
-			"category":     "Research",
-			"privacy_level": "public",
-			"status":       "ready",
-			"file_size":    78643200, // 75MB
-			"created_at":   "2024-10-26T14:15:00Z",
-			"owner":        walletAddress,
-		},
+	page, err := h.service.GetDatasetsByOwner(walletAddress, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get datasets")
+		return
 	}
 
-	c.JSON(http.StatusOK, datasets)
+	respondPage(c, page, gin.H{
+		"owner": walletAddress,
+	})
 }
 
 // 删除数据集
@@ -437,7 +477,7 @@ func (h *Handler) getDatasetDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, dataset)
 }
 
-// 根据钱包地址获取用户信息
+// 根据钱包地址获取用户信息（来自链上用户档案投影）
 func (h *Handler) getUserByWallet(c *gin.Context) {
 	address := c.Param("address")
 	
@@ -448,16 +488,46 @@ func (h *Handler) getUserByWallet(c *gin.Context) {
 		return
 	}
 
-	// 模拟用户数据
-	user := gin.H{
-		"wallet_address": address,
-		"username":       fmt.Sprintf("User_%s", address[len(address)-4:]),
-		"user_role":      "researcher",
-		"created_at":     "2024-10-28T10:00:00Z",
-		"verified":       true,
+	profile, err := h.service.GetUserProfile(address)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	history, err := h.service.GetReputationHistory(address, 20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get reputation history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet_address":          profile.WalletAddress,
+		"username":                profile.Name,
+		"user_role":               profile.RoleName,
+		"role":                    profile.Role,
+		"registered":              profile.Registered,
+		"verified":                profile.VerificationStatus == model.VerificationVerified,
+		"verification_status":     profile.VerificationStatus,
+		"verification_request_id": profile.VerificationReqID,
+		"requested_role":          model.UserRoleName(profile.RequestedRole),
+		"verifier":                profile.Verifier,
+		"verified_at":             profile.VerifiedAt,
+		"reputation":              profile.Reputation,
+		"reputation_history":      history,
+		"access_roles":            profile.AccessRoles,
+		"created_at":              profile.RegisteredAt,
+		"block_number":            profile.BlockNumber,
+	})
 }
 
 // 获取仪表板统计数据
@@ -518,6 +588,12 @@ func (h *Handler) simulateProofEvent(c *gin.Context) {
 	
 	log.Printf("📥 [ZKP] Received event data: %+v", eventData)
 
+	// 只模拟证明提交，其他事件名会写入对应的投影
+	if eventData.EventName != "ProofSubmitted" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only ProofSubmitted events can be simulated"})
+		return
+	}
+
 	// 转换ProofId为字符串
 	proofIdStr := fmt.Sprintf("%v", eventData.ProofId)
 
@@ -541,12 +617,6 @@ func (h *Handler) simulateProofEvent(c *gin.Context) {
 		Description: "Zero-Knowledge Proof Verification",
 	}
 
-	log.Printf("🔍 [ZKP] Starting off-chain verification process...")
-	log.Printf("🔍 [ZKP] Step 1: Validating proof format and structure")
-	log.Printf("🔍 [ZKP] Step 2: Verifying cryptographic proof")
-	log.Printf("🔍 [ZKP] Step 3: Checking public inputs consistency")
-	log.Printf("🔍 [ZKP] Step 4: Updating verification status")
-
 	// 构造事件载荷
 	payload := map[string]interface{}{
 		"proofId":     proofIdStr,
@@ -570,7 +640,7 @@ func (h *Handler) simulateProofEvent(c *gin.Context) {
 		LogIndex:     0,
 		BlockNumber:  eventData.BlockNumber,
 		EventName:    eventData.EventName,
-		ContractAddr: "0x0000000000000000000000000000000000000000",
+		ContractAddr: model.ZeroAddress,
 		PayloadRaw:   string(b),
 		Processed:    false,
 		CreatedAt:    time.Now(),
@@ -590,19 +660,9 @@ func (h *Handler) simulateProofEvent(c *gin.Context) {
 		return
 	}
 
+	// 模拟事件不是合约发出的，只记录在 event_logs 中，不写入证明投影
 	if err := h.repo.MarkEventProcessed(eventLog.ID); err != nil {
 		log.Printf("⚠️  [ZKP] Mark processed failed: %v", err)
-	} else {
-		log.Printf("✅ [ZKP] Proof verification completed and status synchronized")
-		log.Printf("🔍 [ZKP] Proof ID %s is now available for queries", proofIdStr)
-	}
-	
-	// 🎯 演示：直接更新数据库中的证明状态
-	log.Printf("🔄 [ZKP] Updating proof status in database...")
-	if err := h.updateProofStatus(proofIdStr, "verified"); err != nil {
-		log.Printf("⚠️  [ZKP] Failed to update proof status: %v", err)
-	} else {
-		log.Printf("✅ [ZKP] Proof status updated to 'verified' in database")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -612,14 +672,6 @@ func (h *Handler) simulateProofEvent(c *gin.Context) {
 	})
 }
 
-// updateProofStatus 更新数据库中证明的状态
-func (h *Handler) updateProofStatus(proofId, status string) error {
-	// 这里需要直接操作数据库，因为我们没有现成的repository方法
-	// 为了演示，我们先记录日志
-	log.Printf("📝 [ZKP] Would update proof %s to status '%s'", proofId, status)
-	return nil
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
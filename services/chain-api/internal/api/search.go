@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"desci-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// 全文检索研究成果（标题、作者）和数据集（标题、描述），可按类型、持有人和区块范围过滤
+func (h *Handler) search(c *gin.Context) {
+	filter := repository.SearchFilter{
+		Query: c.Query("q"),
+		Type:  c.Query("type"),
+		Owner: c.Query("owner"),
+	}
+	for param, target := range map[string]*uint64{"from_block": &filter.FromBlock, "to_block": &filter.ToBlock} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		block, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid " + param,
+			})
+			return
+		}
+		*target = block
+	}
+
+	page, err := h.service.Search(filter, pageRequest(c))
+	if errors.Is(err, repository.ErrInvalidSearch) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "q is required and type must be research or dataset",
+		})
+		return
+	}
+	if err != nil {
+		writePageError(c, err, "Failed to search")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"query": filter.Query,
+	})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 分页获取 SciToken 持有人排行及总供应量
+func (h *Handler) getTokenHolders(c *gin.Context) {
+	page, supply, err := h.service.GetTokenHolders(pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get token holders")
+		return
+	}
+
+	respondPage(c, page, gin.H{
+		"supply": supply,
+	})
+}
+
+// 获取地址的 SciToken 余额及授权
+func (h *Handler) getTokenBalance(c *gin.Context) {
+	address := c.Param("address")
+
+	account, err := h.service.GetTokenAccount(address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get token balance",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// 分页获取 SciToken 转账记录，可按地址过滤
+func (h *Handler) getTokenTransfers(c *gin.Context) {
+	address := c.Query("address")
+
+	page, err := h.service.GetTokenTransfers(address, pageRequest(c))
+	if err != nil {
+		writePageError(c, err, "Failed to get token transfers")
+		return
+	}
+
+	respondPage(c, page, nil)
+}
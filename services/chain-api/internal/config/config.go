@@ -1,11 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -17,17 +19,63 @@ type Config struct {
 	StartBlock  uint64
 	PrivateKey  string
 
+	// 实时模式：auto（按RPC协议选择）、subscribe 或 poll
+	ListenerMode string
+	PollInterval time.Duration
+
+	// 历史回填每次查询的区块窗口
+	BackfillBatchSize uint64
+
+	// 链重组回溯的最大已索引区块数
+	MaxReorgDepth int
+
+	// 事件物化前需要等待的区块确认数（0 表示立即物化）
+	ConfirmationBlocks       uint64
+	ConfirmationPollInterval time.Duration
+
+	// 失败事件的重放：轮询间隔、每批数量和重试退避（第 n 次失败后等待 base*2^(n-1)，最长 max）
+	ReplayInterval   time.Duration
+	ReplayBatchSize  int
+	ReplayBackoff    time.Duration
+	ReplayMaxBackoff time.Duration
+
+	// 物化失败达到该次数后转入死信队列，不再自动重试
+	DeadLetterMaxAttempts int
+
+	// 管理接口（/api/admin）的访问令牌，为空时管理接口全部拒绝
+	AdminToken string
+
 	// 数据库配置
 	DatabaseURL string
+	// 只读副本，承担列表类查询（为空时读写都走主库）
+	DatabaseReplicaURL string
+
+	// 连接池、语句超时（PostgreSQL）、写锁等待（SQLite）和启动时等待数据库就绪的最长时间
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  time.Duration
+	DBConnMaxIdleTime  time.Duration
+	DBStatementTimeout time.Duration
+	DBBusyTimeout      time.Duration
+	DBConnectTimeout   time.Duration
 
 	// 合约地址
-	DeSciRegistryAddress    string
-	ResearchNFTAddress      string
-	DatasetManagerAddress   string
-	InfluenceRankingAddress string
-	DeSciPlatformAddress    string
+	DeSciRegistryAddress        string
+	ResearchNFTAddress          string
+	DatasetManagerAddress       string
+	InfluenceRankingAddress     string
+	DeSciPlatformAddress        string
+	ZKProofAddress              string
+	ZKPVerifierAddress          string
+	ConstraintManagerAddress    string
+	DataFeatureExtractorAddress string
+	ResearchDataVerifierAddress string
+	SciTokenAddress             string
 
 	ContractsConfigPath string
+
+	// 链下 Groth16 校验的轮询间隔（需配置 ZKProof 和 ZKPVerifier 地址）
+	ProofCheckInterval time.Duration
 }
 
 func Load() *Config {
@@ -37,14 +85,48 @@ func Load() *Config {
 		StartBlock:  getEnvUint64("START_BLOCK", 0),
 		PrivateKey:  getEnv("PRIVATE_KEY", ""),
 
-		DatabaseURL: getEnv("DATABASE_URL", "sqlite://./desci.db"),
-
-		DeSciRegistryAddress:    getEnv("DESCI_REGISTRY_ADDRESS", ""),
-		ResearchNFTAddress:      getEnv("RESEARCH_NFT_ADDRESS", ""),
-		DatasetManagerAddress:   getEnv("DATASET_MANAGER_ADDRESS", ""),
-		InfluenceRankingAddress: getEnv("INFLUENCE_RANKING_ADDRESS", ""),
-		DeSciPlatformAddress:    getEnv("DESCI_PLATFORM_ADDRESS", ""),
-		ContractsConfigPath:     getEnv("CONTRACTS_CONFIG_PATH", filepath.Join("internal", "contracts", "contracts.json")),
+		ListenerMode:      getEnv("LISTENER_MODE", "auto"),
+		PollInterval:      getEnvDuration("POLL_INTERVAL", 3*time.Second),
+		BackfillBatchSize: getEnvUint64("BACKFILL_BATCH_SIZE", 2000),
+		MaxReorgDepth:     getEnvInt("MAX_REORG_DEPTH", 64),
+
+		ConfirmationBlocks:       getEnvUint64("CONFIRMATION_BLOCKS", 0),
+		ConfirmationPollInterval: getEnvDuration("CONFIRMATION_POLL_INTERVAL", 5*time.Second),
+
+		ReplayInterval:   getEnvDuration("REPLAY_INTERVAL", 30*time.Second),
+		ReplayBatchSize:  getEnvInt("REPLAY_BATCH_SIZE", 100),
+		ReplayBackoff:    getEnvDuration("REPLAY_BACKOFF", 10*time.Second),
+		ReplayMaxBackoff: getEnvDuration("REPLAY_MAX_BACKOFF", time.Hour),
+
+		DeadLetterMaxAttempts: getEnvInt("DEAD_LETTER_MAX_ATTEMPTS", 5),
+
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		DatabaseURL:        getEnv("DATABASE_URL", "sqlite://./desci.db"),
+		DatabaseReplicaURL: getEnv("DATABASE_REPLICA_URL", ""),
+
+		DBMaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 10),
+		DBMaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime:  getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime:  getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		DBStatementTimeout: getEnvDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		DBBusyTimeout:      getEnvDuration("DB_BUSY_TIMEOUT", 5*time.Second),
+		DBConnectTimeout:   getEnvDuration("DB_CONNECT_TIMEOUT", time.Minute),
+
+		DeSciRegistryAddress:        getEnv("DESCI_REGISTRY_ADDRESS", ""),
+		ResearchNFTAddress:          getEnv("RESEARCH_NFT_ADDRESS", ""),
+		DatasetManagerAddress:       getEnv("DATASET_MANAGER_ADDRESS", ""),
+		InfluenceRankingAddress:     getEnv("INFLUENCE_RANKING_ADDRESS", ""),
+		DeSciPlatformAddress:        getEnv("DESCI_PLATFORM_ADDRESS", ""),
+		ZKProofAddress:              getEnv("ZK_PROOF_ADDRESS", ""),
+		ZKPVerifierAddress:          getEnv("ZKP_VERIFIER_ADDRESS", ""),
+		ConstraintManagerAddress:    getEnv("CONSTRAINT_MANAGER_ADDRESS", ""),
+		DataFeatureExtractorAddress: getEnv("DATA_FEATURE_EXTRACTOR_ADDRESS", ""),
+		ResearchDataVerifierAddress: getEnv("RESEARCH_DATA_VERIFIER_ADDRESS", ""),
+		SciTokenAddress:             getEnv("SCI_TOKEN_ADDRESS", ""),
+		ContractsConfigPath:         getEnv("CONTRACTS_CONFIG_PATH", filepath.Join("internal", "contracts", "contracts.json")),
+
+		ProofCheckInterval: getEnvDuration("PROOF_CHECK_INTERVAL", 30*time.Second),
 	}
 
 	cfg.applyContractsFromFile()
@@ -68,7 +150,8 @@ func (c *Config) applyContractsFromFile() {
 		} `json:"contracts"`
 	}
 
-	if err := json.Unmarshal(data, &payload); err != nil {
+	// 部署脚本可能重复追加输出，只解码第一个JSON文档
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&payload); err != nil {
 		log.Printf("config: unable to parse contracts config (%s): %v", c.ContractsConfigPath, err)
 		return
 	}
@@ -87,6 +170,12 @@ func (c *Config) applyContractsFromFile() {
 	updateIfEmpty(&c.DatasetManagerAddress, "DatasetManager")
 	updateIfEmpty(&c.InfluenceRankingAddress, "InfluenceRanking")
 	updateIfEmpty(&c.DeSciPlatformAddress, "DeSciPlatform")
+	updateIfEmpty(&c.ZKProofAddress, "ZKProof")
+	updateIfEmpty(&c.ZKPVerifierAddress, "ZKPVerifier")
+	updateIfEmpty(&c.ConstraintManagerAddress, "ConstraintManager")
+	updateIfEmpty(&c.DataFeatureExtractorAddress, "DataFeatureExtractor")
+	updateIfEmpty(&c.ResearchDataVerifierAddress, "ResearchDataVerifier")
+	updateIfEmpty(&c.SciTokenAddress, "SciToken")
 }
 
 func getEnv(key, defaultValue string) string {
@@ -104,3 +193,21 @@ func getEnvUint64(key string, defaultValue uint64) uint64 {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
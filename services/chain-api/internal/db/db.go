@@ -2,57 +2,135 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"io/fs"
+	"log"
+	"net/url"
 	"os"
-	"path/filepath"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// maxRetryBackoff 启动重试的最长间隔
+const maxRetryBackoff = 30 * time.Second
+
 // Config 数据库配置
 type Config struct {
-	Host         string
-	Port         int
-	User         string
-	Password     string
-	DBName       string
-	SSLMode      string
+	// URL sqlite://<路径> 或 PostgreSQL 连接串；为空时由 Host 等字段拼接 PostgreSQL DSN
+	URL string
+	// ReplicaURL 只读副本，承担列表类查询；为空时读写都走主库
+	ReplicaURL string
+
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
 	MaxOpenConns int
 	MaxIdleConns int
 	MaxLifetime  time.Duration
+	MaxIdleTime  time.Duration
+
+	// StatementTimeout PostgreSQL 单条语句的最长执行时间（0 表示不限制）
+	StatementTimeout time.Duration
+	// BusyTimeout SQLite 等待其他连接释放写锁的时间
+	BusyTimeout time.Duration
+
+	// ConnectTimeout 启动时等待数据库可用的最长时间，期间从 RetryBackoff 开始指数退避重试
+	ConnectTimeout time.Duration
+	RetryBackoff   time.Duration
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		Host:         "localhost",
-		Port:         5432,
-		User:         "postgres",
-		Password:     "postgres",
-		DBName:       "desci",
-		SSLMode:      "disable",
-		MaxOpenConns: 10,
-		MaxIdleConns: 10,
-		MaxLifetime:  30 * time.Minute,
+		Host:             "localhost",
+		Port:             5432,
+		User:             "postgres",
+		Password:         "postgres",
+		DBName:           "desci",
+		SSLMode:          "disable",
+		MaxOpenConns:     10,
+		MaxIdleConns:     10,
+		MaxLifetime:      30 * time.Minute,
+		MaxIdleTime:      5 * time.Minute,
+		StatementTimeout: 30 * time.Second,
+		BusyTimeout:      5 * time.Second,
+		ConnectTimeout:   time.Minute,
+		RetryBackoff:     time.Second,
 	}
 }
 
 // DSN 生成数据库连接字符串
 func (c *Config) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
-// Open 打开数据库连接
+// Open 打开主库连接：配置连接池和超时，数据库尚未就绪时按退避重试直到 ConnectTimeout
 func Open(ctx context.Context, config *Config) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(config.DSN()), &gorm.Config{})
+	return open(ctx, config, config.DSN())
+}
+
+// OpenReplica 打开只读副本，未配置 ReplicaURL 时返回 nil
+func OpenReplica(ctx context.Context, config *Config) (*gorm.DB, error) {
+	if config.ReplicaURL == "" {
+		return nil, nil
+	}
+	return open(ctx, config, config.ReplicaURL)
+}
+
+func open(ctx context.Context, config *Config, dsn string) (*gorm.DB, error) {
+	delay := config.RetryBackoff
+	if delay <= 0 {
+		delay = time.Second
+	}
+	deadline := time.Now().Add(config.ConnectTimeout)
+
+	for attempt := 1; ; attempt++ {
+		db, err := connect(ctx, config, dsn)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
+
+		log.Printf("⏳ Database not ready (attempt %d): %v, retrying in %s", attempt, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if delay *= 2; delay > maxRetryBackoff {
+			delay = maxRetryBackoff
+		}
+	}
+}
+
+// connect 建立连接池并做健康检查
+func connect(ctx context.Context, config *Config, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	memory := false
+	if path, ok := sqlitePath(dsn); ok {
+		memory = path == ":memory:" || strings.Contains(path, "mode=memory")
+		dialector = sqlite.Open(sqliteDSN(path, config, memory))
+	} else {
+		dialector = postgres.Open(postgresDSN(dsn, config.StatementTimeout))
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -63,108 +141,94 @@ func Open(ctx context.Context, config *Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	// 配置连接池
-	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
-	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
-	sqlDB.SetConnMaxLifetime(config.MaxLifetime)
+	if memory {
+		// 内存库每个连接都是独立的数据库，只能使用单个常驻连接
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+	} else {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(config.MaxLifetime)
+		sqlDB.SetConnMaxIdleTime(config.MaxIdleTime)
+	}
 
 	// 健康检查
 	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return db, nil
 }
 
-// MustLoadDSNFromEnv 从环境变量加载数据库连接字符串
-func MustLoadDSNFromEnv() string {
-	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
-		return dsn
+// sqlitePath 解析 sqlite:// 或 sqlite: 前缀的数据库路径
+func sqlitePath(dsn string) (string, bool) {
+	for _, prefix := range []string{"sqlite://", "sqlite:"} {
+		if strings.HasPrefix(dsn, prefix) {
+			return strings.TrimPrefix(dsn, prefix), true
+		}
 	}
-	// 回退到默认配置
-	config := DefaultConfig()
-	return config.DSN()
+	return "", false
 }
 
-// RunMigrations 执行数据库迁移
-func RunMigrations(ctx context.Context, db *gorm.DB, migrationDir string) error {
-	sqlDB, err := db.DB()
-	if err != nil {
-		return fmt.Errorf("failed to get sql.DB: %w", err)
-	}
-
-	// 获取所有迁移文件
-	files, err := getMigrationFiles(migrationDir)
-	if err != nil {
-		return fmt.Errorf("failed to get migration files: %w", err)
+// sqliteDSN 为每个连接设置 busy_timeout；文件库启用 WAL，读写互不阻塞，
+// 事务以 IMMEDIATE 开始，避免读锁升级为写锁时绕过 busy_timeout 直接返回 SQLITE_BUSY
+func sqliteDSN(path string, config *Config, memory bool) string {
+	params := []string{"_busy_timeout=" + strconv.FormatInt(config.BusyTimeout.Milliseconds(), 10)}
+	if !memory {
+		params = append(params, "_journal_mode=WAL", "_txlock=immediate")
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no migration files found in %s", migrationDir)
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
 	}
-
-	// 按顺序执行迁移文件
-	for _, file := range files {
-		if err := executeMigrationFile(ctx, sqlDB, file); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", filepath.Base(file), err)
+	for _, p := range params {
+		// 连接串中已显式指定的参数优先
+		if key := p[:strings.Index(p, "=")+1]; strings.Contains(path, key) {
+			continue
 		}
+		path += sep + p
+		sep = "&"
 	}
-
-	return nil
+	return path
 }
 
-// getMigrationFiles 获取排序后的迁移文件列表
-func getMigrationFiles(dir string) ([]string, error) {
-	var files []string
-
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+// postgresDSN 通过连接参数设置服务端 statement_timeout，URL 和 key=value 两种格式均支持
+func postgresDSN(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(dsn, "statement_timeout") {
+		return dsn
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
 		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			return nil
-		}
-
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".sql") {
-			files = append(files, path)
+			return dsn
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
+		q := u.Query()
+		q.Set("statement_timeout", ms)
+		u.RawQuery = q.Encode()
+		return u.String()
 	}
-
-	// 按文件名排序
-	sort.Strings(files)
-	return files, nil
+	return dsn + " statement_timeout=" + ms
 }
 
-// executeMigrationFile 执行单个迁移文件
-func executeMigrationFile(ctx context.Context, db *sql.DB, filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+// MustLoadDSNFromEnv 从环境变量加载数据库连接字符串
+func MustLoadDSNFromEnv() string {
+	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
+		return dsn
 	}
+	// 回退到默认配置
+	config := DefaultConfig()
+	return config.DSN()
+}
 
-	// 在事务中执行迁移
-	tx, err := db.BeginTx(ctx, nil)
+// RunMigrations 应用全部未执行的迁移
+func RunMigrations(ctx context.Context, db *gorm.DB, migrations fs.FS) error {
+	migrator, err := NewMigrator(db, migrations)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// 执行SQL
-	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
-		return fmt.Errorf("failed to execute SQL: %w", err)
+		return err
 	}
-
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+	_, err = migrator.Up(ctx, 0)
+	return err
 }
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_SQLitePragmas(t *testing.T) {
+	config := DefaultConfig()
+	config.URL = "sqlite://" + filepath.Join(t.TempDir(), "desci.db")
+	config.BusyTimeout = 2 * time.Second
+
+	gormDB, err := Open(context.Background(), config)
+	require.NoError(t, err)
+
+	var journal string
+	var busy int
+	require.NoError(t, gormDB.Raw("PRAGMA journal_mode").Scan(&journal).Error)
+	require.NoError(t, gormDB.Raw("PRAGMA busy_timeout").Scan(&busy).Error)
+	assert.Equal(t, "wal", journal)
+	assert.Equal(t, 2000, busy)
+
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	assert.Equal(t, config.MaxOpenConns, sqlDB.Stats().MaxOpenConnections)
+}
+
+func TestOpen_MemoryUsesSingleConnection(t *testing.T) {
+	config := DefaultConfig()
+	config.URL = "sqlite::memory:"
+
+	gormDB, err := Open(context.Background(), config)
+	require.NoError(t, err)
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	assert.Equal(t, 1, sqlDB.Stats().MaxOpenConnections)
+}
+
+func TestOpen_RetriesUntilConnectTimeout(t *testing.T) {
+	config := DefaultConfig()
+	config.URL = "postgres://postgres@127.0.0.1:1/desci?sslmode=disable&connect_timeout=1"
+	config.ConnectTimeout = 300 * time.Millisecond
+	config.RetryBackoff = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := Open(context.Background(), config)
+	require.Error(t, err)
+	// 至少重试一次，且不超过等待上限太多
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	replica, err := OpenReplica(context.Background(), config)
+	require.NoError(t, err)
+	assert.Nil(t, replica)
+}
+
+func TestConnectionStrings(t *testing.T) {
+	assert.Equal(t, "host=db user=app statement_timeout=1500", postgresDSN("host=db user=app", 1500*time.Millisecond))
+	assert.Equal(t, "postgres://app@db/desci?sslmode=disable&statement_timeout=1500",
+		postgresDSN("postgres://app@db/desci?sslmode=disable", 1500*time.Millisecond))
+	assert.Equal(t, "host=db statement_timeout=100", postgresDSN("host=db statement_timeout=100", time.Second))
+
+	config := DefaultConfig()
+	assert.Equal(t, "./desci.db?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", sqliteDSN("./desci.db", config, false))
+	assert.Equal(t, "./desci.db?_journal_mode=DELETE&_busy_timeout=5000&_txlock=immediate", sqliteDSN("./desci.db?_journal_mode=DELETE", config, false))
+	assert.Equal(t, ":memory:?_busy_timeout=5000", sqliteDSN(":memory:", config, true))
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"desci-backend/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"sqlite/0001_init.up.sql":       {Data: []byte("-- 初始表\nCREATE TABLE grants (id integer PRIMARY KEY);\nCREATE INDEX idx_grants_id ON grants(id);\n")},
+		"sqlite/0001_init.down.sql":     {Data: []byte("DROP TABLE grants;\n")},
+		"sqlite/0002_amount.up.sql":     {Data: []byte("ALTER TABLE grants ADD COLUMN amount text;\n")},
+		"sqlite/0002_amount.down.sql":   {Data: []byte("ALTER TABLE grants DROP COLUMN amount;\n")},
+		"postgres/0001_init.up.sql":     {Data: []byte("CREATE TABLE grants (id bigserial PRIMARY KEY);\n")},
+		"postgres/0001_init.down.sql":   {Data: []byte("DROP TABLE grants;\n")},
+		"postgres/0002_amount.up.sql":   {Data: []byte("ALTER TABLE grants ADD COLUMN amount text;\n")},
+		"postgres/0002_amount.down.sql": {Data: []byte("ALTER TABLE grants DROP COLUMN amount;\n")},
+	}
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	fsys := testMigrations()
+
+	migrator, err := NewMigrator(gormDB, fsys)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.True(t, gormDB.Migrator().HasTable("grants"))
+	assert.False(t, gormDB.Migrator().HasColumn("grants", "amount"))
+
+	// 已应用的版本不会重复执行
+	applied, err = migrator.Up(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.True(t, gormDB.Migrator().HasColumn("grants", "amount"))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.NotNil(t, status.AppliedAt)
+		assert.False(t, status.Modified)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, "amount", reverted[0].Name)
+	assert.False(t, gormDB.Migrator().HasColumn("grants", "amount"))
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestMigrator_RejectsModifiedMigration(t *testing.T) {
+	ctx := context.Background()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	fsys := testMigrations()
+	require.NoError(t, RunMigrations(ctx, gormDB, fsys))
+
+	// 修改已应用的迁移后拒绝继续迁移
+	fsys["sqlite/0001_init.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE grants (id integer PRIMARY KEY, title text);\n")}
+	fsys["sqlite/0003_title.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE grants ADD COLUMN title text;\n")}
+	fsys["sqlite/0003_title.down.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE grants DROP COLUMN title;\n")}
+	migrator, err := NewMigrator(gormDB, fsys)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.False(t, gormDB.Migrator().HasColumn("grants", "title"))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Modified)
+	assert.False(t, statuses[2].Applied)
+
+	// 缺少 down 脚本的迁移在加载时即被拒绝
+	delete(fsys, "sqlite/0002_amount.down.sql")
+	_, err = NewMigrator(gormDB, fsys)
+	assert.ErrorContains(t, err, "migration 2_amount has no down script")
+}
+
+// 两种方言的迁移版本必须一一对应，且每个版本都有 up 和 down 脚本
+func TestMigrations_DialectsInParity(t *testing.T) {
+	sqliteMigrations, err := LoadMigrations(migrations.FS, "sqlite")
+	require.NoError(t, err)
+	postgresMigrations, err := LoadMigrations(migrations.FS, "postgres")
+	require.NoError(t, err)
+
+	versions := func(list []Migration) []string {
+		var names []string
+		for _, migration := range list {
+			assert.NotEmpty(t, strings.TrimSpace(migration.Up), "%d_%s up", migration.Version, migration.Name)
+			assert.NotEmpty(t, strings.TrimSpace(migration.Down), "%d_%s down", migration.Version, migration.Name)
+			names = append(names, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+		return names
+	}
+	assert.Equal(t, versions(postgresMigrations), versions(sqliteMigrations))
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("-- 注释\nCREATE TABLE a (\n    id integer\n);\n\nCREATE INDEX idx_a ON a(id);\nDROP TABLE b")
+	assert.Equal(t, []string{"CREATE TABLE a (\n    id integer\n);", "CREATE INDEX idx_a ON a(id);", "DROP TABLE b"}, stmts)
+}
@@ -0,0 +1,215 @@
+package listener
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"desci-backend/internal/model"
+)
+
+const (
+	// defaultBackfillBatchSize 默认每次 FilterLogs 查询的区块窗口
+	defaultBackfillBatchSize = 2000
+	// maxBackfillRetryDelay 非范围类错误重试的最大退避间隔
+	maxBackfillRetryDelay = 30 * time.Second
+)
+
+// CursorStore 合约同步游标的持久化接口（repository.Repository 实现该接口）
+type CursorStore interface {
+	GetSyncCursors() ([]model.SyncCursor, error)
+	SaveSyncCursor(contractAddr string, lastBlock uint64) error
+}
+
+// tooManyResultsHints 各 RPC 提供方拒绝过大查询范围时的错误信息片段（小写）。只收录已知的完整短语，
+// 避免 "exceeds"、"rate limit exceeded" 之类无关错误被误判为需要缩小窗口
+var tooManyResultsHints = []string{
+	"query returned more than",    // geth、Infura
+	"log response size exceeded",  // Alchemy
+	"query exceeds max results",   // Alchemy
+	"block range is too wide",     // Ankr
+	"block range too large",       // QuickNode 等
+	"range is too large",          // Nethermind 等
+	"exceed maximum block range",  // Erigon、BSC
+	"exceeds maximum range limit", // Besu
+	"eth_getlogs is limited to",   // QuickNode
+	"block range limit exceeded",  // Chainstack 等
+	"blocks are not supported",    // Cloudflare："ranges over N blocks are not supported"
+}
+
+// isTooManyResults 判断错误是否表示查询范围过大，需要缩小窗口
+func isTooManyResults(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, hint := range tooManyResultsHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadNextBlocks 返回每个合约下一个待索引的区块高度
+func (el *EventListener) loadNextBlocks() (map[common.Address]uint64, error) {
+	start := el.startBlock
+	// 本次运行中已分发的位置优先于配置的起点
+	if el.position.valid && el.position.index == maxLogIndex && el.position.block+1 > start {
+		start = el.position.block + 1
+	}
+
+	next := make(map[common.Address]uint64, len(el.contracts))
+	for _, addr := range el.contracts {
+		next[addr] = start
+	}
+	if el.cursorStore == nil {
+		return next, nil
+	}
+
+	cursors, err := el.cursorStore.GetSyncCursors()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cursors {
+		addr := common.HexToAddress(c.ContractAddr)
+		if n, ok := next[addr]; ok && c.LastBlock+1 > n {
+			next[addr] = c.LastBlock + 1
+		}
+	}
+	return next, nil
+}
+
+// backfill 按区块窗口分批拉取历史日志直到 to（含），每个窗口处理完成后推进游标
+func (el *EventListener) backfill(to uint64) error {
+	next, err := el.loadNextBlocks()
+	if err != nil {
+		return fmt.Errorf("load sync cursors: %w", err)
+	}
+
+	from := to + 1
+	for _, n := range next {
+		if n < from {
+			from = n
+		}
+	}
+	if from > to {
+		if from > 0 {
+			el.markSynced(from - 1)
+		}
+		return nil
+	}
+
+	log.Printf("Backfilling blocks %d-%d in windows of %d", from, to, el.batchSize)
+
+	batch := el.batchSize
+	retryDelay := time.Second
+	for from <= to {
+		end := from + batch - 1
+		if end > to || end < from {
+			end = to
+		}
+
+		var due []common.Address
+		for _, addr := range el.contracts {
+			if next[addr] <= end {
+				due = append(due, addr)
+			}
+		}
+
+		logs, err := el.client.FilterLogs(el.ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: due,
+		})
+		if err != nil {
+			if el.ctx.Err() != nil {
+				return el.ctx.Err()
+			}
+			if isTooManyResults(err) && batch > 1 {
+				batch /= 2
+				log.Printf("⚠️  Backfill window %d-%d too large, shrinking to %d blocks: %v", from, end, batch, err)
+				continue
+			}
+			log.Printf("⚠️  Backfill window %d-%d failed, retrying in %s: %v", from, end, retryDelay, err)
+			select {
+			case <-time.After(retryDelay):
+			case <-el.ctx.Done():
+				return el.ctx.Err()
+			}
+			if retryDelay *= 2; retryDelay > maxBackfillRetryDelay {
+				retryDelay = maxBackfillRetryDelay
+			}
+			continue
+		}
+		retryDelay = time.Second
+
+		sortLogs(logs)
+		for _, vLog := range logs {
+			if vLog.BlockNumber < next[vLog.Address] {
+				continue
+			}
+			if err := el.handleLog(vLog); err != nil {
+				if retryBlock(err) {
+					// 游标只推进到失败区块之前，由调用方稍后从该区块重试
+					return el.stopBefore(due, next, vLog.BlockNumber, err)
+				}
+				log.Printf("Error processing event: %v", err)
+			}
+		}
+
+		for _, addr := range due {
+			if el.cursorStore != nil {
+				if err := el.cursorStore.SaveSyncCursor(addr.Hex(), end); err != nil {
+					return fmt.Errorf("save sync cursor %s: %w", addr.Hex(), err)
+				}
+			}
+			next[addr] = end + 1
+		}
+		el.markSynced(end)
+		log.Printf("Backfilled blocks %d-%d (%d events)", from, end, len(logs))
+
+		from = end + 1
+		// 查询成功后逐步恢复窗口大小
+		if batch < el.batchSize {
+			if batch *= 2; batch > el.batchSize {
+				batch = el.batchSize
+			}
+		}
+	}
+	return nil
+}
+
+// stopBefore 日志需重试（重组检测或获取区块时间失败）时将游标推进到 block 之前并返回原错误
+func (el *EventListener) stopBefore(due []common.Address, next map[common.Address]uint64, block uint64, cause error) error {
+	if block == 0 {
+		return cause
+	}
+	for _, addr := range due {
+		if next[addr] > block-1 {
+			continue
+		}
+		if el.cursorStore != nil {
+			if err := el.cursorStore.SaveSyncCursor(addr.Hex(), block-1); err != nil {
+				return fmt.Errorf("save sync cursor %s: %w", addr.Hex(), err)
+			}
+		}
+		next[addr] = block
+	}
+	el.markSynced(block - 1)
+	return cause
+}
+
+// sortLogs 按 (block, logIndex) 升序排列日志
+func sortLogs(logs []types.Log) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+}
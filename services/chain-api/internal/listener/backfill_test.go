@@ -0,0 +1,104 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"desci-backend/internal/model"
+)
+
+// memCursorStore 内存版 CursorStore
+type memCursorStore struct {
+	cursors map[string]uint64
+}
+
+func (s *memCursorStore) GetSyncCursors() ([]model.SyncCursor, error) {
+	var out []model.SyncCursor
+	for addr, block := range s.cursors {
+		out = append(out, model.SyncCursor{ContractAddr: addr, LastBlock: block})
+	}
+	return out, nil
+}
+
+func (s *memCursorStore) SaveSyncCursor(contractAddr string, lastBlock uint64) error {
+	s.cursors[contractAddr] = lastBlock
+	return nil
+}
+
+// recordHandler 记录分发给处理器的事件顺序
+func recordHandler(el *EventListener) *[]uint64 {
+	var blocks []uint64
+	el.SetEventHandler(func(event *model.ParsedEvent) error {
+		blocks = append(blocks, event.Block)
+		return nil
+	})
+	return &blocks
+}
+
+func TestBackfill_ShrinksWindowAndAdvancesCursor(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	chain := newFakeChain()
+	chain.maxRange = 25
+	chain.logs = []types.Log{
+		{Address: addr, BlockNumber: 5},
+		{Address: addr, BlockNumber: 60, Index: 1},
+		{Address: addr, BlockNumber: 60, Index: 0},
+		{Address: addr, BlockNumber: 99},
+	}
+	cursors := &memCursorStore{cursors: map[string]uint64{}}
+
+	el := &EventListener{client: chain, ctx: context.Background(), contracts: []common.Address{addr}, cursorStore: cursors, batchSize: 100}
+	blocks := recordHandler(el)
+
+	require.NoError(t, el.backfill(100))
+
+	assert.Equal(t, []uint64{5, 60, 60, 99}, *blocks)
+	assert.Equal(t, uint64(100), cursors.cursors[addr.Hex()])
+	// 首个窗口被拒绝后缩小，之后的窗口都在提供方限制之内
+	assert.Equal(t, [2]uint64{0, 99}, chain.queries[0])
+	last := chain.queries[len(chain.queries)-1]
+	assert.Equal(t, uint64(100), last[1])
+	assert.LessOrEqual(t, last[1]-last[0]+1, chain.maxRange)
+}
+
+func TestBackfill_ResumesFromPerContractCursor(t *testing.T) {
+	a := common.HexToAddress("0xa")
+	b := common.HexToAddress("0xb")
+	chain := newFakeChain()
+	chain.logs = []types.Log{
+		{Address: a, BlockNumber: 10},
+		{Address: b, BlockNumber: 10},
+		{Address: a, BlockNumber: 30},
+		{Address: b, BlockNumber: 30},
+	}
+	// a 已完整索引到 20（区块 11-20 没有事件也不会重复扫描），b 尚未开始
+	cursors := &memCursorStore{cursors: map[string]uint64{a.Hex(): 20}}
+
+	el := &EventListener{client: chain, ctx: context.Background(), contracts: []common.Address{a, b}, cursorStore: cursors, batchSize: 1000}
+	blocks := recordHandler(el)
+
+	require.NoError(t, el.backfill(40))
+
+	// a 在区块10的事件已处理过，不会重复分发
+	assert.Equal(t, []uint64{10, 30, 30}, *blocks)
+	assert.Equal(t, uint64(40), cursors.cursors[a.Hex()])
+	assert.Equal(t, uint64(40), cursors.cursors[b.Hex()])
+}
+
+func TestIsTooManyResults(t *testing.T) {
+	assert.True(t, isTooManyResults(errors.New("query returned more than 10000 results")))
+	assert.True(t, isTooManyResults(errors.New("Log response size exceeded")))
+	assert.True(t, isTooManyResults(errors.New("exceed maximum block range: 5000")))
+	assert.True(t, isTooManyResults(errors.New("block range is too wide")))
+	assert.False(t, isTooManyResults(errors.New("connection refused")))
+	// 与查询范围无关的错误不缩小窗口
+	assert.False(t, isTooManyResults(errors.New("gas required exceeds allowance (0)")))
+	assert.False(t, isTooManyResults(errors.New("429 Too Many Requests: rate limit exceeded")))
+	assert.False(t, isTooManyResults(errors.New("context deadline exceeded")))
+}
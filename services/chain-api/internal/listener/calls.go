@@ -0,0 +1,80 @@
+package listener
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// callContract 在事件所在区块调用合约只读方法；非归档节点无法查询历史状态时退回最新状态
+func (el *EventListener) callContract(contract common.Address, ab *abi.ABI, block uint64, method string, args ...interface{}) (map[string]interface{}, error) {
+	input, err := ab.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	msg := ethereum.CallMsg{To: &contract, Data: input}
+
+	out, err := el.client.CallContract(el.ctx, msg, new(big.Int).SetUint64(block))
+	if err != nil {
+		log.Printf("⚠️  %s at block %d failed, retrying at latest: %v", method, block, err)
+		if out, err = el.client.CallContract(el.ctx, msg, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	vals := map[string]interface{}{}
+	if err := ab.UnpackIntoMap(vals, method, out); err != nil {
+		return nil, fmt.Errorf("unpack %s: %w", method, err)
+	}
+	return vals, nil
+}
+
+// fetchResearchHashes 通过 ResearchNFT.researches(tokenId) 读取内容哈希和元数据哈希
+func (el *EventListener) fetchResearchHashes(vLog types.Log, ab *abi.ABI, tokenID *big.Int) (contentHash, metadataHash string, err error) {
+	vals, err := el.callContract(vLog.Address, ab, vLog.BlockNumber, "researches", tokenID)
+	if err != nil {
+		return "", "", err
+	}
+	contentHash, _ = vals["contentHash"].(string)
+	metadataHash, _ = vals["metadataHash"].(string)
+	return contentHash, metadataHash, nil
+}
+
+// enrichArgs 调用返回单个 tuple 或具名返回值的 getter，把事件中没有的字段补充到参数中
+func (el *EventListener) enrichArgs(vLog types.Log, ab *abi.ABI, method string, id interface{}, args map[string]interface{}) error {
+	vals, err := el.callContract(vLog.Address, ab, vLog.BlockNumber, method, id)
+	if err != nil {
+		return err
+	}
+	fields := normalizeArgs(vals)
+	if tuple, ok := vals[""]; ok {
+		if fields, ok = normalizeArg(tuple).(map[string]interface{}); !ok {
+			return fmt.Errorf("%s: unexpected output", method)
+		}
+	}
+	for k, v := range fields {
+		if _, exists := args[k]; !exists {
+			args[k] = v
+		}
+	}
+	return nil
+}
+
+// blockTime 返回日志所在区块的时间戳（同一区块的连续日志复用上次结果）
+func (el *EventListener) blockTime(vLog types.Log) (uint64, error) {
+	if el.lastHeaderHash == vLog.BlockHash && el.lastHeaderTime > 0 {
+		return el.lastHeaderTime, nil
+	}
+	header, err := el.client.HeaderByHash(el.ctx, vLog.BlockHash)
+	if err != nil {
+		return 0, err
+	}
+	el.lastHeaderHash = vLog.BlockHash
+	el.lastHeaderTime = header.Time
+	return header.Time, nil
+}
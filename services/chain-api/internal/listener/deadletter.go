@@ -0,0 +1,66 @@
+package listener
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+
+	"desci-backend/internal/model"
+)
+
+// DeadLetterStore 死信的持久化接口（repository.Repository 实现该接口）
+type DeadLetterStore interface {
+	InsertDeadLetter(letter *model.DeadLetter) error
+}
+
+// SetDeadLetterStore 设置死信存储，事件处理器失败时保存事件以便修复后重试
+func (el *EventListener) SetDeadLetterStore(store DeadLetterStore) {
+	el.deadLetters = store
+}
+
+// dispatch 交由事件处理器处理；处理器返回错误或panic时写入死信，避免游标推进后事件丢失
+func (el *EventListener) dispatch(event *model.ParsedEvent) (err error) {
+	defer func() {
+		var stack string
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+			stack = string(debug.Stack())
+		}
+		if err != nil {
+			el.deadLetter(event, err, stack)
+		}
+	}()
+	return el.eventHandler(event)
+}
+
+// deadLetter 以完整的 ParsedEvent 作为载荷写入死信
+func (el *EventListener) deadLetter(event *model.ParsedEvent, cause error, stack string) {
+	if el.deadLetters == nil {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal dead letter for %s: %v", event.TxHash, err)
+		return
+	}
+	letter := &model.DeadLetter{
+		Source:       model.DeadLetterSourceListener,
+		EventName:    event.EventName,
+		TxHash:       event.TxHash,
+		LogIndex:     event.LogIndex,
+		BlockNumber:  event.Block,
+		ContractAddr: strings.ToLower(event.Contract),
+		Attempts:     1,
+		Error:        cause.Error(),
+		Stack:        stack,
+		PayloadRaw:   string(payload),
+		Status:       model.DeadLetterStatusDead,
+	}
+	if err := el.deadLetters.InsertDeadLetter(letter); err != nil {
+		log.Printf("⚠️  Failed to dead-letter event %s: %v", event.TxHash, err)
+		return
+	}
+	log.Printf("☠️  Event %s (%s #%d) dead-lettered: %v", event.EventName, event.TxHash, event.LogIndex, cause)
+}
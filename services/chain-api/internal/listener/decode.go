@@ -0,0 +1,109 @@
+package listener
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// decodeLogArgs 解码日志的全部参数，indexed 参数从 topics 还原
+func decodeLogArgs(ev abi.Event, vLog types.Log) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	if err := ev.Inputs.UnpackIntoMap(vals, vLog.Data); err != nil {
+		return vals, err
+	}
+
+	var indexed abi.Arguments
+	for _, input := range ev.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) > 0 && len(vLog.Topics) == len(indexed)+1 {
+		if err := abi.ParseTopicsIntoMap(vals, indexed, vLog.Topics[1:]); err != nil {
+			return vals, err
+		}
+	}
+	return vals, nil
+}
+
+// normalizeArgs 将解码结果转换为可直接JSON序列化的值：
+// 地址和哈希为十六进制字符串，uint256 为十进制字符串
+func normalizeArgs(vals map[string]interface{}) map[string]interface{} {
+	args := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		args[k] = normalizeArg(v)
+	}
+	return args
+}
+
+func normalizeArg(v interface{}) interface{} {
+	switch x := v.(type) {
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case *big.Int:
+		if x == nil {
+			return "0"
+		}
+		return x.String()
+	case []byte:
+		return hexutil.Encode(x)
+	case string, bool, uint8, uint16, uint32, uint64, int8, int16, int32, int64:
+		return x
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		// bytesN
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = normalizeArg(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			name := rv.Type().Field(i).Name
+			out[strings.ToLower(name[:1])+name[1:]] = normalizeArg(rv.Field(i).Interface())
+		}
+		return out
+	}
+	return v
+}
+
+// addressArg 返回第一个存在的地址参数
+func addressArg(vals map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		if a, ok := vals[name].(common.Address); ok {
+			return a.Hex()
+		}
+	}
+	return ""
+}
+
+// constraintGetter 返回 ConstraintManager 事件对应的详情 getter 及其ID参数名
+func constraintGetter(eventName string) (method, idArg string) {
+	switch eventName {
+	case "ConstraintGroupCreated":
+		return "getConstraintGroup", "groupId"
+	case "ValidationRuleCreated":
+		return "getValidationRule", "ruleId"
+	default:
+		return "getConstraint", "constraintId"
+	}
+}
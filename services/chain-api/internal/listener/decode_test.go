@@ -0,0 +1,365 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"desci-backend/internal/model"
+)
+
+const (
+	registryAddr    = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	researchNFTAddr = "0xa513E6E4b8f2a923D98304ec87F64353C4D5C853"
+	constraintAddr  = "0xCf7Ed3AccA5a467e9e704C703E8D87F634fB0Fc9"
+	influenceAddr   = "0x2279B7A0a67DB372996a5FaB50D91eAA73d2eBe6"
+	sciTokenAddr    = "0x8aCd85898458400f7Db866d53FCFF6f0D49741FF"
+)
+
+func TestLoadContractABIs_ConcatenatedConfig(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	assert.Contains(t, abis, strings.ToLower(registryAddr))
+}
+
+func TestParseAndHandleEvent_DecodesUserRegistered(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	registry := abis[strings.ToLower(registryAddr)]
+	ev := registry.Events["UserRegistered"]
+
+	user := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	data, err := ev.Inputs.NonIndexed().Pack("Alice", uint8(2), big.NewInt(1700000000))
+	require.NoError(t, err)
+
+	chain := newFakeChain()
+	header := chain.addBlock(7, common.Hash{}, 0, true)
+
+	var got *model.ParsedEvent
+	el := &EventListener{
+		client:       chain,
+		contractABIs: abis,
+		eventHandler: func(e *model.ParsedEvent) error {
+			got = e
+			return nil
+		},
+	}
+	err = el.parseAndHandleEvent(types.Log{
+		Address:     common.HexToAddress(registryAddr),
+		Topics:      []common.Hash{ev.ID, common.BytesToHash(user.Bytes())},
+		Data:        data,
+		BlockNumber: 7,
+		BlockHash:   header.Hash(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	assert.Equal(t, "UserRegistered", got.EventName)
+	assert.Equal(t, user.Hex(), got.Author)
+	assert.Equal(t, "Alice", got.Title)
+	assert.Equal(t, common.HexToAddress(registryAddr).Hex(), got.Contract)
+	assert.Equal(t, user.Hex(), got.Args["user"])
+	assert.Equal(t, "Alice", got.Args["name"])
+	assert.Equal(t, uint8(2), got.Args["role"])
+	assert.Equal(t, "1700000000", got.Args["timestamp"])
+}
+
+func TestParseAndHandleEvent_DecodesResearchMinted(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	nft := abis[strings.ToLower(researchNFTAddr)]
+	ev := nft.Events["ResearchMinted"]
+
+	authors := []common.Address{
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"),
+	}
+	data, err := ev.Inputs.NonIndexed().Pack(authors, "Deep Paper", uint8(3), big.NewInt(1700000000))
+	require.NoError(t, err)
+
+	// researches(tokenId) 返回的链上状态
+	out, err := nft.Methods["researches"].Outputs.Pack(
+		big.NewInt(42), "Deep Paper", "abstract", uint8(3), "0xcontent", "0xmeta",
+		big.NewInt(1700000000), uint8(0), uint8(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), true, big.NewInt(0),
+	)
+	require.NoError(t, err)
+
+	chain := newFakeChain()
+	chain.callOut = out
+	chain.callErr = errors.New("missing trie node")
+	header := &types.Header{Number: big.NewInt(9), Time: 1700000012, Difficulty: big.NewInt(1)}
+	chain.byHash[header.Hash()] = header
+
+	var got *model.ParsedEvent
+	el := &EventListener{
+		client:       chain,
+		ctx:          context.Background(),
+		contractABIs: abis,
+		eventHandler: func(e *model.ParsedEvent) error {
+			got = e
+			return nil
+		},
+	}
+	err = el.parseAndHandleEvent(types.Log{
+		Address:     common.HexToAddress(researchNFTAddr),
+		Topics:      []common.Hash{ev.ID, common.BigToHash(big.NewInt(42))},
+		Data:        data,
+		BlockNumber: 9,
+		BlockHash:   header.Hash(),
+		TxHash:      common.HexToHash("0xabc"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	assert.Equal(t, "42", got.TokenID)
+	assert.Equal(t, []string{authors[0].Hex(), authors[1].Hex()}, got.Authors)
+	assert.Equal(t, "0xcontent", got.DataHash)
+	assert.Equal(t, "0xmeta", got.MetadataHash)
+	assert.Equal(t, uint64(1700000012), got.BlockTime)
+	assert.Equal(t, uint8(3), got.Args["pubType"])
+	assert.Equal(t, "1700000000", got.Args["timestamp"])
+
+	// 历史区块调用失败后退回最新状态
+	require.Len(t, chain.calls, 2)
+	assert.Equal(t, uint64(9), chain.calls[0].Uint64())
+	assert.Nil(t, chain.calls[1])
+}
+
+func TestParseAndHandleEvent_EnrichesConstraintGroup(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	manager := abis[strings.ToLower(constraintAddr)]
+	ev := manager.Events["ConstraintGroupCreated"]
+
+	groupID := common.HexToHash("0x01")
+	members := [][32]byte{common.HexToHash("0xc1"), common.HexToHash("0xc2")}
+	data, err := ev.Inputs.NonIndexed().Pack("basic", big.NewInt(2))
+	require.NoError(t, err)
+
+	// getConstraintGroup(groupId) 返回的组详情
+	creator := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	out, err := manager.Methods["getConstraintGroup"].Outputs.Pack(struct {
+		GroupId         [32]byte
+		Name            string
+		Description     string
+		ConstraintIds   [][32]byte
+		IsActive        bool
+		MinSatisfaction *big.Int
+		TotalWeight     *big.Int
+		CreatedAt       *big.Int
+		Creator         common.Address
+	}{groupID, "basic", "basic checks", members, true, big.NewInt(50), big.NewInt(3), big.NewInt(1700000000), creator})
+	require.NoError(t, err)
+
+	chain := newFakeChain()
+	chain.callOut = out
+	header := chain.addBlock(5, common.Hash{}, 0, true)
+
+	var got *model.ParsedEvent
+	el := &EventListener{
+		client:       chain,
+		ctx:          context.Background(),
+		contractABIs: abis,
+		eventHandler: func(e *model.ParsedEvent) error {
+			got = e
+			return nil
+		},
+	}
+	err = el.parseAndHandleEvent(types.Log{
+		Address:     common.HexToAddress(constraintAddr),
+		Topics:      []common.Hash{ev.ID, groupID},
+		Data:        data,
+		BlockNumber: 5,
+		BlockHash:   header.Hash(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	assert.Equal(t, groupID.Hex(), got.TokenID)
+	assert.Equal(t, "basic", got.Title)
+	assert.Equal(t, creator.Hex(), got.Author)
+	assert.Equal(t, "2", got.Args["constraintCount"])
+	assert.Equal(t, "basic checks", got.Args["description"])
+	assert.Equal(t, []interface{}{common.Hash(members[0]).Hex(), common.Hash(members[1]).Hex()}, got.Args["constraintIds"])
+	assert.Equal(t, "50", got.Args["minSatisfaction"])
+}
+
+func TestParseAndHandleEvent_EnrichesInfluenceDetails(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	ranking := abis[strings.ToLower(influenceAddr)]
+	ev := ranking.Events["InfluenceUpdated"]
+
+	user := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(100), big.NewInt(250), big.NewInt(1))
+	require.NoError(t, err)
+
+	// getUserInfluenceDetails(user) 以具名返回值给出各维度得分
+	out, err := ranking.Methods["getUserInfluenceDetails"].Outputs.Pack(
+		big.NewInt(120), big.NewInt(60), big.NewInt(40), big.NewInt(20), big.NewInt(10), big.NewInt(250), big.NewInt(1), big.NewInt(1700000000),
+	)
+	require.NoError(t, err)
+
+	chain := newFakeChain()
+	chain.callOut = out
+	header := chain.addBlock(3, common.Hash{}, 0, true)
+
+	var got *model.ParsedEvent
+	el := &EventListener{
+		client:       chain,
+		ctx:          context.Background(),
+		contractABIs: abis,
+		eventHandler: func(e *model.ParsedEvent) error {
+			got = e
+			return nil
+		},
+	}
+	err = el.parseAndHandleEvent(types.Log{
+		Address:     common.HexToAddress(influenceAddr),
+		Topics:      []common.Hash{ev.ID, common.BytesToHash(user.Bytes())},
+		Data:        data,
+		BlockNumber: 3,
+		BlockHash:   header.Hash(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	assert.Equal(t, user.Hex(), got.Author)
+	assert.Equal(t, "250", got.Args["newInfluence"])
+	assert.Equal(t, "120", got.Args["publicationScore"])
+	assert.Equal(t, "10", got.Args["governanceScore"])
+
+	// 原始日志和仅按ABI解码的参数不含补全字段
+	assert.Equal(t, []string{ev.ID.Hex(), common.BytesToHash(user.Bytes()).Hex()}, got.Topics)
+	assert.Equal(t, hexutil.Encode(data), got.Data)
+	assert.Equal(t, header.Hash().Hex(), got.BlockHash)
+	assert.Equal(t, "250", got.DecodedArgs["newInfluence"])
+	assert.NotContains(t, got.DecodedArgs, "publicationScore")
+}
+
+func TestParseAndHandleEvent_RegistryRenamesContractEvent(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	ev := abis[strings.ToLower(sciTokenAddr)].Events["Transfer"]
+
+	from := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	to := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(1500))
+	require.NoError(t, err)
+
+	chain := newFakeChain()
+	header := chain.addBlock(5, common.Hash{}, 0, true)
+	transfer := types.Log{
+		Address:     common.HexToAddress(sciTokenAddr),
+		Topics:      []common.Hash{ev.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        data,
+		BlockNumber: 5,
+		BlockHash:   header.Hash(),
+	}
+
+	var got *model.ParsedEvent
+	el := &EventListener{
+		client:       chain,
+		contractABIs: abis,
+		eventHandler: func(e *model.ParsedEvent) error {
+			got = e
+			return nil
+		},
+	}
+
+	// 未设置合约名时按ABI事件名处理，仍解码全部参数
+	require.NoError(t, el.parseAndHandleEvent(transfer))
+	assert.Equal(t, "Transfer", got.EventName)
+	assert.Equal(t, map[string]interface{}{"from": from.Hex(), "to": to.Hex(), "value": "1500"}, got.Args)
+
+	el.SetContractNames(map[string]string{sciTokenAddr: "SciToken"})
+	require.NoError(t, el.parseAndHandleEvent(transfer))
+	assert.Equal(t, "TokenTransfer", got.EventName)
+	assert.Equal(t, strings.ToLower(to.Hex()), got.EntityID)
+	assert.Equal(t, header.Time, got.BlockTime)
+}
+
+type memDeadLetterStore struct {
+	letters []*model.DeadLetter
+}
+
+func (s *memDeadLetterStore) InsertDeadLetter(letter *model.DeadLetter) error {
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func TestParseAndHandleEvent_DeadLettersHandlerFailure(t *testing.T) {
+	store := &memDeadLetterStore{}
+	fail := errors.New("insert event log: database is locked")
+	el := &EventListener{
+		eventHandler: func(e *model.ParsedEvent) error {
+			if e.LogIndex == 1 {
+				panic("nil payload")
+			}
+			return fail
+		},
+	}
+	el.SetDeadLetterStore(store)
+
+	vLog := types.Log{Address: common.HexToAddress(sciTokenAddr), BlockNumber: 9, TxHash: common.HexToHash("0x09")}
+	require.ErrorIs(t, el.parseAndHandleEvent(vLog), fail)
+	vLog.Index = 1
+	require.EqualError(t, el.parseAndHandleEvent(vLog), "panic: nil payload")
+
+	require.Len(t, store.letters, 2)
+	letter := store.letters[0]
+	assert.Equal(t, model.DeadLetterSourceListener, letter.Source)
+	assert.Equal(t, fail.Error(), letter.Error)
+	assert.Equal(t, uint64(9), letter.BlockNumber)
+	assert.Equal(t, strings.ToLower(sciTokenAddr), letter.ContractAddr)
+	assert.Contains(t, letter.PayloadRaw, `"tx_hash":"`+vLog.TxHash.Hex()+`"`)
+	assert.Empty(t, letter.Stack)
+	assert.Contains(t, store.letters[1].Stack, "goroutine")
+}
+
+func TestBackfill_RetriesBlockWhenHeaderUnavailable(t *testing.T) {
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	ev := abis[strings.ToLower(registryAddr)].Events["UserRegistered"]
+	data, err := ev.Inputs.NonIndexed().Pack("Alice", uint8(2), big.NewInt(1700000000))
+	require.NoError(t, err)
+
+	addr := common.HexToAddress(registryAddr)
+	chain := newFakeChain()
+	header := &types.Header{Number: big.NewInt(7), Time: 1700000007, Difficulty: big.NewInt(1)}
+	chain.logs = []types.Log{{Address: addr, Topics: []common.Hash{ev.ID, common.HexToHash("0x70")}, Data: data, BlockNumber: 7, BlockHash: header.Hash()}}
+	cursors := &memCursorStore{cursors: map[string]uint64{addr.Hex(): 6}}
+
+	var got []*model.ParsedEvent
+	el := &EventListener{client: chain, ctx: context.Background(), contractABIs: abis, contracts: []common.Address{addr}, cursorStore: cursors, batchSize: 100}
+	el.SetEventHandler(func(e *model.ParsedEvent) error {
+		got = append(got, e)
+		return nil
+	})
+
+	// 区块头暂不可用：不分发事件，游标停在该区块之前
+	err = el.backfill(7)
+	require.ErrorIs(t, err, errBlockTime)
+	assert.Empty(t, got)
+	assert.Equal(t, uint64(6), cursors.cursors[addr.Hex()])
+
+	chain.byHash[header.Hash()] = header
+	require.NoError(t, el.backfill(7))
+	require.Len(t, got, 1)
+	assert.Equal(t, header.Time, got[0].BlockTime)
+	assert.Equal(t, uint64(7), cursors.cursors[addr.Hex()])
+}
+
+func TestNormalizeArg(t *testing.T) {
+	assert.Equal(t, "0x0102", normalizeArg([2]byte{1, 2}))
+	assert.Equal(t, []interface{}{"1", "2"}, normalizeArg([]*big.Int{big.NewInt(1), big.NewInt(2)}))
+	assert.Equal(t, "0x0000000000000000000000000000000000000001", normalizeArg(common.BigToAddress(big.NewInt(1))))
+}
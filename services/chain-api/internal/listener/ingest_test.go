@@ -0,0 +1,135 @@
+package listener
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"desci-backend/internal/service"
+)
+
+const (
+	datasetManagerAddr = "0x0165878A594ca255338adfa4d48449f69242Eb8F"
+	dataVerifierAddr   = "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707"
+)
+
+// datasetTuple DatasetManager.getDataset 返回的结构
+type datasetTuple struct {
+	Id               *big.Int
+	Owner            common.Address
+	Title            string
+	Description      string
+	Keywords         []string
+	DataType         uint8
+	Quality          uint8
+	AccessType       uint8
+	Size             *big.Int
+	IpfsHash         string
+	MetadataHash     string
+	ZkpProofHash     string
+	AccessPrice      *big.Int
+	UploadTime       *big.Int
+	DownloadCount    *big.Int
+	CitationCount    *big.Int
+	RevenueGenerated *big.Int
+	IsActive         bool
+}
+
+// ingestHarness 监听器解码后经 Service.IngestEvent 写入 SQLite，覆盖从链上日志到查询接口的完整路径
+type ingestHarness struct {
+	t     *testing.T
+	el    *EventListener
+	chain *fakeChain
+	svc   *service.Service
+}
+
+func newIngestHarness(t *testing.T) *ingestHarness {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, repository.Migrate(gormDB))
+	svc := service.NewService(repository.NewTestRepository(gormDB))
+
+	abis, err := loadContractABIs("../contracts/contracts.json")
+	require.NoError(t, err)
+	chain := newFakeChain()
+	el := &EventListener{client: chain, ctx: context.Background(), contractABIs: abis, eventHandler: svc.IngestEvent}
+	el.SetContractNames(map[string]string{datasetManagerAddr: "DatasetManager", dataVerifierAddr: "ResearchDataVerifier"})
+	return &ingestHarness{t: t, el: el, chain: chain, svc: svc}
+}
+
+// emit 按ABI编码事件并交给监听器处理；indexed 为按顺序排列的 topic 参数
+func (h *ingestHarness) emit(contract, event string, block uint64, tx string, indexed []common.Hash, args ...interface{}) {
+	ab := h.el.contractABIs[strings.ToLower(contract)]
+	ev := ab.Events[event]
+	data, err := ev.Inputs.NonIndexed().Pack(args...)
+	require.NoError(h.t, err)
+	header := h.chain.addBlock(block, common.Hash{}, 0, true)
+	require.NoError(h.t, h.el.parseAndHandleEvent(types.Log{
+		Address:     common.HexToAddress(contract),
+		Topics:      append([]common.Hash{ev.ID}, indexed...),
+		Data:        data,
+		BlockNumber: block,
+		BlockHash:   header.Hash(),
+		TxHash:      common.HexToHash(tx),
+	}))
+}
+
+// uploadDataset 模拟 DatasetUploaded 事件，getDataset 返回给定的描述和 IPFS 哈希
+func (h *ingestHarness) uploadDataset(id int64, owner common.Address, title, description, ipfsHash string, block uint64) {
+	ab := h.el.contractABIs[strings.ToLower(datasetManagerAddr)]
+	out, err := ab.Methods["getDataset"].Outputs.Pack(datasetTuple{
+		Id: big.NewInt(id), Owner: owner, Title: title, Description: description, Keywords: []string{},
+		Size: big.NewInt(1024), IpfsHash: ipfsHash, AccessPrice: big.NewInt(0), UploadTime: big.NewInt(1700000000),
+		DownloadCount: big.NewInt(0), CitationCount: big.NewInt(0), RevenueGenerated: big.NewInt(0), IsActive: true,
+	})
+	require.NoError(h.t, err)
+	h.chain.callOut = out
+	h.emit(datasetManagerAddr, "DatasetUploaded", block, "0xa0", []common.Hash{common.BigToHash(big.NewInt(id)), common.BytesToHash(owner.Bytes())},
+		title, uint8(0), big.NewInt(1024))
+}
+
+func TestIngest_DatasetUploadedJoinsDataQuality(t *testing.T) {
+	h := newIngestHarness(t)
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	h.uploadDataset(9, owner, "Cohort", "genome cohort", "QmData9", 10)
+
+	dataset, err := h.svc.GetDatasetByID("9")
+	require.NoError(t, err)
+	assert.Equal(t, "QmData9", dataset.DataHash)
+	assert.Equal(t, "genome cohort", dataset.Description)
+
+	// 验证器在另一笔交易、另一个合约中提交数据，通过 IPFS 哈希关联到数据集
+	dataID := common.HexToHash("0xd9")
+	h.emit(dataVerifierAddr, "DataSubmitted", 11, "0xb0", []common.Hash{dataID, common.BytesToHash(owner.Bytes())}, "genomic", "QmData9")
+
+	features, _, err := h.svc.GetDatasetFeatures("9", 0)
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+	assert.Equal(t, model.DataQualitySourceVerifier, features[0].Source)
+	assert.Equal(t, dataID.Hex(), features[0].SubjectID)
+	assert.Equal(t, "genomic", features[0].DataType)
+}
+
+func TestIngest_DatasetUploadedIsSearchable(t *testing.T) {
+	h := newIngestHarness(t)
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	h.uploadDataset(9, owner, "Cohort", "whole genome sequencing of a rare disease cohort", "QmData9", 10)
+
+	// 只出现在描述中的词也能检索到，描述来自 getDataset 而不是事件参数
+	page, err := h.svc.Search(repository.SearchFilter{Query: "sequencing", Type: "dataset", Owner: owner.Hex()}, repository.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "9", page.Items[0].DocID)
+	assert.Equal(t, "Cohort", page.Items[0].Title)
+	assert.Contains(t, page.Items[0].Snippet, "<mark>sequencing</mark>")
+}
@@ -1,32 +1,61 @@
 package listener
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"log"
+	"maps"
 	"math/big"
 	"encoding/json"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"desci-backend/internal/model"
 )
 
+// chainClient 监听器依赖的链上RPC能力（*ethclient.Client 实现该接口）
+type chainClient interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
 type EventListener struct {
-	client       *ethclient.Client
-	contracts    []common.Address
-	startBlock   uint64
-	eventChan    chan types.Log
-	ctx          context.Context
-	cancel       context.CancelFunc
-	eventHandler func(*model.ParsedEvent) error
-	contractABIs map[string]*abi.ABI
+	client        chainClient
+	contracts     []common.Address
+	startBlock    uint64
+	ctx           context.Context
+	cancel        context.CancelFunc
+	eventHandler  func(*model.ParsedEvent) error
+	contractABIs  map[string]*abi.ABI
+	contractNames map[string]string
+	blockStore    BlockStore
+	maxReorgDepth int
+	cursorStore   CursorStore
+	deadLetters   DeadLetterStore
+	batchSize     uint64
+	phase         string
+	phaseMu       sync.RWMutex
+	position      logPosition
+	mode          string
+	pollInterval  time.Duration
+
+	// 最近一次查询的区块头，同一区块的连续日志共用
+	lastHeaderHash common.Hash
+	lastHeaderTime uint64
 }
 
 func NewEventListener(rpcURL string, contractAddresses []string, startBlock uint64, contractsConfigPath string) (*EventListener, error) {
@@ -45,13 +74,17 @@ func NewEventListener(rpcURL string, contractAddresses []string, startBlock uint
 	abis, _ := loadContractABIs(contractsConfigPath)
 
 	return &EventListener{
-		client:     client,
-		contracts:  contracts,
-		startBlock: startBlock,
-		eventChan:  make(chan types.Log, 100),
-		ctx:        ctx,
-		cancel:     cancel,
-		contractABIs: abis,
+		client:        client,
+		contracts:     contracts,
+		startBlock:    startBlock,
+		ctx:           ctx,
+		cancel:        cancel,
+		contractABIs:  abis,
+		maxReorgDepth: defaultMaxReorgDepth,
+		batchSize:     defaultBackfillBatchSize,
+		phase:         PhaseIdle,
+		mode:          modeForURL(rpcURL),
+		pollInterval:  defaultPollInterval,
 	}, nil
 }
 
@@ -59,103 +92,46 @@ func (el *EventListener) SetEventHandler(handler func(*model.ParsedEvent) error)
 	el.eventHandler = handler
 }
 
-func (el *EventListener) Start() error {
-	log.Printf("Starting event listener for %d contracts...", len(el.contracts))
-
-	// 先获取历史事件
-	go el.processHistoricalEvents()
-
-	// 然后监听新事件
-	go el.subscribeToNewEvents()
-
-	// 处理事件
-	go el.processEvents()
-
-	return nil
-}
-
-func (el *EventListener) processHistoricalEvents() {
-	log.Println("Fetching historical events...")
-
-	query := ethereum.FilterQuery{
-		FromBlock: big.NewInt(int64(el.startBlock)),
-		ToBlock:   nil, // latest
-		Addresses: el.contracts,
+// SetContractNames 设置合约地址到合约名的映射，用于区分不同合约的同名事件
+func (el *EventListener) SetContractNames(names map[string]string) {
+	el.contractNames = make(map[string]string, len(names))
+	for addr, name := range names {
+		el.contractNames[strings.ToLower(addr)] = name
 	}
+}
 
-	logs, err := el.client.FilterLogs(el.ctx, query)
-	if err != nil {
-		log.Printf("Error fetching historical logs: %v", err)
-		return
-	}
+// SetBlockStore 设置区块哈希存储，启用链重组检测与回滚
+func (el *EventListener) SetBlockStore(store BlockStore) {
+	el.blockStore = store
+}
 
-	log.Printf("Found %d historical events", len(logs))
-	for _, vLog := range logs {
-		select {
-		case el.eventChan <- vLog:
-		case <-el.ctx.Done():
-			return
-		}
+// SetMaxReorgDepth 设置回溯查找分叉点的最大区块数
+func (el *EventListener) SetMaxReorgDepth(depth int) {
+	if depth > 0 {
+		el.maxReorgDepth = depth
 	}
 }
 
-func (el *EventListener) subscribeToNewEvents() {
-	for {
-		query := ethereum.FilterQuery{Addresses: el.contracts}
-		logsCh := make(chan types.Log, 100)
-		sub, err := el.client.SubscribeFilterLogs(el.ctx, query, logsCh)
-		if err != nil {
-			log.Printf("Error subscribing to logs: %v", err)
-			select {
-			case <-time.After(3 * time.Second):
-				continue
-			case <-el.ctx.Done():
-				return
-			}
-		}
+// SetCursorStore 设置同步游标存储，启用断点续扫
+func (el *EventListener) SetCursorStore(store CursorStore) {
+	el.cursorStore = store
+}
 
-		log.Println("Subscribed to new events...")
-
-		for {
-			select {
-			case err := <-sub.Err():
-				log.Printf("Subscription error: %v", err)
-				sub.Unsubscribe()
-				select {
-				case <-time.After(2 * time.Second):
-					break
-				case <-el.ctx.Done():
-					return
-				}
-				break
-			case vLog := <-logsCh:
-				log.Printf("New event received: block %d, tx %s", vLog.BlockNumber, vLog.TxHash.Hex())
-				select {
-				case el.eventChan <- vLog:
-				case <-el.ctx.Done():
-					return
-				}
-			case <-el.ctx.Done():
-				sub.Unsubscribe()
-				return
-			}
-		}
+// SetBackfillBatchSize 设置历史回填每次查询的区块窗口
+func (el *EventListener) SetBackfillBatchSize(size uint64) {
+	if size > 0 {
+		el.batchSize = size
 	}
 }
 
-func (el *EventListener) processEvents() {
-	log.Println("Event processor started...")
+func (el *EventListener) Start() error {
+	log.Printf("Starting event listener for %d contracts...", len(el.contracts))
 
-	for {
-		select {
-		case vLog := <-el.eventChan:
-			if err := el.parseAndHandleEvent(vLog); err != nil {
-				log.Printf("Error processing event: %v", err)
-			}
-		case <-el.ctx.Done():
-			return
-		}
-	}
+	// 回填、追赶与实时阶段由同一个状态机按顺序驱动
+	log.Printf("Live mode: %s", el.Mode())
+	go el.run()
+
+	return nil
 }
 
 func (el *EventListener) parseAndHandleEvent(vLog types.Log) error {
@@ -164,158 +140,81 @@ func (el *EventListener) parseAndHandleEvent(vLog types.Log) error {
 		return nil
 	}
 
-	eventName := "UnknownEvent"
-	title := ""
-	tokenStr := ""
-	authorAddr := ""
-	var authors []string
+	// ResearchMinted 和 DatasetUploaded 由解码器从合约读取数据哈希，其他事件沿用交易哈希
+	parsedEvent := &model.ParsedEvent{
+		Contract:  vLog.Address.Hex(),
+		DataHash:  vLog.TxHash.Hex(),
+		Block:     vLog.BlockNumber,
+		TxHash:    vLog.TxHash.Hex(),
+		LogIndex:  uint(vLog.Index),
+		EventName: "UnknownEvent",
+		Data:      hexutil.Encode(vLog.Data),
+		BlockHash: vLog.BlockHash.Hex(),
+	}
+	for _, topic := range vLog.Topics {
+		parsedEvent.Topics = append(parsedEvent.Topics, topic.Hex())
+	}
 
 	addrKey := strings.ToLower(vLog.Address.Hex())
 	if ab, ok := el.contractABIs[addrKey]; ok && len(vLog.Topics) > 0 {
-		for name, ev := range ab.Events {
-			if ev.ID == vLog.Topics[0] {
-				eventName = name
-				vals := map[string]interface{}{}
-				_ = ev.Inputs.UnpackIntoMap(vals, vLog.Data)
-				switch name {
-				case "ResearchMinted":
-					if len(vLog.Topics) > 1 {
-						bi := new(big.Int).SetBytes(vLog.Topics[1].Bytes())
-						tokenStr = bi.String()
-					}
-					if v, ok := vals["authors"]; ok {
-						if arr, ok2 := v.([]common.Address); ok2 && len(arr) > 0 {
-							authorAddr = arr[0].Hex()
-							for _, a := range arr {
-								authors = append(authors, a.Hex())
-							}
-						}
-					}
-					if v, ok := vals["title"].(string); ok {
-						title = v
-					}
-				case "DatasetUploaded":
-					if len(vLog.Topics) > 1 {
-						bi := new(big.Int).SetBytes(vLog.Topics[1].Bytes())
-						tokenStr = bi.String()
-					}
-					if len(vLog.Topics) > 2 {
-						authorAddr = common.HexToAddress(vLog.Topics[2].Hex()).Hex()
-					}
-					if v, ok := vals["title"].(string); ok {
-						title = v
-					}
-				case "UserRegistered":
-					if len(vLog.Topics) > 1 {
-						authorAddr = common.HexToAddress(vLog.Topics[1].Hex()).Hex()
-					}
-				case "ProofSubmitted":
-					log.Printf("🔍 [ZKP] ProofSubmitted event detected!")
-					if len(vLog.Topics) > 1 {
-						bi := new(big.Int).SetBytes(vLog.Topics[1].Bytes())
-						tokenStr = bi.String()
-						log.Printf("🔍 [ZKP] Proof ID: %s", tokenStr)
-					}
-					if len(vLog.Topics) > 2 {
-						authorAddr = common.HexToAddress(vLog.Topics[2].Hex()).Hex()
-						log.Printf("🔍 [ZKP] Submitter: %s", authorAddr)
-					}
-					if v, ok := vals["proofData"].(string); ok {
-						maxLen := 100
-						if len(v) < maxLen {
-							maxLen = len(v)
-						}
-						log.Printf("🔍 [ZKP] Proof Data: %s...", v[:maxLen])
-						title = "ZK Proof #" + tokenStr
-					}
-					if v, ok := vals["publicInputs"].(string); ok {
-						maxLen := 100
-						if len(v) < maxLen {
-							maxLen = len(v)
-						}
-						log.Printf("🔍 [ZKP] Public Inputs: %s...", v[:maxLen])
-					}
-				}
-				break
+		if ev, err := ab.EventByID(vLog.Topics[0]); err == nil {
+			vals, err := decodeLogArgs(*ev, vLog)
+			if err != nil {
+				log.Printf("⚠️  Failed to decode %s args: %v", ev.Name, err)
+			}
+			parsedEvent.EventName = ev.Name
+			// 解码器只在 Args 顶层补全或覆盖字段，DecodedArgs 保留其浅拷贝作为仅按ABI解码的参数
+			parsedEvent.DecodedArgs = normalizeArgs(vals)
+			parsedEvent.Args = maps.Clone(parsedEvent.DecodedArgs)
+
+			spec := lookupEvent(el.contractNames[addrKey], ev.Name)
+			if spec.decode != nil {
+				spec.decode(el, &decodedLog{log: vLog, abi: ab, name: ev.Name, vals: vals, event: parsedEvent})
+			}
+			if spec.name != "" {
+				parsedEvent.EventName = spec.name
 			}
 		}
 	}
 
-	if title == "" {
-		if eventName == "ResearchMinted" && tokenStr != "" {
-			title = "Research #" + tokenStr
-		} else if eventName == "DatasetUploaded" && tokenStr != "" {
-			title = "Dataset #" + tokenStr
-		} else {
-			title = "Blockchain Event"
+	if parsedEvent.EventName != "UnknownEvent" {
+		t, err := el.blockTime(vLog)
+		if err != nil {
+			// 不分发缺少区块时间的事件，由调用方从该区块重试
+			return fmt.Errorf("%w %d: %v", errBlockTime, vLog.BlockNumber, err)
 		}
+		parsedEvent.BlockTime = t
 	}
 
-	parsedEvent := &model.ParsedEvent{
-		TokenID:     tokenStr,
-		Author:      authorAddr,
-		DataHash:    vLog.TxHash.Hex(),
-		Block:       vLog.BlockNumber,
-		TxHash:      vLog.TxHash.Hex(),
-		LogIndex:    uint(vLog.Index),
-		EventName:   eventName,
-		Title:       title,
-		Description: "",
+	// 其余事件以 datasetId/tokenId 参数作为实体ID
+	if parsedEvent.TokenID == "" {
+		for _, key := range []string{"datasetId", "tokenId", "_tokenId", "toTokenId", "proofId", "constraintId", "groupId", "ruleId", "dataId", "featureId"} {
+			if v, ok := parsedEvent.Args[key].(string); ok {
+				parsedEvent.TokenID = v
+				break
+			}
+		}
 	}
-
-	log.Printf("📡 Processing event: %s, TokenID=%s, Block=%d", eventName, parsedEvent.TokenID, parsedEvent.Block)
-
-	return el.eventHandler(parsedEvent)
-}
-
-// 根据事件特征猜测事件类型
-func (el *EventListener) guessEventType(vLog types.Log) string {
-	// 简单的启发式判断
-	if len(vLog.Topics) >= 2 {
-		return "UserRegistered"
-	} else if len(vLog.Data) > 32 {
-		return "ResearchMinted"
-	} else {
-		return "DatasetUploaded"
+	if parsedEvent.EntityID == "" {
+		parsedEvent.EntityID = parsedEvent.TokenID
 	}
-}
-
-// 为事件生成标题
-func (el *EventListener) generateTitleForEvent(eventName string) string {
-	switch eventName {
-	case "UserRegistered":
-		return "新用户注册到平台"
-	case "DatasetUploaded":
-		return "科研数据集上传"
-	case "ResearchMinted":
-		return "研究成果NFT铸造"
-	default:
-		return "区块链事件"
+	if parsedEvent.Title == "" {
+		parsedEvent.Title = "Blockchain Event"
 	}
+
+	log.Printf("📡 Processing event: %s, TokenID=%s, Block=%d", parsedEvent.EventName, parsedEvent.TokenID, parsedEvent.Block)
+
+	return el.dispatch(parsedEvent)
 }
 
-// 为事件生成描述
-func (el *EventListener) generateDescriptionForEvent(eventName string) string {
-	switch eventName {
-	case "UserRegistered":
-		return "用户成功注册到DeSci平台"
-	case "DatasetUploaded":
-		return "研究数据集已上传到平台"
-	case "ResearchMinted":
-		return "研究成果已铸造为NFT"
-	default:
-		return "来自区块链的事件数据"
-	}
+// LatestBlock 返回节点当前的最新区块高度
+func (el *EventListener) LatestBlock(ctx context.Context) (uint64, error) {
+	return el.client.BlockNumber(ctx)
 }
 
 func (el *EventListener) Stop() {
 	log.Println("Stopping event listener...")
 	el.cancel()
-	close(el.eventChan)
-}
-
-func (el *EventListener) GetEventChannel() <-chan types.Log {
-	return el.eventChan
 }
 
 func loadContractABIs(configPath string) (map[string]*abi.ABI, error) {
@@ -333,7 +232,8 @@ func loadContractABIs(configPath string) (map[string]*abi.ABI, error) {
 			ABI     json.RawMessage `json:"abi"`
 		} `json:"contracts"`
 	}
-	if err := json.Unmarshal(b, &payload); err != nil {
+	// 部署脚本可能重复追加输出，只解码第一个JSON文档
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&payload); err != nil {
 		return result, err
 	}
 	for _, c := range payload.Contracts {
@@ -0,0 +1,98 @@
+package listener
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 实时阶段的事件获取方式
+const (
+	ModeAuto      = "auto"      // 根据RPC地址协议自动选择
+	ModeSubscribe = "subscribe" // eth_subscribe 推送（需要 websocket/IPC）
+	ModePoll      = "poll"      // eth_blockNumber + 分段 eth_getLogs 轮询
+)
+
+// defaultPollInterval 轮询模式下查询新区块的间隔
+const defaultPollInterval = 3 * time.Second
+
+// modeForURL 根据RPC地址的协议选择实时模式：HTTP 端点不支持订阅，使用轮询
+func modeForURL(rpcURL string) string {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return ModeSubscribe
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return ModePoll
+	default:
+		// ws/wss 以及 IPC 路径都支持订阅
+		return ModeSubscribe
+	}
+}
+
+// SetMode 强制指定实时模式；ModeAuto 或空值保持按RPC地址自动选择
+func (el *EventListener) SetMode(mode string) {
+	switch mode {
+	case ModeSubscribe, ModePoll:
+		el.setMode(mode)
+	case ModeAuto, "":
+	default:
+		log.Printf("⚠️  Unknown listener mode %q, keeping %s", mode, el.Mode())
+	}
+}
+
+// Mode 返回当前的实时模式
+func (el *EventListener) Mode() string {
+	el.phaseMu.RLock()
+	defer el.phaseMu.RUnlock()
+	if el.mode == "" {
+		return ModeSubscribe
+	}
+	return el.mode
+}
+
+func (el *EventListener) setMode(mode string) {
+	el.phaseMu.Lock()
+	el.mode = mode
+	el.phaseMu.Unlock()
+}
+
+// SetPollInterval 设置轮询模式下查询新区块的间隔
+func (el *EventListener) SetPollInterval(interval time.Duration) {
+	if interval > 0 {
+		el.pollInterval = interval
+	}
+}
+
+// followLive 按当前模式进入实时阶段；订阅不被节点支持时自动降级为轮询
+func (el *EventListener) followLive() error {
+	if el.Mode() == ModePoll {
+		return el.followPolling()
+	}
+
+	err := el.followSubscription()
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		log.Printf("⚠️  RPC endpoint does not support subscriptions, falling back to polling")
+		el.setMode(ModePoll)
+		return nil
+	}
+	return err
+}
+
+// followPolling 周期性读取链头，并以分段 eth_getLogs 拉取新区块的日志
+func (el *EventListener) followPolling() error {
+	el.setPhase(PhaseLive)
+	log.Printf("Polling for new events every %s...", el.pollInterval)
+
+	for el.sleep(el.pollInterval) {
+		if err := el.catchUp(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
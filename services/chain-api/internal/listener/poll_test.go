@@ -0,0 +1,85 @@
+package listener
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"desci-backend/internal/model"
+)
+
+func TestModeForURL(t *testing.T) {
+	assert.Equal(t, ModePoll, modeForURL("http://localhost:8545"))
+	assert.Equal(t, ModePoll, modeForURL("HTTPS://rpc.example.org"))
+	assert.Equal(t, ModeSubscribe, modeForURL("ws://localhost:8546"))
+	assert.Equal(t, ModeSubscribe, modeForURL("wss://rpc.example.org"))
+	assert.Equal(t, ModeSubscribe, modeForURL("/tmp/geth.ipc"))
+}
+
+func TestSetMode_AutoKeepsDetectedMode(t *testing.T) {
+	el := &EventListener{mode: modeForURL("http://localhost:8545")}
+	el.SetMode(ModeAuto)
+	assert.Equal(t, ModePoll, el.Mode())
+
+	el.SetMode(ModeSubscribe)
+	assert.Equal(t, ModeSubscribe, el.Mode())
+}
+
+func TestRun_FallsBackToPollingWithoutSubscriptions(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	chain := newFakeChain()
+	chain.head = 10
+	chain.logs = []types.Log{{Address: addr, BlockNumber: 3}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	el := &EventListener{
+		client:       chain,
+		ctx:          ctx,
+		contracts:    []common.Address{addr},
+		batchSize:    100,
+		mode:         ModeSubscribe,
+		pollInterval: 5 * time.Millisecond,
+	}
+
+	var mu sync.Mutex
+	var blocks []uint64
+	live := make(chan struct{})
+	el.SetEventHandler(func(event *model.ParsedEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		blocks = append(blocks, event.Block)
+		if event.Block == 12 {
+			close(live)
+		}
+		return nil
+	})
+
+	go el.run()
+
+	// 等待降级为轮询后出块
+	deadline := time.After(5 * time.Second)
+	for el.Mode() != ModePoll || el.Phase() != PhaseLive {
+		select {
+		case <-deadline:
+			t.Fatal("listener did not switch to polling")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	chain.mine(12, types.Log{Address: addr, BlockNumber: 12})
+
+	select {
+	case <-live:
+	case <-deadline:
+		t.Fatal("timed out waiting for polled event")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []uint64{3, 12}, blocks)
+}
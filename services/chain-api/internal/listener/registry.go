@@ -0,0 +1,238 @@
+package listener
+
+import (
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"desci-backend/internal/model"
+)
+
+// decodedLog 一条已按ABI解码的日志；vals 为原始解码值，event.Args 为可JSON序列化的全部参数
+type decodedLog struct {
+	log   types.Log
+	abi   *abi.ABI
+	name  string
+	vals  map[string]interface{}
+	event *model.ParsedEvent
+}
+
+// eventDecoder 在通用解码结果上补充实体ID、作者、标题等字段，或从合约读取详情补全参数
+type eventDecoder func(el *EventListener, d *decodedLog)
+
+// eventSpec 合约事件的注册项
+type eventSpec struct {
+	// name 写入 event_logs 的事件名，空时沿用ABI事件名
+	name   string
+	decode eventDecoder
+}
+
+// eventRegistry 以 "合约名.事件名"（区分不同合约的同名事件）或 "事件名" 为键的事件注册表；
+// 未注册的事件同样会解码全部参数，只是不做额外处理
+var eventRegistry = map[string]eventSpec{
+	"ResearchMinted":  {name: "ResearchCreated", decode: decodeResearchMinted},
+	"DatasetUploaded": {name: "DatasetCreated", decode: decodeDatasetUploaded},
+
+	"DatasetManager.RevenueDistributed": {name: "DatasetRevenueDistributed"},
+	"DatasetManager.Transfer":           {name: "DatasetTransfer"},
+	"ResearchNFT.RevenueDistributed":    {name: "ResearchRevenueDistributed"},
+	"ResearchNFT.Transfer":              {name: "ResearchTransfer"},
+
+	"UserRegistered":        {decode: decodeUser("user")},
+	"UserVerified":          {decode: decodeUser("user")},
+	"RoleChanged":           {decode: decodeUser("user")},
+	"ReputationUpdated":     {decode: decodeUser("user")},
+	"VerificationRequested": {decode: decodeVerificationRequested},
+	"RoleGranted":           {decode: decodeUser("account")},
+	"RoleRevoked":           {decode: decodeUser("account")},
+	"Paused":                {decode: decodeUser("account")},
+	"Unpaused":              {decode: decodeUser("account")},
+
+	"ProofSubmitted":            {decode: decodeProofSubmitted},
+	"ProofVerified":             {decode: decodeAuthor("verifier")},
+	"ZKPVerifier.ProofVerified": {name: "ProofHashVerified", decode: decodeEntityArg("proofHash", "verifier")},
+	"ProofTypeAdded":            {decode: decodeEntityArg("proofType", "")},
+	"ProofTypeUpdated":          {decode: decodeEntityArg("proofType", "")},
+	"ProofTypeRegistered":       {decode: decodeEntityArg("proofType", "")},
+	"ConstraintCreated":         {decode: decodeConstraint},
+	"ConstraintUpdated":         {decode: decodeConstraint},
+	"ConstraintGroupCreated":    {decode: decodeConstraint},
+	"ValidationRuleCreated":     {decode: decodeConstraint},
+	"FeaturesCalculated":        {decode: decodeFeatures},
+	"FeaturesExtracted":         {decode: decodeFeatures},
+	"DataSubmitted":             {decode: decodeDataSubmitted},
+	"ConstraintAdded":           {decode: decodeEntityArg("constraintType", "")},
+	"InfluenceUpdated":          {decode: decodeInfluenceUpdated},
+	"InfluenceRankingUpdated":   {decode: decodeUser("user")},
+	"RewardDistributed":         {decode: decodeUser("user")},
+	"RankingUpdated":            {decode: decodeEntityArg("identifier", "")},
+	"CollaborationFormed":       {decode: decodeCollaboration},
+	"SciToken.Transfer":         {name: "TokenTransfer", decode: decodeUser("to")},
+	"SciToken.Approval":         {name: "TokenApproval", decode: decodeUser("owner")},
+}
+
+// lookupEvent 先按合约限定名、再按事件名查找注册项
+func lookupEvent(contract, eventName string) eventSpec {
+	if contract != "" {
+		if spec, ok := eventRegistry[contract+"."+eventName]; ok {
+			return spec
+		}
+	}
+	return eventRegistry[eventName]
+}
+
+// decodeAuthor 以指定地址参数作为事件作者
+func decodeAuthor(arg string) eventDecoder {
+	return func(el *EventListener, d *decodedLog) {
+		d.event.Author = addressArg(d.vals, arg)
+	}
+}
+
+// decodeUser 以指定地址参数作为作者，并按小写地址聚合实体
+func decodeUser(arg string) eventDecoder {
+	return func(el *EventListener, d *decodedLog) {
+		d.event.Author = addressArg(d.vals, arg)
+		d.event.EntityID = strings.ToLower(d.event.Author)
+		if v, ok := d.vals["name"].(string); ok {
+			d.event.Title = v
+		}
+	}
+}
+
+func decodeVerificationRequested(el *EventListener, d *decodedLog) {
+	decodeUser("applicant")(el, d)
+	if v, ok := d.vals["requestId"].(*big.Int); ok {
+		d.event.TokenID = v.String()
+	}
+}
+
+// decodeEntityArg 以指定参数作为实体ID，authorArg 非空时同时记录作者
+func decodeEntityArg(arg, authorArg string) eventDecoder {
+	return func(el *EventListener, d *decodedLog) {
+		d.event.EntityID, _ = d.event.Args[arg].(string)
+		if authorArg != "" {
+			d.event.Author = addressArg(d.vals, authorArg)
+		}
+	}
+}
+
+// decodeResearchMinted 事件不含哈希，从合约状态读取真实的内容/元数据哈希
+func decodeResearchMinted(el *EventListener, d *decodedLog) {
+	e := d.event
+	if tokenID, ok := d.vals["tokenId"].(*big.Int); ok {
+		e.TokenID = tokenID.String()
+		contentHash, metadataHash, err := el.fetchResearchHashes(d.log, d.abi, tokenID)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch research #%s hashes: %v", e.TokenID, err)
+		}
+		e.DataHash, e.MetadataHash = contentHash, metadataHash
+	}
+	if arr, ok := d.vals["authors"].([]common.Address); ok && len(arr) > 0 {
+		e.Author = arr[0].Hex()
+		for _, a := range arr {
+			e.Authors = append(e.Authors, a.Hex())
+		}
+	}
+	e.Title, _ = d.vals["title"].(string)
+	if e.Title == "" && e.TokenID != "" {
+		e.Title = "Research #" + e.TokenID
+	}
+	e.Args["title"] = e.Title
+	e.Args["contentHash"] = e.DataHash
+	e.Args["metadataHash"] = e.MetadataHash
+	if _, ok := e.Args["authors"]; !ok {
+		e.Args["authors"] = e.Authors
+	}
+}
+
+// decodeDatasetUploaded 数据集以所有者为作者；事件不含描述和 IPFS 哈希，从 getDataset 读取。
+// 数据哈希取 IPFS 哈希，质量验证器的 DataSubmitted 以此关联数据集，读取失败时留空而不用交易哈希代替
+func decodeDatasetUploaded(el *EventListener, d *decodedLog) {
+	e := d.event
+	e.Author = addressArg(d.vals, "owner")
+	e.Title, _ = d.vals["title"].(string)
+	id, ok := d.vals["datasetId"].(*big.Int)
+	if ok && e.Title == "" {
+		e.Title = "Dataset #" + id.String()
+	}
+	e.Args["title"] = e.Title
+	if ok {
+		if err := el.enrichArgs(d.log, d.abi, "getDataset", id, e.Args); err != nil {
+			log.Printf("⚠️  Failed to fetch dataset %s: %v", id, err)
+		}
+	}
+	// 事件只含标题，描述和 IPFS 哈希来自 getDataset
+	e.Description, _ = e.Args["description"].(string)
+	e.DataHash, _ = e.Args["ipfsHash"].(string)
+}
+
+func decodeProofSubmitted(el *EventListener, d *decodedLog) {
+	e := d.event
+	e.Author = addressArg(d.vals, "submitter")
+	if v, ok := d.vals["proofId"].(*big.Int); ok {
+		e.TokenID = v.String()
+		e.Title = "ZK Proof #" + e.TokenID
+	}
+	log.Printf("🔍 [ZKP] ProofSubmitted: proofId=%s, submitter=%s, type=%v", e.TokenID, e.Author, e.Args["proofType"])
+}
+
+// decodeConstraint 事件只含名称等少量字段，阈值、成员关系等从合约读取
+func decodeConstraint(el *EventListener, d *decodedLog) {
+	getter, key := constraintGetter(d.name)
+	if id, ok := d.vals[key].([32]byte); ok {
+		if err := el.enrichArgs(d.log, d.abi, getter, id, d.event.Args); err != nil {
+			log.Printf("⚠️  Failed to fetch %s details: %v", d.name, err)
+		}
+	}
+	// 组和规则事件不含创建者，取 getter 返回的 creator
+	d.event.Author = addressArg(d.vals, "creator")
+	if v, ok := d.event.Args["creator"].(string); ok && d.event.Author == "" {
+		d.event.Author = v
+	}
+	if v, ok := d.vals["name"].(string); ok {
+		d.event.Title = v
+	}
+}
+
+// decodeFeatures 事件只含部分统计量，特征哈希、极值和样本数从合约读取
+func decodeFeatures(el *EventListener, d *decodedLog) {
+	key := "featureId"
+	if d.name == "FeaturesExtracted" {
+		key = "dataId"
+	}
+	if id, ok := d.vals[key].([32]byte); ok {
+		if err := el.enrichArgs(d.log, d.abi, "getDataFeatures", id, d.event.Args); err != nil {
+			log.Printf("⚠️  Failed to fetch %s features: %v", d.name, err)
+		}
+	}
+	d.event.Author = addressArg(d.vals, "calculator")
+}
+
+func decodeDataSubmitted(el *EventListener, d *decodedLog) {
+	d.event.Author = addressArg(d.vals, "submitter")
+	if v, ok := d.vals["dataHash"].(string); ok {
+		d.event.DataHash = v
+	}
+}
+
+// decodeInfluenceUpdated 事件只含总影响力，各维度得分从合约读取
+func decodeInfluenceUpdated(el *EventListener, d *decodedLog) {
+	decodeUser("user")(el, d)
+	if user, ok := d.vals["user"].(common.Address); ok {
+		if err := el.enrichArgs(d.log, d.abi, "getUserInfluenceDetails", user, d.event.Args); err != nil {
+			log.Printf("⚠️  Failed to fetch influence details of %s: %v", d.event.Author, err)
+		}
+	}
+}
+
+// decodeCollaboration 合作关系以研究成果ID为实体
+func decodeCollaboration(el *EventListener, d *decodedLog) {
+	d.event.Author = addressArg(d.vals, "user1")
+	if v, ok := d.vals["researchId"].(*big.Int); ok {
+		d.event.TokenID = v.String()
+	}
+}
@@ -0,0 +1,215 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+
+	"desci-backend/internal/model"
+)
+
+// defaultMaxReorgDepth 默认回溯查找分叉点的最大区块数
+const defaultMaxReorgDepth = 64
+
+// BlockStore 已索引区块哈希的持久化接口（repository.Repository 实现该接口）
+type BlockStore interface {
+	GetIndexedBlock(number uint64) (*model.IndexedBlock, error)
+	GetLatestIndexedBlockBefore(number uint64) (*model.IndexedBlock, error)
+	SaveIndexedBlock(block *model.IndexedBlock) error
+	RollbackFromBlock(number uint64) error
+}
+
+// errReorgCheck 重组检测或回滚失败：该日志未分发，调用方需从其所在区块重试
+var errReorgCheck = errors.New("reorg check failed")
+
+// errBlockTime 获取区块时间失败：该日志未分发，调用方需从其所在区块重试
+var errBlockTime = errors.New("fetch time of block")
+
+// retryBlock 判断日志是否因重组检测或区块时间获取失败而未分发
+func retryBlock(err error) bool {
+	return errors.Is(err, errReorgCheck) || errors.Is(err, errBlockTime)
+}
+
+// handleLog 在分发事件前执行链重组检测；检测或获取区块时间失败时不分发也不推进位置
+func (el *EventListener) handleLog(vLog types.Log) error {
+	if vLog.Removed {
+		if err := el.handleRemovedLog(vLog); err != nil {
+			return fmt.Errorf("%w at block %d: %v", errReorgCheck, vLog.BlockNumber, err)
+		}
+		return nil
+	}
+	if err := el.checkReorg(vLog); err != nil {
+		return fmt.Errorf("%w at block %d: %v", errReorgCheck, vLog.BlockNumber, err)
+	}
+	err := el.parseAndHandleEvent(vLog)
+	if errors.Is(err, errBlockTime) {
+		return err
+	}
+	el.advancePosition(vLog)
+	return err
+}
+
+// rollback 回滚数据库并将已分发位置退回到分叉点之前
+func (el *EventListener) rollback(number uint64) error {
+	if err := el.blockStore.RollbackFromBlock(number); err != nil {
+		return err
+	}
+	el.rewindTo(number)
+	return nil
+}
+
+// handleRemovedLog 处理节点推送的 removed 日志：其所在区块已被孤立，回滚该区块及之后的数据
+func (el *EventListener) handleRemovedLog(vLog types.Log) error {
+	if el.blockStore == nil {
+		log.Printf("Removed log ignored (no block store): block %d, tx %s", vLog.BlockNumber, vLog.TxHash.Hex())
+		return nil
+	}
+
+	stored, err := el.blockStore.GetIndexedBlock(vLog.BlockNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	// 只有记录的仍是被孤立的区块时才回滚，避免重复回滚已重放的规范链数据
+	if stored.Hash != vLog.BlockHash.Hex() {
+		return nil
+	}
+
+	log.Printf("🔀 Removed log for block %d (%s), rolling back", vLog.BlockNumber, stored.Hash)
+	return el.rollback(vLog.BlockNumber)
+}
+
+// checkReorg 比对区块哈希与父哈希，发现分叉时回滚孤立区块并重放规范链区间
+func (el *EventListener) checkReorg(vLog types.Log) error {
+	if el.blockStore == nil {
+		return nil
+	}
+
+	number := vLog.BlockNumber
+	hash := vLog.BlockHash.Hex()
+
+	stored, err := el.blockStore.GetIndexedBlock(number)
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if found && stored.Hash == hash {
+		return nil
+	}
+
+	header, err := el.client.HeaderByHash(el.ctx, vLog.BlockHash)
+	if err != nil {
+		return fmt.Errorf("fetch header %s: %w", hash, err)
+	}
+
+	reorged := found
+	if !reorged {
+		if reorged, err = el.parentMismatch(number, header); err != nil {
+			return err
+		}
+	}
+
+	if reorged {
+		forkPoint, err := el.findForkPoint(number)
+		if err != nil {
+			return err
+		}
+		log.Printf("🔀 Chain reorg detected at block %d, fork point %d", number, forkPoint)
+		if err := el.rollback(forkPoint); err != nil {
+			return fmt.Errorf("rollback from %d: %w", forkPoint, err)
+		}
+		if forkPoint < number {
+			if err := el.replayRange(forkPoint, number-1); err != nil {
+				return fmt.Errorf("replay %d-%d: %w", forkPoint, number-1, err)
+			}
+		}
+	}
+
+	return el.blockStore.SaveIndexedBlock(&model.IndexedBlock{
+		Number:     number,
+		Hash:       hash,
+		ParentHash: header.ParentHash.Hex(),
+	})
+}
+
+// parentMismatch 检查新区块是否接续在最近一个已索引区块之上
+func (el *EventListener) parentMismatch(number uint64, header *types.Header) (bool, error) {
+	prev, err := el.blockStore.GetLatestIndexedBlockBefore(number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if prev.Number == number-1 {
+		return prev.Hash != header.ParentHash.Hex(), nil
+	}
+
+	// 中间存在没有事件的区块，直接比对规范链上该高度的哈希
+	canonical, err := el.client.HeaderByNumber(el.ctx, new(big.Int).SetUint64(prev.Number))
+	if err != nil {
+		return false, fmt.Errorf("fetch header #%d: %w", prev.Number, err)
+	}
+	return canonical.Hash().Hex() != prev.Hash, nil
+}
+
+// findForkPoint 自高向低回溯已索引区块，返回第一个不在规范链上的区块高度
+func (el *EventListener) findForkPoint(number uint64) (uint64, error) {
+	cursor := number
+	for depth := 0; depth < el.maxReorgDepth; depth++ {
+		prev, err := el.blockStore.GetLatestIndexedBlockBefore(cursor)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return cursor, nil
+			}
+			return 0, err
+		}
+
+		canonical, err := el.client.HeaderByNumber(el.ctx, new(big.Int).SetUint64(prev.Number))
+		if err != nil {
+			return 0, fmt.Errorf("fetch header #%d: %w", prev.Number, err)
+		}
+		if canonical.Hash().Hex() == prev.Hash {
+			return prev.Number + 1, nil
+		}
+		cursor = prev.Number
+	}
+
+	log.Printf("⚠️  Reorg deeper than %d indexed blocks, rolling back from block %d", el.maxReorgDepth, cursor)
+	return cursor, nil
+}
+
+// replayRange 重新拉取规范链上 [from, to] 区间的日志并处理
+func (el *EventListener) replayRange(from, to uint64) error {
+	if from > to {
+		return nil
+	}
+
+	logs, err := el.client.FilterLogs(el.ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: el.contracts,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🔁 Replaying %d canonical events in blocks %d-%d", len(logs), from, to)
+	for _, vLog := range logs {
+		if err := el.handleLog(vLog); err != nil {
+			if retryBlock(err) {
+				return err
+			}
+			log.Printf("Error replaying event: %v", err)
+		}
+	}
+	return nil
+}
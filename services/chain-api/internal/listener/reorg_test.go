@@ -0,0 +1,300 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"desci-backend/internal/model"
+)
+
+// fakeChain 以内存中的规范链模拟RPC节点
+type fakeChain struct {
+	mu        sync.Mutex
+	canonical map[uint64]*types.Header
+	byHash    map[common.Hash]*types.Header
+	logs      []types.Log
+	maxRange  uint64 // 大于0时模拟提供方拒绝过大的查询范围
+	queries   [][2]uint64
+	head      uint64      // 大于0时作为 BlockNumber 返回值
+	subLogs   []types.Log // 订阅建立后推送的日志
+	calls     []*big.Int  // CallContract 收到的区块参数
+	callOut   []byte
+	callErr   error // 非nil时指定区块的调用失败（模拟非归档节点）
+}
+
+// fakeSub 模拟 ethereum.Subscription
+type fakeSub struct {
+	errCh chan error
+}
+
+func (s *fakeSub) Unsubscribe()      {}
+func (s *fakeSub) Err() <-chan error { return s.errCh }
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{canonical: map[uint64]*types.Header{}, byHash: map[common.Hash]*types.Header{}}
+}
+
+// addBlock 添加区块头；canonical 为 true 时同时作为该高度的规范区块
+func (c *fakeChain) addBlock(number uint64, parent common.Hash, fork byte, canonical bool) *types.Header {
+	h := &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: parent, Extra: []byte{fork}, Difficulty: big.NewInt(1)}
+	c.byHash[h.Hash()] = h
+	if canonical {
+		c.canonical[number] = h
+	}
+	return h
+}
+
+// mine 追加一个新区块及其日志（可与监听器并发调用）
+func (c *fakeChain) mine(head uint64, logs ...types.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = head
+	c.logs = append(c.logs, logs...)
+}
+
+func (c *fakeChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if q.ToBlock != nil {
+		c.queries = append(c.queries, [2]uint64{q.FromBlock.Uint64(), q.ToBlock.Uint64()})
+		if c.maxRange > 0 && q.ToBlock.Uint64()-q.FromBlock.Uint64()+1 > c.maxRange {
+			return nil, errors.New("query returned more than 10000 results")
+		}
+	}
+	var out []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || (q.ToBlock != nil && l.BlockNumber > q.ToBlock.Uint64()) {
+			continue
+		}
+		if len(q.Addresses) > 0 && !containsAddress(q.Addresses, l.Address) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func containsAddress(list []common.Address, addr common.Address) bool {
+	for _, a := range list {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if c.subLogs == nil {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	for _, l := range c.subLogs {
+		ch <- l
+	}
+	return &fakeSub{errCh: make(chan error)}, nil
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if h, ok := c.canonical[number.Uint64()]; ok {
+		return h, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.head > 0 {
+		return c.head, nil
+	}
+	var head uint64
+	for n := range c.canonical {
+		if n > head {
+			head = n
+		}
+	}
+	return head, nil
+}
+
+func (c *fakeChain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls = append(c.calls, blockNumber)
+	if blockNumber != nil && c.callErr != nil {
+		return nil, c.callErr
+	}
+	return c.callOut, nil
+}
+
+func (c *fakeChain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if h, ok := c.byHash[hash]; ok {
+		return h, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// memBlockStore 内存版 BlockStore
+type memBlockStore struct {
+	blocks      map[uint64]*model.IndexedBlock
+	rollbacks   []uint64
+	rollbackErr error // 非nil时回滚失败
+}
+
+func newMemBlockStore() *memBlockStore {
+	return &memBlockStore{blocks: map[uint64]*model.IndexedBlock{}}
+}
+
+func (s *memBlockStore) GetIndexedBlock(number uint64) (*model.IndexedBlock, error) {
+	if b, ok := s.blocks[number]; ok {
+		return b, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *memBlockStore) GetLatestIndexedBlockBefore(number uint64) (*model.IndexedBlock, error) {
+	var best *model.IndexedBlock
+	for n, b := range s.blocks {
+		if n < number && (best == nil || n > best.Number) {
+			best = b
+		}
+	}
+	if best == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return best, nil
+}
+
+func (s *memBlockStore) SaveIndexedBlock(block *model.IndexedBlock) error {
+	s.blocks[block.Number] = block
+	return nil
+}
+
+func (s *memBlockStore) RollbackFromBlock(number uint64) error {
+	if s.rollbackErr != nil {
+		return s.rollbackErr
+	}
+	s.rollbacks = append(s.rollbacks, number)
+	for n := range s.blocks {
+		if n >= number {
+			delete(s.blocks, n)
+		}
+	}
+	return nil
+}
+
+func newTestListener(chain *fakeChain, store *memBlockStore) *EventListener {
+	return &EventListener{
+		client:        chain,
+		ctx:           context.Background(),
+		blockStore:    store,
+		maxReorgDepth: defaultMaxReorgDepth,
+	}
+}
+
+func TestCheckReorg_RollsBackOrphanedBlocksAndReplays(t *testing.T) {
+	chain := newFakeChain()
+	store := newMemBlockStore()
+
+	b10 := chain.addBlock(10, common.Hash{}, 0, true)
+	orphan11 := chain.addBlock(11, b10.Hash(), 1, false)
+	c11 := chain.addBlock(11, b10.Hash(), 2, true)
+	c12 := chain.addBlock(12, c11.Hash(), 2, true)
+
+	store.blocks[10] = &model.IndexedBlock{Number: 10, Hash: b10.Hash().Hex()}
+	store.blocks[11] = &model.IndexedBlock{Number: 11, Hash: orphan11.Hash().Hex(), ParentHash: b10.Hash().Hex()}
+
+	chain.logs = []types.Log{{BlockNumber: 11, BlockHash: c11.Hash()}}
+
+	el := newTestListener(chain, store)
+	err := el.handleLog(types.Log{BlockNumber: 12, BlockHash: c12.Hash()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{11}, store.rollbacks)
+	assert.Equal(t, c11.Hash().Hex(), store.blocks[11].Hash)
+	assert.Equal(t, c12.Hash().Hex(), store.blocks[12].Hash)
+	assert.Equal(t, b10.Hash().Hex(), store.blocks[10].Hash)
+}
+
+func TestCheckReorg_NoReorgOnCanonicalExtension(t *testing.T) {
+	chain := newFakeChain()
+	store := newMemBlockStore()
+
+	b10 := chain.addBlock(10, common.Hash{}, 0, true)
+	b11 := chain.addBlock(11, b10.Hash(), 0, true)
+	chain.addBlock(12, b11.Hash(), 0, true)
+	b13 := chain.addBlock(13, chain.canonical[12].Hash(), 0, true)
+
+	store.blocks[10] = &model.IndexedBlock{Number: 10, Hash: b10.Hash().Hex()}
+
+	el := newTestListener(chain, store)
+	require.NoError(t, el.handleLog(types.Log{BlockNumber: 11, BlockHash: b11.Hash()}))
+	require.NoError(t, el.handleLog(types.Log{BlockNumber: 13, BlockHash: b13.Hash()}))
+
+	assert.Empty(t, store.rollbacks)
+	assert.Len(t, store.blocks, 3)
+}
+
+func TestHandleRemovedLog_RollsBackOnce(t *testing.T) {
+	chain := newFakeChain()
+	store := newMemBlockStore()
+
+	b10 := chain.addBlock(10, common.Hash{}, 0, true)
+	orphan11 := chain.addBlock(11, b10.Hash(), 1, false)
+	store.blocks[10] = &model.IndexedBlock{Number: 10, Hash: b10.Hash().Hex()}
+	store.blocks[11] = &model.IndexedBlock{Number: 11, Hash: orphan11.Hash().Hex()}
+
+	el := newTestListener(chain, store)
+	removed := types.Log{BlockNumber: 11, BlockHash: orphan11.Hash(), Removed: true}
+	require.NoError(t, el.handleLog(removed))
+	require.NoError(t, el.handleLog(removed))
+
+	assert.Equal(t, []uint64{11}, store.rollbacks)
+	_, ok := store.blocks[11]
+	assert.False(t, ok)
+}
+
+func TestHandleLog_FailedRollbackSkipsDispatch(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	chain := newFakeChain()
+	store := newMemBlockStore()
+	store.rollbackErr = errors.New("database is locked")
+
+	b10 := chain.addBlock(10, common.Hash{}, 0, true)
+	orphan11 := chain.addBlock(11, b10.Hash(), 1, false)
+	c11 := chain.addBlock(11, b10.Hash(), 2, true)
+	c12 := chain.addBlock(12, c11.Hash(), 2, true)
+	store.blocks[10] = &model.IndexedBlock{Number: 10, Hash: b10.Hash().Hex()}
+	store.blocks[11] = &model.IndexedBlock{Number: 11, Hash: orphan11.Hash().Hex(), ParentHash: b10.Hash().Hex()}
+	chain.logs = []types.Log{
+		{Address: addr, BlockNumber: 11, BlockHash: c11.Hash()},
+		{Address: addr, BlockNumber: 12, BlockHash: c12.Hash()},
+	}
+	cursors := &memCursorStore{cursors: map[string]uint64{addr.Hex(): 10}}
+
+	el := newTestListener(chain, store)
+	el.contracts = []common.Address{addr}
+	el.cursorStore = cursors
+	el.batchSize = 100
+	blocks := recordHandler(el)
+
+	err := el.backfill(12)
+	require.ErrorIs(t, err, errReorgCheck)
+	// 回滚失败后不分发该日志，游标停在失败区块之前
+	assert.Empty(t, *blocks)
+	assert.Equal(t, uint64(10), cursors.cursors[addr.Hex()])
+	assert.Equal(t, orphan11.Hash().Hex(), store.blocks[11].Hash)
+
+	store.rollbackErr = nil
+	require.NoError(t, el.backfill(12))
+	assert.Equal(t, []uint64{11, 12}, *blocks)
+	assert.Equal(t, uint64(12), cursors.cursors[addr.Hex()])
+	assert.Equal(t, c11.Hash().Hex(), store.blocks[11].Hash)
+}
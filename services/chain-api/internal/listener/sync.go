@@ -0,0 +1,223 @@
+package listener
+
+import (
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 索引器同步阶段
+const (
+	PhaseIdle     = "idle"     // 尚未启动
+	PhaseBackfill = "backfill" // 回填到启动时的链头快照
+	PhaseCatchUp  = "catchup"  // 追赶回填期间新产生的区块
+	PhaseLive     = "live"     // 通过订阅或轮询接收新区块事件
+)
+
+// maxLogIndex 表示某区块的全部日志均已分发
+const maxLogIndex = ^uint(0)
+
+// logPosition 最后一条已分发日志在链上的位置
+type logPosition struct {
+	block uint64
+	index uint
+	valid bool
+}
+
+// before 判断该位置是否严格早于给定日志
+func (p logPosition) before(vLog types.Log) bool {
+	if !p.valid {
+		return true
+	}
+	if p.block != vLog.BlockNumber {
+		return p.block < vLog.BlockNumber
+	}
+	return p.index < vLog.Index
+}
+
+// Phase 返回索引器当前的同步阶段
+func (el *EventListener) Phase() string {
+	el.phaseMu.RLock()
+	defer el.phaseMu.RUnlock()
+	if el.phase == "" {
+		return PhaseIdle
+	}
+	return el.phase
+}
+
+func (el *EventListener) setPhase(phase string) {
+	el.phaseMu.Lock()
+	changed := el.phase != phase
+	el.phase = phase
+	el.phaseMu.Unlock()
+	if changed {
+		log.Printf("🔄 Indexer phase: %s", phase)
+	}
+}
+
+// advancePosition 记录已分发的日志位置
+func (el *EventListener) advancePosition(vLog types.Log) {
+	if el.position.before(vLog) {
+		el.position = logPosition{block: vLog.BlockNumber, index: vLog.Index, valid: true}
+	}
+}
+
+// markSynced 标记 block 及之前的全部日志已分发
+func (el *EventListener) markSynced(block uint64) {
+	if !el.position.valid || el.position.block < block {
+		el.position = logPosition{block: block, index: maxLogIndex, valid: true}
+	}
+}
+
+// rewindTo 链重组回滚后将位置退回到 number 之前
+func (el *EventListener) rewindTo(number uint64) {
+	if !el.position.valid || el.position.block < number {
+		return
+	}
+	if number == 0 {
+		el.position = logPosition{}
+		return
+	}
+	el.position = logPosition{block: number - 1, index: maxLogIndex, valid: true}
+}
+
+// run 同步状态机：backfill 到启动时的链头快照，catchup 追平最新区块，
+// 然后切换到 live（订阅或轮询）；中断时回到 catchup 补齐缺口后重新进入 live
+func (el *EventListener) run() {
+	el.setPhase(PhaseBackfill)
+	head, ok := el.waitForHead()
+	if !ok {
+		return
+	}
+	for {
+		err := el.backfill(head)
+		if err == nil {
+			break
+		}
+		log.Printf("Error backfilling historical logs: %v", err)
+		if !el.sleep(3 * time.Second) {
+			return
+		}
+	}
+	log.Printf("Historical backfill complete up to block %d", head)
+
+	for el.ctx.Err() == nil {
+		el.setPhase(PhaseCatchUp)
+		if err := el.catchUp(); err != nil {
+			log.Printf("Error catching up: %v", err)
+			el.sleep(3 * time.Second)
+			continue
+		}
+		if err := el.followLive(); err != nil {
+			log.Printf("Live %s error: %v", el.Mode(), err)
+			el.sleep(3 * time.Second)
+		}
+	}
+}
+
+// waitForHead 获取链头高度，失败时重试直到成功或ctx取消
+func (el *EventListener) waitForHead() (uint64, bool) {
+	for {
+		head, err := el.client.BlockNumber(el.ctx)
+		if err == nil {
+			return head, true
+		}
+		log.Printf("Error fetching latest block: %v", err)
+		if !el.sleep(3 * time.Second) {
+			return 0, false
+		}
+	}
+}
+
+// catchUp 反复回填到最新链头，直到已分发位置追平链头
+func (el *EventListener) catchUp() error {
+	for {
+		head, err := el.client.BlockNumber(el.ctx)
+		if err != nil {
+			return err
+		}
+		if el.position.valid && el.position.block >= head {
+			return nil
+		}
+		if err := el.backfill(head); err != nil {
+			return err
+		}
+	}
+}
+
+// followSubscription 建立订阅后再补齐一次订阅建立前的空档，之后按顺序分发实时日志
+func (el *EventListener) followSubscription() error {
+	logsCh := make(chan types.Log, 1024)
+	sub, err := el.client.SubscribeFilterLogs(el.ctx, ethereum.FilterQuery{Addresses: el.contracts}, logsCh)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// 订阅期间产生、已被补齐的日志会在 deliverLive 中按位置去重
+	if err := el.catchUp(); err != nil {
+		return err
+	}
+	el.setPhase(PhaseLive)
+	log.Println("Subscribed to new events...")
+
+	for {
+		select {
+		case err := <-sub.Err():
+			return err
+		case vLog := <-logsCh:
+			if err := el.deliverLive(vLog); err != nil {
+				return err
+			}
+		case <-el.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// deliverLive 分发一条实时日志：丢弃已分发过的日志，并在进入新区块时推进游标；
+// 重组检测失败时返回错误，由状态机从游标处重新追赶该区块
+func (el *EventListener) deliverLive(vLog types.Log) error {
+	if !vLog.Removed {
+		if !el.position.before(vLog) {
+			return nil
+		}
+		// 订阅按顺序推送，进入新区块意味着之前的区块已全部分发
+		if el.position.valid && vLog.BlockNumber > el.position.block {
+			el.saveCursors(vLog.BlockNumber - 1)
+		}
+	}
+
+	log.Printf("New event received: block %d, tx %s", vLog.BlockNumber, vLog.TxHash.Hex())
+	if err := el.handleLog(vLog); err != nil {
+		if retryBlock(err) {
+			return err
+		}
+		log.Printf("Error processing event: %v", err)
+	}
+	return nil
+}
+
+// saveCursors 将全部合约的同步游标推进到 block
+func (el *EventListener) saveCursors(block uint64) {
+	if el.cursorStore == nil {
+		return
+	}
+	for _, addr := range el.contracts {
+		if err := el.cursorStore.SaveSyncCursor(addr.Hex(), block); err != nil {
+			log.Printf("⚠️  Failed to save sync cursor %s: %v", addr.Hex(), err)
+		}
+	}
+}
+
+// sleep 等待 d 或ctx取消，返回是否应继续运行
+func (el *EventListener) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-el.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package listener
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"desci-backend/internal/model"
+)
+
+func TestRun_OrderedHandoffFromBackfillToLive(t *testing.T) {
+	addr := common.HexToAddress("0x1")
+	chain := newFakeChain()
+	chain.head = 20
+	chain.logs = []types.Log{
+		{Address: addr, BlockNumber: 5},
+		{Address: addr, BlockNumber: 15, Index: 0},
+		{Address: addr, BlockNumber: 15, Index: 1},
+	}
+	// 订阅推送的日志与回填区间重叠，重叠部分必须去重
+	chain.subLogs = []types.Log{
+		{Address: addr, BlockNumber: 15, Index: 1},
+		{Address: addr, BlockNumber: 21, Index: 0},
+		{Address: addr, BlockNumber: 22, Index: 3},
+	}
+	cursors := &memCursorStore{cursors: map[string]uint64{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	el := &EventListener{client: chain, ctx: ctx, contracts: []common.Address{addr}, cursorStore: cursors, batchSize: 8}
+
+	var mu sync.Mutex
+	var got [][2]uint64
+	done := make(chan struct{})
+	el.SetEventHandler(func(event *model.ParsedEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, [2]uint64{event.Block, uint64(event.LogIndex)})
+		if event.Block == 22 {
+			close(done)
+		}
+		return nil
+	})
+
+	assert.Equal(t, PhaseIdle, el.Phase())
+	go el.run()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for live events")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, [][2]uint64{{5, 0}, {15, 0}, {15, 1}, {21, 0}, {22, 3}}, got)
+	assert.Equal(t, PhaseLive, el.Phase())
+	// 进入区块22时，21及之前的区块已全部分发
+	require.Contains(t, cursors.cursors, addr.Hex())
+	assert.Equal(t, uint64(21), cursors.cursors[addr.Hex()])
+}
+
+func TestLogPosition_Before(t *testing.T) {
+	var p logPosition
+	assert.True(t, p.before(types.Log{BlockNumber: 0}))
+
+	p = logPosition{block: 10, index: 2, valid: true}
+	assert.True(t, p.before(types.Log{BlockNumber: 10, Index: 3}))
+	assert.False(t, p.before(types.Log{BlockNumber: 10, Index: 2}))
+	assert.False(t, p.before(types.Log{BlockNumber: 9, Index: 7}))
+	assert.True(t, p.before(types.Log{BlockNumber: 11}))
+}
@@ -1,9 +1,12 @@
 package model
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,11 +16,148 @@ type ResearchData struct {
 	TokenID      string      `gorm:"uniqueIndex" json:"token_id"`
 	Title        string      `json:"title"`
 	Authors      StringArray `gorm:"type:text" json:"authors"`
+	AuthorsText  string      `gorm:"type:text" json:"-"` // 署名作者的显示名称和地址，供全文检索
 	ContentHash  string      `json:"content_hash"`
 	MetadataHash string      `json:"metadata_hash"`
-	BlockNumber  uint64      `json:"block_number"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
+	BlockNumber  uint64      `gorm:"index" json:"block_number"`
+	Status       string      `gorm:"size:32;default:confirmed" json:"status"`
+	Owner        string      `gorm:"index;size:64" json:"owner"` // 当前持有人（由 Transfer 事件维护）
+	PubType      uint8       `gorm:"default:0" json:"pub_type"`
+	PubTypeName  string      `gorm:"size:32" json:"pub_type_name"`
+	PublishedAt  *time.Time  `json:"published_at,omitempty"` // 合约记录的发布时间
+	BlockTime    *time.Time  `json:"block_time,omitempty"`   // 铸造所在区块的时间
+
+	// 以下统计由引用、评审、影响力和收益事件汇总而来
+	CitationCount   int64     `gorm:"default:0" json:"citation_count"`
+	ReviewCount     int64     `gorm:"default:0" json:"review_count"`
+	AverageScore    float64   `gorm:"default:0" json:"average_score"`
+	ImpactLevel     uint8     `gorm:"default:0" json:"impact_level"`
+	ImpactLevelName string    `gorm:"size:32;default:low" json:"impact_level_name"`
+	TotalRevenue    string    `gorm:"size:78;default:0" json:"total_revenue"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ResearchAuthor 研究成果的署名作者（地址统一小写），随 ResearchData 的插入和更新维护
+type ResearchAuthor struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	TokenID string `json:"token_id" gorm:"size:255;uniqueIndex:idx_research_authors_token_position,priority:1;index:idx_research_authors_author,priority:2"`
+	Author  string `json:"author" gorm:"size:255;index:idx_research_authors_author,priority:1"`
+	// Position 署名顺序，0 为第一作者
+	Position    int    `json:"position" gorm:"uniqueIndex:idx_research_authors_token_position,priority:2"`
+	BlockNumber uint64 `json:"block_number" gorm:"index"`
+}
+
+// AuthoredResearch 作者参与的研究成果及其在该成果中的署名顺序
+type AuthoredResearch struct {
+	ResearchData
+	AuthorPosition int `json:"author_position"`
+}
+
+// ResearchCitation 研究成果之间的引用关系（FromTokenID 引用 ToTokenID）
+type ResearchCitation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	FromTokenID string    `json:"from_token_id" gorm:"index;size:255"`
+	ToTokenID   string    `json:"to_token_id" gorm:"index;size:255"`
+	Citer       string    `json:"citer" gorm:"size:64"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResearchReview 同行评审记录；匿名评审不保存评审人地址
+type ResearchReview struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TokenID     string    `json:"token_id" gorm:"index;size:255"`
+	Reviewer    string    `json:"reviewer,omitempty" gorm:"size:64"`
+	Score       uint8     `json:"score"`
+	IsAnonymous bool      `json:"is_anonymous"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResearchImpactChange 影响力等级变更记录，最新一条即当前等级
+type ResearchImpactChange struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TokenID     string    `json:"token_id" gorm:"index;size:255"`
+	OldLevel    uint8     `json:"old_level"`
+	NewLevel    uint8     `json:"new_level"`
+	LevelName   string    `json:"level_name" gorm:"size:32"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResearchRevenue 研究成果收益分配记录（Shares 与 Authors 一一对应）
+type ResearchRevenue struct {
+	ID          uint        `json:"id" gorm:"primaryKey"`
+	TokenID     string      `json:"token_id" gorm:"index;size:255"`
+	Authors     StringArray `json:"authors" gorm:"type:text"`
+	Shares      StringArray `json:"shares" gorm:"type:text"`
+	TotalAmount string      `json:"total_amount" gorm:"size:78"`
+	BlockNumber uint64      `json:"block_number" gorm:"index"`
+	TxHash      string      `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint        `json:"log_index"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// ResearchMetadataUpdate ERC-4906 元数据更新记录（单个token时 From == To）
+type ResearchMetadataUpdate struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	FromTokenID string    `json:"from_token_id" gorm:"size:78"`
+	ToTokenID   string    `json:"to_token_id" gorm:"size:78"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResearchEventPayload ResearchNFT 事件载荷（与ABI参数同名）
+type ResearchEventPayload struct {
+	TokenID           string   `json:"tokenId"`
+	FromTokenID       string   `json:"fromTokenId"`
+	ToTokenID         string   `json:"toTokenId"`
+	Citer             string   `json:"citer"`
+	Reviewer          string   `json:"reviewer"`
+	Score             uint8    `json:"score"`
+	IsAnonymous       bool     `json:"isAnonymous"`
+	OldLevel          uint8    `json:"oldLevel"`
+	NewLevel          uint8    `json:"newLevel"`
+	Authors           []string `json:"authors"`
+	Shares            []string `json:"shares"`
+	TotalAmount       string   `json:"totalAmount"`
+	MetadataTokenID   string   `json:"_tokenId"`
+	MetadataFromToken string   `json:"_fromTokenId"`
+	MetadataToToken   string   `json:"_toTokenId"`
+}
+
+// PublicationTypeName 返回 ResearchNFT.PublicationType 枚举对应的名称
+func PublicationTypeName(pubType uint8) string {
+	names := []string{"paper", "patent", "dataset", "software", "experiment", "review", "preprint", "thesis"}
+	if int(pubType) < len(names) {
+		return names[pubType]
+	}
+	return "unknown"
+}
+
+// ImpactLevelName 返回 ResearchNFT.ImpactLevel 枚举对应的名称
+func ImpactLevelName(level uint8) string {
+	switch level {
+	case 0:
+		return "low"
+	case 1:
+		return "medium"
+	case 2:
+		return "high"
+	case 3:
+		return "breakthrough"
+	default:
+		return "unknown"
+	}
 }
 
 // NodeJSNFT Node.js数据库中的NFT记录（用于数据对比）
@@ -63,10 +203,747 @@ type DatasetRecord struct {
 	Description string    `json:"description"`
 	Owner       string    `json:"owner" gorm:"index;size:255"`
 	DataHash    string    `json:"data_hash"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	Status      string    `json:"status" gorm:"size:32;default:confirmed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// 全文检索结果的文档类型
+const (
+	SearchTypeResearch = "research" // ResearchData：标题与作者
+	SearchTypeDataset  = "dataset"  // DatasetRecord：标题与描述
+)
+
+// SearchResult 全文检索命中的研究成果或数据集；高亮片段以 <mark> 标记命中词
+type SearchResult struct {
+	DocType        string  `json:"type"`
+	DocID          string  `json:"id"` // 研究成果的 token_id 或数据集的 dataset_id
+	Title          string  `json:"title"`
+	Owner          string  `json:"owner"`
+	BlockNumber    uint64  `json:"block_number"`
+	Score          float64 `json:"score"` // 相关度，越大越相关；不同数据库的取值范围不同
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// DatasetAccess 数据集访问（购买）记录
+type DatasetAccess struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	DatasetID   string     `json:"dataset_id" gorm:"index;size:255"`
+	User        string     `json:"user" gorm:"index;size:64"`
+	PricePaid   string     `json:"price_paid" gorm:"size:78"`
+	AccessedAt  *time.Time `json:"accessed_at"`
+	BlockNumber uint64     `json:"block_number" gorm:"index"`
+	TxHash      string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint       `json:"log_index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// DatasetCitation 数据集被研究成果引用的记录
+type DatasetCitation struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	DatasetID       string     `json:"dataset_id" gorm:"index;size:255"`
+	Researcher      string     `json:"researcher" gorm:"index;size:64"`
+	PublicationHash string     `json:"publication_hash"`
+	CitedAt         *time.Time `json:"cited_at"`
+	BlockNumber     uint64     `json:"block_number" gorm:"index"`
+	TxHash          string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex        uint       `json:"log_index"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// DatasetQualityChange 数据集质量等级变更记录，最新一条即当前等级
+type DatasetQualityChange struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	DatasetID   string    `json:"dataset_id" gorm:"index;size:255"`
+	OldLevel    uint8     `json:"old_level"`
+	NewLevel    uint8     `json:"new_level"`
+	LevelName   string    `json:"level_name" gorm:"size:32"`
+	Verifier    string    `json:"verifier" gorm:"size:64"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DatasetRevenue 数据集收益分配记录
+type DatasetRevenue struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	DatasetID     string    `json:"dataset_id" gorm:"index;size:255"`
+	Owner         string    `json:"owner" gorm:"index;size:64"`
+	OwnerShare    string    `json:"owner_share" gorm:"size:78"`
+	PlatformShare string    `json:"platform_share" gorm:"size:78"`
+	BlockNumber   uint64    `json:"block_number" gorm:"index"`
+	TxHash        string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex      uint      `json:"log_index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DatasetEventPayload DatasetManager 事件载荷（与ABI参数同名）
+type DatasetEventPayload struct {
+	DatasetID       string `json:"datasetId"`
+	User            string `json:"user"`
+	PricePaid       string `json:"pricePaid"`
+	Researcher      string `json:"researcher"`
+	PublicationHash string `json:"publicationHash"`
+	OldLevel        uint8  `json:"oldLevel"`
+	NewLevel        uint8  `json:"newLevel"`
+	Verifier        string `json:"verifier"`
+	Owner           string `json:"owner"`
+	OwnerShare      string `json:"ownerShare"`
+	PlatformShare   string `json:"platformShare"`
+	Timestamp       string `json:"timestamp"`
+}
+
+// DatasetRevenueSummary 数据集收益分配汇总（金额为 wei 十进制字符串）
+type DatasetRevenueSummary struct {
+	DatasetID          string           `json:"dataset_id"`
+	TotalOwnerShare    string           `json:"total_owner_share"`
+	TotalPlatformShare string           `json:"total_platform_share"`
+	TotalRevenue       string           `json:"total_revenue"`
+	Distributions      int              `json:"distributions"`
+	History            []DatasetRevenue `json:"history"`
+}
+
+// QualityLevelName 返回 DatasetManager.QualityLevel 枚举对应的名称
+func QualityLevelName(level uint8) string {
+	switch level {
+	case 0:
+		return "unverified"
+	case 1:
+		return "basic"
+	case 2:
+		return "standard"
+	case 3:
+		return "premium"
+	case 4:
+		return "gold"
+	default:
+		return "unknown"
+	}
+}
+
+// NFT 集合名称
+const (
+	CollectionResearch = "research" // ResearchNFT
+	CollectionDataset  = "dataset"  // DatasetManager
+)
+
+// ZeroAddress 铸造和销毁时 Transfer 使用的零地址
+const ZeroAddress = "0x0000000000000000000000000000000000000000"
+
+// NFTTransfer ERC-721 转移记录（token 的完整流转链）
+type NFTTransfer struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Collection  string    `json:"collection" gorm:"index:idx_nft_transfer_token;size:32"`
+	TokenID     string    `json:"token_id" gorm:"index:idx_nft_transfer_token;size:78"`
+	FromAddress string    `json:"from" gorm:"index;size:64"`
+	ToAddress   string    `json:"to" gorm:"index;size:64"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
 	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NFTOwner token 的当前持有人（由最新一条转移记录决定）
+type NFTOwner struct {
+	Collection  string    `json:"collection" gorm:"primaryKey;size:32"`
+	TokenID     string    `json:"token_id" gorm:"primaryKey;size:78"`
+	Owner       string    `json:"owner" gorm:"index;size:64"`
+	Burned      bool      `json:"burned"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TransferEventPayload ERC-721 Transfer 事件载荷
+type TransferEventPayload struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	TokenID string `json:"tokenId"`
+}
+
+// TokenTransfer SciToken ERC-20 转账记录（from 为零地址表示铸造，to 为零地址表示销毁）
+type TokenTransfer struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	FromAddress   string     `json:"from" gorm:"index;size:64"`
+	ToAddress     string     `json:"to" gorm:"index;size:64"`
+	Value         string     `json:"value" gorm:"size:78"`
+	TransferredAt *time.Time `json:"transferred_at,omitempty"`
+	BlockNumber   uint64     `json:"block_number" gorm:"index"`
+	TxHash        string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex      uint       `json:"log_index"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TokenBalance SciToken 持有人余额（由转账记录累加，wei 十进制字符串）
+type TokenBalance struct {
+	Holder      string    `json:"holder" gorm:"primaryKey;size:64"`
+	Balance     string    `json:"balance" gorm:"size:78"`
+	BalanceKey  string    `json:"-" gorm:"size:78;index"` // 左补零的余额，按字符串排序即按数值排序
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// TokenApproval SciToken 授权记录，同一 owner/spender 以最新一条为准
+type TokenApproval struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Owner       string    `json:"owner" gorm:"index;size:64"`
+	Spender     string    `json:"spender" gorm:"size:64"`
+	Value       string    `json:"value" gorm:"size:78"`
+	BlockNumber uint64    `json:"block_number" gorm:"index"`
+	TxHash      string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint      `json:"log_index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TokenSupply SciToken 供应量汇总（由铸造和销毁记录计算）
+type TokenSupply struct {
+	TotalSupply string `json:"total_supply"`
+	Minted      string `json:"minted"`
+	Burned      string `json:"burned"`
+	Holders     int64  `json:"holders"`
+}
+
+// TokenEventPayload SciToken Transfer/Approval 事件载荷
+type TokenEventPayload struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Value     string `json:"value"`
+	BlockTime uint64 `json:"blockTimestamp"`
+}
+
+// ZKProof ZKProof 合约提交的零知识证明及其验证状态
+type ZKProof struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ProofID       string     `json:"proof_id" gorm:"uniqueIndex;size:78"`
+	Submitter     string     `json:"submitter" gorm:"index;size:64"`
+	ProofType     string     `json:"proof_type" gorm:"index;size:128"`
+	MetadataHash  string     `json:"metadata_hash"`
+	Status        string     `json:"status" gorm:"index;size:32;default:submitted"`
+	Verifier      string     `json:"verifier,omitempty" gorm:"size:64"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	BlockNumber   uint64     `json:"block_number" gorm:"index"` // 提交所在区块
+	VerifiedBlock uint64     `json:"verified_block,omitempty"`
+	TxHash        string     `json:"tx_hash" gorm:"size:66"`
+
+	// 链下 Groth16 校验结果及与 ZKPVerifier.verificationResults 的交叉核对
+	ProofHash     string     `json:"proof_hash,omitempty" gorm:"index;size:66"`
+	CheckStatus   string     `json:"check_status,omitempty" gorm:"index;size:32"`
+	CheckReason   string     `json:"check_reason,omitempty"`
+	OnchainResult string     `json:"onchain_result,omitempty" gorm:"size:32"`
+	CheckedAt     *time.Time `json:"checked_at,omitempty"`
+	Trusted       bool       `json:"trusted"` // 链下校验通过且链上记录一致
+	// 自动核对失败或链上仍无记录的次数及下次核对时间，按退避策略重试，达到上限后不再自动核对
+	CheckAttempts int        `json:"check_attempts" gorm:"default:0"`
+	NextCheckAt   *time.Time `json:"next_check_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (ZKProof) TableName() string {
+	return "proofs"
+}
+
+// 证明状态
+const (
+	ProofStatusSubmitted = "submitted"
+	ProofStatusVerified  = "verified"
+	ProofStatusRejected  = "rejected"
+)
+
+// 链下校验及链上记录的结果
+const (
+	ProofCheckValid   = "valid"
+	ProofCheckInvalid = "invalid"
+	ProofCheckUnknown = "unknown" // ZKPVerifier 尚未记录该证明
+)
+
+// 证明验证结果的来源合约
+const (
+	ProofSourceZKProof     = "ZKProof"
+	ProofSourceZKPVerifier = "ZKPVerifier"
+)
+
+// ProofVerification 证明验证记录：ZKProof 按 proofId 记录，ZKPVerifier 按 proofHash 记录
+type ProofVerification struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Source      string     `json:"source" gorm:"size:32"`
+	ProofID     string     `json:"proof_id,omitempty" gorm:"index;size:78"`
+	ProofHash   string     `json:"proof_hash,omitempty" gorm:"index;size:66"`
+	IsValid     bool       `json:"is_valid"`
+	Verifier    string     `json:"verifier,omitempty" gorm:"size:64"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	BlockNumber uint64     `json:"block_number" gorm:"index"`
+	TxHash      string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint       `json:"log_index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ProofType 证明类型目录（ZKProof 的启用状态与 ZKPVerifier 的注册信息合并）
+type ProofType struct {
+	Name             string    `json:"name" gorm:"primaryKey;size:128"`
+	VerifierContract string    `json:"verifier_contract,omitempty" gorm:"size:64"`
+	IsActive         bool      `json:"is_active"`
+	Registered       bool      `json:"registered"` // 已在 ZKPVerifier 注册验证参数
+	Registrant       string    `json:"registrant,omitempty" gorm:"size:64"`
+	BlockNumber      uint64    `json:"block_number" gorm:"index"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ProofTypeEvents 参与构建证明类型目录的事件
+var ProofTypeEvents = []string{
+	"ProofTypeAdded",
+	"ProofTypeUpdated",
+	"ProofTypeRegistered",
+}
+
+// ProofEventPayload 证明相关事件载荷（与ABI参数同名）
+type ProofEventPayload struct {
+	ProofID          string `json:"proofId"`
+	ProofHash        string `json:"proofHash"`
+	Submitter        string `json:"submitter"`
+	ProofType        string `json:"proofType"`
+	MetadataHash     string `json:"metadataHash"`
+	IsValid          bool   `json:"isValid"`
+	Verifier         string `json:"verifier"`
+	VerifierContract string `json:"verifierContract"`
+	IsActive         bool   `json:"isActive"`
+	Registrant       string `json:"registrant"`
+	Timestamp        string `json:"timestamp"`
+	BlockTime        uint64 `json:"blockTimestamp"`
+}
+
+// Apply 将一条证明类型事件应用到目录上
+func (t *ProofType) Apply(eventName string, e *ProofEventPayload, block uint64) {
+	switch eventName {
+	case "ProofTypeAdded":
+		t.VerifierContract = strings.ToLower(e.VerifierContract)
+		t.IsActive = true
+	case "ProofTypeUpdated":
+		t.IsActive = e.IsActive
+	case "ProofTypeRegistered":
+		t.Registered = true
+		t.Registrant = strings.ToLower(e.Registrant)
+	}
+	t.BlockNumber = block
+}
+
+// Constraint ConstraintManager 约束目录
+type Constraint struct {
+	ConstraintID     string      `json:"constraint_id" gorm:"primaryKey;size:66"`
+	Name             string      `json:"name"`
+	Description      string      `json:"description"`
+	Category         uint8       `json:"category"`
+	CategoryName     string      `json:"category_name" gorm:"index;size:32"`
+	Operator         uint8       `json:"operator"`
+	OperatorName     string      `json:"operator_name" gorm:"size:32"`
+	Thresholds       StringArray `json:"thresholds" gorm:"type:text"`
+	Priority         string      `json:"priority" gorm:"size:78"`
+	Weight           string      `json:"weight" gorm:"size:78"`
+	ApplicableFields StringArray `json:"applicable_fields" gorm:"type:text"`
+	IsGlobal         bool        `json:"is_global"`
+	IsActive         bool        `json:"is_active"`
+	Creator          string      `json:"creator" gorm:"size:64"`
+	BlockNumber      uint64      `json:"block_number" gorm:"index"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// ConstraintGroup 约束组，记录创建时包含的约束
+type ConstraintGroup struct {
+	GroupID         string      `json:"group_id" gorm:"primaryKey;size:66"`
+	Name            string      `json:"name"`
+	Description     string      `json:"description"`
+	ConstraintIDs   StringArray `json:"constraint_ids" gorm:"type:text"`
+	MinSatisfaction string      `json:"min_satisfaction" gorm:"size:78"`
+	TotalWeight     string      `json:"total_weight" gorm:"size:78"`
+	IsActive        bool        `json:"is_active"`
+	Creator         string      `json:"creator" gorm:"size:64"`
+	BlockNumber     uint64      `json:"block_number" gorm:"index"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// ValidationRule 验证规则，由若干约束组组成，平均得分达到 MinScore 即通过
+type ValidationRule struct {
+	RuleID      string      `json:"rule_id" gorm:"primaryKey;size:66"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	GroupIDs    StringArray `json:"group_ids" gorm:"type:text"`
+	MinScore    string      `json:"min_score" gorm:"size:78"`
+	IsActive    bool        `json:"is_active"`
+	Creator     string      `json:"creator" gorm:"size:64"`
+	BlockNumber uint64      `json:"block_number" gorm:"index"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// ConstraintEvaluation 约束评估结果的时间序列
+type ConstraintEvaluation struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ConstraintID string     `json:"constraint_id" gorm:"index;size:66"`
+	Result       bool       `json:"result"`
+	Score        string     `json:"score" gorm:"size:78"`
+	EvaluatedAt  *time.Time `json:"evaluated_at,omitempty"`
+	BlockNumber  uint64     `json:"block_number" gorm:"index"`
+	TxHash       string     `json:"tx_hash" gorm:"index;size:66"`
+	LogIndex     uint       `json:"log_index"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ConstraintEvents 参与构建约束目录的事件
+var ConstraintEvents = []string{
+	"ConstraintCreated",
+	"ConstraintUpdated",
+}
+
+// DatasetEvents 以数据集ID为实体的事件，用于按交易关联约束评估
+var DatasetEvents = []string{
+	"DatasetCreated",
+	"DatasetAccessed",
+	"DatasetCited",
+	"QualityUpdated",
+	"DatasetRevenueDistributed",
+	"DatasetTransfer",
+}
+
+// ConstraintEventPayload ConstraintManager 事件载荷（事件参数加上合约 getter 返回的详情）
+type ConstraintEventPayload struct {
+	ConstraintID     string   `json:"constraintId"`
+	GroupID          string   `json:"groupId"`
+	RuleID           string   `json:"ruleId"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Category         uint8    `json:"category"`
+	Operator         uint8    `json:"operator"`
+	Thresholds       []string `json:"thresholds"`
+	Priority         string   `json:"priority"`
+	Weight           string   `json:"weight"`
+	ApplicableFields []string `json:"applicableFields"`
+	IsGlobal         bool     `json:"isGlobal"`
+	IsActive         *bool    `json:"isActive"`
+	Creator          string   `json:"creator"`
+	ConstraintIDs    []string `json:"constraintIds"`
+	MinSatisfaction  string   `json:"minSatisfaction"`
+	TotalWeight      string   `json:"totalWeight"`
+	GroupIDs         []string `json:"groupIds"`
+	MinScore         string   `json:"minScore"`
+	Result           bool     `json:"result"`
+	Score            string   `json:"score"`
+	BlockTime        uint64   `json:"blockTimestamp"`
+}
+
+// Apply 将一条约束事件应用到目录上；缺少详情时保留已有值
+func (c *Constraint) Apply(eventName string, e *ConstraintEventPayload, block uint64) {
+	if e.Name != "" {
+		c.Name = e.Name
+	}
+	if e.Description != "" {
+		c.Description = e.Description
+	}
+	if eventName == "ConstraintCreated" {
+		c.Category = e.Category
+		c.CategoryName = ConstraintCategoryName(e.Category)
+		c.Creator = strings.ToLower(e.Creator)
+		c.IsActive = true
+	}
+	if e.Thresholds != nil {
+		c.Operator = e.Operator
+		c.OperatorName = ConstraintOperatorName(e.Operator)
+		c.Thresholds = e.Thresholds
+		c.Priority = e.Priority
+		c.Weight = e.Weight
+		c.ApplicableFields = e.ApplicableFields
+		c.IsGlobal = e.IsGlobal
+	}
+	if e.IsActive != nil {
+		c.IsActive = *e.IsActive
+	}
+	c.BlockNumber = block
+}
+
+// ConstraintCategoryName 返回 ConstraintManager.ConstraintCategory 枚举对应的名称
+func ConstraintCategoryName(category uint8) string {
+	names := []string{"statistical", "format", "range", "relationship", "quality", "custom"}
+	if int(category) < len(names) {
+		return names[category]
+	}
+	return "unknown"
+}
+
+// ConstraintOperatorName 返回 ConstraintManager.ConstraintOperator 枚举对应的名称
+func ConstraintOperatorName(operator uint8) string {
+	names := []string{"equal", "not_equal", "greater_than", "greater_equal", "less_than",
+		"less_equal", "between", "not_between", "contains", "not_contains"}
+	if int(operator) < len(names) {
+		return names[operator]
+	}
+	return "unknown"
+}
+
+// 数据质量记录的来源合约
+const (
+	DataQualitySourceExtractor = "feature_extractor"
+	DataQualitySourceVerifier  = "data_verifier"
+)
+
+// DataQuality 数据质量投影：DataFeatureExtractor 的特征记录或 ResearchDataVerifier 的数据记录的最新指标，
+// 通过 DataHash 或同一交易中的数据集事件与 DatasetRecord 关联
+type DataQuality struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	Source             string    `json:"source" gorm:"uniqueIndex:idx_data_quality_subject;size:32"`
+	SubjectID          string    `json:"subject_id" gorm:"uniqueIndex:idx_data_quality_subject;size:66"`
+	DataHash           string    `json:"data_hash" gorm:"index"`
+	FeatureHash        string    `json:"feature_hash"`
+	DataType           string    `json:"data_type" gorm:"size:64"`
+	DataCount          string    `json:"data_count" gorm:"size:78"`
+	Submitter          string    `json:"submitter" gorm:"index;size:64"`
+	Mean               string    `json:"mean" gorm:"size:78"`
+	StandardDeviation  string    `json:"standard_deviation" gorm:"size:78"`
+	MinValue           string    `json:"min_value" gorm:"size:78"`
+	MaxValue           string    `json:"max_value" gorm:"size:78"`
+	Score              string    `json:"score" gorm:"size:78"`
+	IsValid            *bool     `json:"is_valid"`
+	VerificationStatus string    `json:"verification_status" gorm:"size:32"`
+	BlockNumber        uint64    `json:"block_number" gorm:"index"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DataQualityMetric 数据质量指标的时间序列，每条事件一行，未携带的字段为空
+type DataQualityMetric struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	Source             string     `json:"source" gorm:"index:idx_data_quality_metric_subject;size:32"`
+	SubjectID          string     `json:"subject_id" gorm:"index:idx_data_quality_metric_subject;size:66"`
+	EventName          string     `json:"event_name" gorm:"size:64"`
+	DataHash           string     `json:"data_hash,omitempty"`
+	FeatureHash        string     `json:"feature_hash,omitempty"`
+	DataType           string     `json:"data_type,omitempty" gorm:"size:64"`
+	DataCount          string     `json:"data_count,omitempty" gorm:"size:78"`
+	Submitter          string     `json:"submitter,omitempty" gorm:"size:64"`
+	Mean               string     `json:"mean,omitempty" gorm:"size:78"`
+	StandardDeviation  string     `json:"standard_deviation,omitempty" gorm:"size:78"`
+	MinValue           string     `json:"min_value,omitempty" gorm:"size:78"`
+	MaxValue           string     `json:"max_value,omitempty" gorm:"size:78"`
+	Score              string     `json:"score,omitempty" gorm:"size:78"`
+	IsValid            *bool      `json:"is_valid,omitempty"`
+	VerificationStatus string     `json:"verification_status,omitempty" gorm:"size:32"`
+	RecordedAt         *time.Time `json:"recorded_at,omitempty"`
+	BlockNumber        uint64     `json:"block_number" gorm:"index"`
+	TxHash             string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex           uint       `json:"log_index"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// VerifierConstraint ResearchDataVerifier 的全局约束阈值，同一类型以最新一条为准
+type VerifierConstraint struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ConstraintType string    `json:"constraint_type" gorm:"index;size:64"`
+	Threshold      string    `json:"threshold" gorm:"size:78"`
+	Description    string    `json:"description"`
+	BlockNumber    uint64    `json:"block_number" gorm:"index"`
+	TxHash         string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex       uint      `json:"log_index"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// DataQualityFeatures 与数据集关联的一条质量投影及其指标历史（最新在前）
+type DataQualityFeatures struct {
+	DataQuality
+	History []DataQualityMetric `json:"history"`
+}
+
+// DataQualityEventPayload DataFeatureExtractor/ResearchDataVerifier 事件载荷
+type DataQualityEventPayload struct {
+	DataID            string      `json:"dataId"`
+	FeatureID         string      `json:"featureId"`
+	Submitter         string      `json:"submitter"`
+	Calculator        string      `json:"calculator"`
+	DataType          interface{} `json:"dataType"` // 特征提取器为枚举值，验证器为字符串
+	DataHash          string      `json:"dataHash"`
+	FeatureHash       string      `json:"featureHash"`
+	DataCount         string      `json:"dataCount"`
+	Mean              string      `json:"mean"`
+	StandardDeviation string      `json:"standardDeviation"`
+	MinValue          string      `json:"minValue"`
+	MaxValue          string      `json:"maxValue"`
+	IsValid           *bool       `json:"isValid"`
+	Score             string      `json:"score"`
+	Status            string      `json:"status"`
+	ConstraintType    string      `json:"constraintType"`
+	Threshold         string      `json:"threshold"`
+	Description       string      `json:"description"`
+	BlockTime         uint64      `json:"blockTimestamp"`
+}
+
+// DataTypeName 返回数据类型名称
+func (e *DataQualityEventPayload) DataTypeName() string {
+	switch v := e.DataType.(type) {
+	case string:
+		return v
+	case float64:
+		return FeatureDataTypeName(uint8(v))
+	}
+	return ""
+}
+
+// FeatureDataTypeName 返回 DataFeatureExtractor.DataType 枚举对应的名称
+func FeatureDataTypeName(dataType uint8) string {
+	names := []string{"experimental", "statistical", "time_series", "categorical", "numerical"}
+	if int(dataType) < len(names) {
+		return names[dataType]
+	}
+	return "unknown"
+}
+
+// Apply 将一条指标记录合并到投影上，只覆盖该记录携带的字段
+func (q *DataQuality) Apply(m *DataQualityMetric) {
+	setIfPresent(&q.DataHash, m.DataHash)
+	setIfPresent(&q.FeatureHash, m.FeatureHash)
+	setIfPresent(&q.DataType, m.DataType)
+	setIfPresent(&q.DataCount, m.DataCount)
+	setIfPresent(&q.Submitter, m.Submitter)
+	setIfPresent(&q.Mean, m.Mean)
+	setIfPresent(&q.StandardDeviation, m.StandardDeviation)
+	setIfPresent(&q.MinValue, m.MinValue)
+	setIfPresent(&q.MaxValue, m.MaxValue)
+	setIfPresent(&q.Score, m.Score)
+	setIfPresent(&q.VerificationStatus, m.VerificationStatus)
+	if m.IsValid != nil {
+		q.IsValid = m.IsValid
+	}
+	q.BlockNumber = m.BlockNumber
+}
+
+func setIfPresent(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+// InfluenceChange 用户影响力和排名变化记录（InfluenceRanking.InfluenceUpdated / DeSciPlatform.InfluenceRankingUpdated）
+type InfluenceChange struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	User               string     `json:"user" gorm:"column:user_address;index;size:64"` // user 是 Postgres 保留字
+	EventName          string     `json:"event_name" gorm:"size:64"`
+	OldInfluence       string     `json:"old_influence,omitempty" gorm:"size:78"`
+	NewInfluence       string     `json:"new_influence,omitempty" gorm:"size:78"`
+	OldRank            string     `json:"old_rank" gorm:"size:78"`
+	NewRank            string     `json:"new_rank" gorm:"size:78"`
+	PublicationScore   string     `json:"publication_score,omitempty" gorm:"size:78"`
+	ReviewScore        string     `json:"review_score,omitempty" gorm:"size:78"`
+	DataContribution   string     `json:"data_contribution,omitempty" gorm:"size:78"`
+	CollaborationScore string     `json:"collaboration_score,omitempty" gorm:"size:78"`
+	GovernanceScore    string     `json:"governance_score,omitempty" gorm:"size:78"`
+	ChangedAt          *time.Time `json:"changed_at,omitempty"`
+	// IsLatest 是否为该用户最新一条 InfluenceUpdated 记录；InfluenceKey 为左补零的新影响力，二者构成排行榜索引
+	IsLatest     bool      `json:"-" gorm:"index:idx_influence_leaderboard,priority:1"`
+	InfluenceKey string    `json:"-" gorm:"size:78;index:idx_influence_leaderboard,priority:2"`
+	BlockNumber  uint64    `json:"block_number" gorm:"index"`
+	TxHash       string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex     uint      `json:"log_index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// InfluenceWeights 影响力权重配置变更记录，最新一条为当前配置
+type InfluenceWeights struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	PublicationWeight   string     `json:"publication_weight" gorm:"size:78"`
+	ReviewWeight        string     `json:"review_weight" gorm:"size:78"`
+	DataWeight          string     `json:"data_weight" gorm:"size:78"`
+	CollaborationWeight string     `json:"collaboration_weight" gorm:"size:78"`
+	GovernanceWeight    string     `json:"governance_weight" gorm:"size:78"`
+	ChangedAt           *time.Time `json:"changed_at,omitempty"`
+	BlockNumber         uint64     `json:"block_number" gorm:"index"`
+	TxHash              string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex            uint       `json:"log_index"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// RankingRefresh 排名刷新记录
+type RankingRefresh struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	RankingType     uint8      `json:"ranking_type"`
+	RankingTypeName string     `json:"ranking_type_name" gorm:"size:32"`
+	Identifier      string     `json:"identifier" gorm:"size:255"`
+	RefreshedAt     *time.Time `json:"refreshed_at,omitempty"`
+	BlockNumber     uint64     `json:"block_number" gorm:"index"`
+	TxHash          string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex        uint       `json:"log_index"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// RewardDistribution DeSciPlatform 奖励发放流水
+type RewardDistribution struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	User          string     `json:"user" gorm:"column:user_address;index;size:64"`
+	Amount        string     `json:"amount" gorm:"size:78"`
+	Reason        string     `json:"reason"`
+	DistributedAt *time.Time `json:"distributed_at,omitempty"`
+	BlockNumber   uint64     `json:"block_number" gorm:"index"`
+	TxHash        string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex      uint       `json:"log_index"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// RewardSummary 用户奖励汇总（金额为 wei 十进制字符串）
+type RewardSummary struct {
+	User          string               `json:"user"`
+	TotalRewards  string               `json:"total_rewards"`
+	Distributions int                  `json:"distributions"`
+	History       []RewardDistribution `json:"history"`
+}
+
+// Collaboration 合作关系边：两位作者共同署名的研究成果
+type Collaboration struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	User1       string     `json:"user1" gorm:"index;size:64"`
+	User2       string     `json:"user2" gorm:"index;size:64"`
+	ResearchID  string     `json:"research_id" gorm:"index;size:78"`
+	FormedAt    *time.Time `json:"formed_at,omitempty"`
+	BlockNumber uint64     `json:"block_number" gorm:"index"`
+	TxHash      string     `json:"tx_hash" gorm:"size:66"`
+	LogIndex    uint       `json:"log_index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// InfluenceEventPayload InfluenceRanking/DeSciPlatform 事件载荷
+type InfluenceEventPayload struct {
+	User                string `json:"user"`
+	OldInfluence        string `json:"oldInfluence"`
+	NewInfluence        string `json:"newInfluence"`
+	OldRank             string `json:"oldRank"`
+	NewRank             string `json:"newRank"`
+	PublicationScore    string `json:"publicationScore"`
+	ReviewScore         string `json:"reviewScore"`
+	DataContribution    string `json:"dataContribution"`
+	CollaborationScore  string `json:"collaborationScore"`
+	GovernanceScore     string `json:"governanceScore"`
+	RankingType         uint8  `json:"rankingType"`
+	Identifier          string `json:"identifier"`
+	Timestamp           string `json:"timestamp"`
+	PublicationWeight   string `json:"publicationWeight"`
+	ReviewWeight        string `json:"reviewWeight"`
+	DataWeight          string `json:"dataWeight"`
+	CollaborationWeight string `json:"collaborationWeight"`
+	GovernanceWeight    string `json:"governanceWeight"`
+	Amount              string `json:"amount"`
+	Reason              string `json:"reason"`
+	User1               string `json:"user1"`
+	User2               string `json:"user2"`
+	ResearchID          string `json:"researchId"`
+	BlockTime           uint64 `json:"blockTimestamp"`
+}
+
+// RankingTypeName 返回 InfluenceRanking.RankingType 枚举对应的名称
+func RankingTypeName(rankingType uint8) string {
+	names := []string{"global", "field", "institution", "regional", "trending"}
+	if int(rankingType) < len(names) {
+		return names[rankingType]
+	}
+	return "unknown"
+}
+
 // EventLog 事件日志表结构
 type EventLog struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`
@@ -74,12 +951,282 @@ type EventLog struct {
 	LogIndex     uint      `json:"log_index"`
 	BlockNumber  uint64    `json:"block_number" gorm:"index"`
 	EventName    string    `json:"event_name" gorm:"index;size:255"`
-	ContractAddr string    `json:"contract_address"`
+	EntityID     string    `json:"entity_id" gorm:"index;size:255"`
+	ContractAddr string    `json:"contract_address" gorm:"index;size:64"`
+	// Topic0 事件签名哈希，Topics/Data 为原始日志内容（十六进制），可脱离链重新解码
+	Topic0    string      `json:"topic0" gorm:"index;size:66"`
+	Topics    StringArray `json:"topics" gorm:"type:text"`
+	Data      string      `json:"data" gorm:"type:text"`
+	BlockHash string      `json:"block_hash" gorm:"size:66"`
+	BlockTime *time.Time  `json:"block_time,omitempty"`
+	// Args 按ABI解码的全部事件参数；PayloadRaw 在此基础上包含从合约补全的字段，供服务层物化
+	Args         JSONMap   `json:"args" gorm:"type:text"`
 	PayloadRaw   string    `json:"payload_raw" gorm:"type:text"`
+	Status       string    `json:"status" gorm:"index;size:32;default:confirmed"`
 	Processed    bool      `json:"processed" gorm:"default:false"`
+	// 物化失败的次数、最近一次错误和下次重试时间，由重放任务按退避策略重试
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	// DeadLettered 失败次数达到上限后转入死信队列，重放任务不再自动重试
+	DeadLettered bool      `json:"dead_lettered" gorm:"index;default:false"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// 事件确认状态
+const (
+	EventStatusPending   = "pending"   // 等待足够的区块确认
+	EventStatusConfirmed = "confirmed" // 已达到确认深度，可物化到业务表
+)
+
+// DeadLetter 多次处理失败的事件，保留错误、调用栈和载荷，供运维修复后重试或丢弃
+type DeadLetter struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// EventLogID 对应的 event_logs 记录；监听器入库前失败的事件为 0，载荷为完整的 ParsedEvent
+	EventLogID   uint      `json:"event_log_id" gorm:"index"`
+	Source       string    `json:"source" gorm:"index;size:32"`
+	EventName    string    `json:"event_name" gorm:"index;size:255"`
+	TxHash       string    `json:"tx_hash" gorm:"index;size:255"`
+	LogIndex     uint      `json:"log_index"`
+	BlockNumber  uint64    `json:"block_number" gorm:"index"`
+	ContractAddr string    `json:"contract_address" gorm:"size:64"`
+	Attempts     int       `json:"attempts"`
+	Error        string    `json:"error" gorm:"type:text"`
+	Stack        string    `json:"stack,omitempty" gorm:"type:text"`
+	PayloadRaw   string    `json:"payload_raw" gorm:"type:text"`
+	Status       string    `json:"status" gorm:"index;size:32;default:dead"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// 死信来源
+const (
+	DeadLetterSourceService  = "service"  // 服务层物化失败
+	DeadLetterSourceListener = "listener" // 监听器写入 event_logs 前失败
+)
+
+// 死信状态
+const (
+	DeadLetterStatusDead      = "dead"      // 等待处理
+	DeadLetterStatusResolved  = "resolved"  // 重试成功
+	DeadLetterStatusDiscarded = "discarded" // 已丢弃
+)
+
+// IndexedBlock 已索引区块的哈希记录（用于链重组检测）
+type IndexedBlock struct {
+	Number     uint64    `json:"number" gorm:"primaryKey;autoIncrement:false"`
+	Hash       string    `json:"hash" gorm:"size:66"`
+	ParentHash string    `json:"parent_hash" gorm:"size:66"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SyncCursor 每个合约已完整索引到的区块高度（用于断点续扫）
+type SyncCursor struct {
+	ContractAddr string    `json:"contract_address" gorm:"primaryKey;size:64"`
+	LastBlock    uint64    `json:"last_block"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UserProfile 用户档案投影（由 DeSciRegistry 事件构建）
+type UserProfile struct {
+	ID                 uint        `json:"id" gorm:"primaryKey"`
+	WalletAddress      string      `json:"wallet_address" gorm:"uniqueIndex;size:64"`
+	Name               string      `json:"name"`
+	Role               uint8       `json:"role"`
+	RoleName           string      `json:"role_name" gorm:"size:32"`
+	Registered         bool        `json:"registered"`
+	VerificationStatus string      `json:"verification_status" gorm:"size:32"`
+	RequestedRole      uint8       `json:"requested_role"`
+	VerificationReqID  string      `json:"verification_request_id" gorm:"size:78"`
+	Verifier           string      `json:"verifier" gorm:"size:64"`
+	VerifiedAt         *time.Time  `json:"verified_at"`
+	Reputation         string      `json:"reputation" gorm:"size:78;default:0"`
+	AccessRoles        StringArray `json:"access_roles" gorm:"type:text"`
+	RegisteredAt       *time.Time  `json:"registered_at"`
+	BlockNumber        uint64      `json:"block_number" gorm:"index"` // 最近一次更新所在区块
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+}
+
+// ReputationChange 用户声誉变更历史
+type ReputationChange struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	WalletAddress string    `json:"wallet_address" gorm:"index;size:64"`
+	OldReputation string    `json:"old_reputation" gorm:"size:78"`
+	NewReputation string    `json:"new_reputation" gorm:"size:78"`
+	Reason        string    `json:"reason"`
+	BlockNumber   uint64    `json:"block_number" gorm:"index"`
+	TxHash        string    `json:"tx_hash" gorm:"size:66"`
+	LogIndex      uint      `json:"log_index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// 用户验证状态
+const (
+	VerificationUnverified = "unverified"
+	VerificationPending    = "pending"
+	VerificationVerified   = "verified"
+)
+
+// UserEvents 参与构建用户档案的 DeSciRegistry 事件
+var UserEvents = []string{
+	"UserRegistered",
+	"VerificationRequested",
+	"UserVerified",
+	"RoleChanged",
+	"ReputationUpdated",
+	"RoleGranted",
+	"RoleRevoked",
+}
+
+// UserRoleName 返回 DeSciRegistry.UserRole 枚举对应的名称
+func UserRoleName(role uint8) string {
+	switch role {
+	case 0:
+		return "none"
+	case 1:
+		return "researcher"
+	case 2:
+		return "reviewer"
+	case 3:
+		return "data_provider"
+	case 4:
+		return "institution"
+	default:
+		return "unknown"
+	}
+}
+
+// UserEventPayload 用户事件载荷（与ABI参数同名）
+type UserEventPayload struct {
+	User          string `json:"user"`
+	Applicant     string `json:"applicant"`
+	Account       string `json:"account"`
+	Name          string `json:"name"`
+	Role          uint8  `json:"role"`
+	OldRole       uint8  `json:"oldRole"`
+	NewRole       uint8  `json:"newRole"`
+	RequestedRole uint8  `json:"requestedRole"`
+	RequestID     string `json:"requestId"`
+	Verifier      string `json:"verifier"`
+	AccessRole    string `json:"-"` // RoleGranted/RoleRevoked 的 bytes32 角色
+
+	OldReputation string `json:"oldReputation"`
+	NewReputation string `json:"newReputation"`
+	Reason        string `json:"reason"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// ParseUserEventPayload 解析用户事件载荷；AccessControl 事件的 role 是 bytes32 而非枚举
+func ParseUserEventPayload(eventName, raw string) (*UserEventPayload, error) {
+	if eventName == "RoleGranted" || eventName == "RoleRevoked" {
+		var access struct {
+			Role    string `json:"role"`
+			Account string `json:"account"`
+		}
+		if err := json.Unmarshal([]byte(raw), &access); err != nil {
+			return nil, err
+		}
+		return &UserEventPayload{Account: access.Account, AccessRole: access.Role}, nil
+	}
+
+	var e UserEventPayload
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Subject 返回事件所属的用户地址
+func (e *UserEventPayload) Subject() string {
+	for _, addr := range []string{e.User, e.Applicant, e.Account} {
+		if addr != "" {
+			return strings.ToLower(addr)
+		}
+	}
+	return ""
+}
+
+// Apply 将一条用户事件应用到档案上
+func (p *UserProfile) Apply(eventName string, e *UserEventPayload, block uint64) {
+	at := EventTime(e.Timestamp)
+	switch eventName {
+	case "UserRegistered":
+		p.Name = e.Name
+		p.setRole(e.Role)
+		p.Registered = true
+		p.RegisteredAt = at
+		// 需要审核的角色会在同一交易中紧接着发出 VerificationRequested
+		p.VerificationStatus = VerificationVerified
+		p.VerifiedAt = at
+	case "VerificationRequested":
+		p.VerificationStatus = VerificationPending
+		p.RequestedRole = e.RequestedRole
+		p.VerificationReqID = e.RequestID
+	case "UserVerified":
+		p.setRole(e.Role)
+		p.VerificationStatus = VerificationVerified
+		p.Verifier = e.Verifier
+		p.VerifiedAt = at
+	case "RoleChanged":
+		p.setRole(e.NewRole)
+	case "ReputationUpdated":
+		p.Reputation = e.NewReputation
+	case "RoleGranted":
+		if !containsString(p.AccessRoles, e.AccessRole) {
+			p.AccessRoles = append(p.AccessRoles, e.AccessRole)
+		}
+	case "RoleRevoked":
+		roles := StringArray{}
+		for _, r := range p.AccessRoles {
+			if r != e.AccessRole {
+				roles = append(roles, r)
+			}
+		}
+		p.AccessRoles = roles
+	}
+	if p.VerificationStatus == "" {
+		p.VerificationStatus = VerificationUnverified
+	}
+	if p.Reputation == "" {
+		p.Reputation = "0"
+	}
+	p.BlockNumber = block
+}
+
+func (p *UserProfile) setRole(role uint8) {
+	p.Role = role
+	p.RoleName = UserRoleName(role)
+}
+
+// UnixTime 将区块时间戳转换为时间，0 表示未知
+func UnixTime(sec uint64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(int64(sec), 0).UTC()
+	return &t
+}
+
+// EventTime 将链上秒级时间戳转换为时间
+func EventTime(ts string) *time.Time {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sec <= 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // ParsedEvent 解析后的事件结构（用于事件监听）
 type ParsedEvent struct {
 	TokenID     string   `json:"token_id"`
@@ -89,11 +1236,22 @@ type ParsedEvent struct {
 	DataHash    string   `json:"data_hash"`
 	MetadataHash string   `json:"metadata_hash,omitempty"`
 	Block       uint64   `json:"block"`
+	BlockTime   uint64   `json:"block_time,omitempty"` // 区块时间戳（秒）
 	TxHash      string   `json:"tx_hash"`
 	LogIndex    uint     `json:"log_index"`
 	EventName   string   `json:"event_name"`
+	// EntityID 事件聚合的实体（token/数据集ID、用户地址等），为空时取 TokenID
+	EntityID    string   `json:"entity_id,omitempty"`
 	Title       string   `json:"title,omitempty"`
 	Description string   `json:"description,omitempty"`
+	// Args 事件参数及解码器从合约补全的字段（地址/哈希为十六进制，uint256 为十进制字符串）
+	Args map[string]interface{} `json:"args,omitempty"`
+	// DecodedArgs 仅按ABI解码的事件参数
+	DecodedArgs map[string]interface{} `json:"decoded_args,omitempty"`
+	// 原始日志：topics 与 data 为十六进制
+	Topics    []string `json:"topics,omitempty"`
+	Data      string   `json:"data,omitempty"`
+	BlockHash string   `json:"block_hash,omitempty"`
 }
 
 // 复合唯一索引: tx_hash + log_index
@@ -131,3 +1289,37 @@ func (a *StringArray) Scan(value interface{}) error {
 		return errors.New("cannot scan into StringArray")
 	}
 }
+
+// JSONMap 以JSON文本存储的对象，兼容SQLite和PostgreSQL
+type JSONMap map[string]interface{}
+
+// Value 实现 driver.Valuer 接口
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan 实现 sql.Scanner 接口，数字保留为 json.Number 以免精度丢失
+func (m *JSONMap) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("cannot scan into JSONMap")
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(m)
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// blockScopedModels 含 block_number 列、链重组时需要按区块回滚的表
+var blockScopedModels = []interface{}{
+	&model.ResearchData{},
+	&model.ResearchAuthor{},
+	&model.DatasetRecord{},
+	&model.EventLog{},
+	&model.ReputationChange{},
+	&model.DatasetAccess{},
+	&model.DatasetCitation{},
+	&model.DatasetQualityChange{},
+	&model.DatasetRevenue{},
+	&model.ResearchCitation{},
+	&model.ResearchReview{},
+	&model.ResearchImpactChange{},
+	&model.ResearchRevenue{},
+	&model.ResearchMetadataUpdate{},
+	&model.NFTTransfer{},
+	&model.ZKProof{},
+	&model.ProofVerification{},
+	&model.ConstraintGroup{},
+	&model.ValidationRule{},
+	&model.ConstraintEvaluation{},
+	&model.DataQualityMetric{},
+	&model.VerifierConstraint{},
+	&model.InfluenceChange{},
+	&model.InfluenceWeights{},
+	&model.RankingRefresh{},
+	&model.RewardDistribution{},
+	&model.Collaboration{},
+	&model.TokenTransfer{},
+	&model.TokenApproval{},
+	&model.DeadLetter{},
+}
+
+// 查询指定高度的已索引区块
+func (r *Repository) GetIndexedBlock(number uint64) (*model.IndexedBlock, error) {
+	var block model.IndexedBlock
+	err := r.db.Where("number = ?", number).First(&block).Error
+	return &block, err
+}
+
+// 查询低于指定高度的最近一个已索引区块
+func (r *Repository) GetLatestIndexedBlockBefore(number uint64) (*model.IndexedBlock, error) {
+	var block model.IndexedBlock
+	err := r.db.Where("number < ?", number).Order("number DESC").First(&block).Error
+	return &block, err
+}
+
+// 保存已索引区块（同高度覆盖）
+func (r *Repository) SaveIndexedBlock(block *model.IndexedBlock) error {
+	return r.db.Save(block).Error
+}
+
+// 回滚指定高度及之后的全部派生数据、事件日志和区块记录
+func (r *Repository) RollbackFromBlock(number uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 先记录受影响的研究成果和 token，删除后重新计算统计与持有人
+		affected, err := derivedFromBlock(tx, number)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range blockScopedModels {
+			if err := tx.Where("block_number >= ?", number).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("number >= ?", number).Delete(&model.IndexedBlock{}).Error; err != nil {
+			return err
+		}
+		if err := rebuildProjections(tx, number); err != nil {
+			return err
+		}
+		if err := affected.refresh(tx); err != nil {
+			return err
+		}
+		// 同步游标退回到分叉点之前，确保孤立区间会被重新扫描
+		if number == 0 {
+			return tx.Where("1 = 1").Delete(&model.SyncCursor{}).Error
+		}
+		return tx.Model(&model.SyncCursor{}).Where("last_block >= ?", number).
+			Update("last_block", number-1).Error
+	})
+}
+
+// 删除区块范围内的派生数据，并把范围内已确认的事件重置为未处理，返回重置的事件数
+func (r *Repository) ResetBlockRange(fromBlock, toBlock uint64) (int64, error) {
+	var reset int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 受影响的实体与删除范围一致，范围之后的记录不受影响
+		affected, err := derivedInRange(tx, blockRange{from: fromBlock, to: &toBlock})
+		if err != nil {
+			return err
+		}
+		for _, m := range blockScopedModels {
+			// 事件日志和死信是重建的输入，只删除派生数据
+			switch m.(type) {
+			case *model.EventLog, *model.DeadLetter:
+				continue
+			}
+			if err := tx.Where("block_number >= ? AND block_number <= ?", fromBlock, toBlock).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		if err := affected.refresh(tx); err != nil {
+			return err
+		}
+		result := tx.Model(&model.EventLog{}).
+			Where("block_number >= ? AND block_number <= ? AND status = ?", fromBlock, toBlock, model.EventStatusConfirmed).
+			Updates(map[string]interface{}{"processed": false, "attempts": 0, "last_error": "", "next_attempt_at": nil})
+		reset = result.RowsAffected
+		return result.Error
+	})
+	return reset, err
+}
+
+// 按 event_logs 重建指定高度之后变更过的可变投影，并重新计算由派生记录汇总的统计
+func (r *Repository) RefreshProjections(fromBlock uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		affected, err := derivedFromBlock(tx, fromBlock)
+		if err != nil {
+			return err
+		}
+		if err := rebuildProjections(tx, fromBlock); err != nil {
+			return err
+		}
+		return affected.refresh(tx)
+	})
+}
+
+// derivedEntities 在某高度之后有派生记录、需要重新汇总的实体
+type derivedEntities struct {
+	researchTokens  []string
+	nftTokens       []nftToken
+	verifiedProofs  []string
+	qualitySubjects []model.DataQualityMetric
+	tokenHolders    []string
+	influenceUsers  []string
+}
+
+// blockRange 派生记录的区块范围，to 为 nil 时不设上限
+type blockRange struct {
+	from uint64
+	to   *uint64
+}
+
+func (b blockRange) scope(db *gorm.DB) *gorm.DB {
+	db = db.Where("block_number >= ?", b.from)
+	if b.to != nil {
+		db = db.Where("block_number <= ?", *b.to)
+	}
+	return db
+}
+
+// derivedFromBlock 收集在指定高度及之后有派生记录的实体
+func derivedFromBlock(tx *gorm.DB, number uint64) (*derivedEntities, error) {
+	return derivedInRange(tx, blockRange{from: number})
+}
+
+// derivedInRange 收集在区块范围内有派生记录的实体
+func derivedInRange(tx *gorm.DB, blocks blockRange) (*derivedEntities, error) {
+	var d derivedEntities
+	var err error
+	if d.researchTokens, err = researchTokensInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	if d.nftTokens, err = nftTokensInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	if d.verifiedProofs, err = proofsVerifiedInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	if d.qualitySubjects, err = dataQualityInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	if d.tokenHolders, err = tokenHoldersInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	if d.influenceUsers, err = influenceUsersInRange(tx, blocks); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// refresh 按剩余的派生记录重新计算统计、持有人、证明状态、质量投影、余额和排行榜条目
+func (d *derivedEntities) refresh(tx *gorm.DB) error {
+	for _, tokenID := range d.researchTokens {
+		if err := refreshResearchStats(tx, tokenID); err != nil {
+			return err
+		}
+	}
+	for _, t := range d.nftTokens {
+		if err := refreshNFTOwner(tx, t.Collection, t.TokenID); err != nil {
+			return err
+		}
+	}
+	for _, proofID := range d.verifiedProofs {
+		if err := refreshProofStatus(tx, proofID); err != nil {
+			return err
+		}
+	}
+	for _, q := range d.qualitySubjects {
+		if err := refreshDataQuality(tx, q.Source, q.SubjectID); err != nil {
+			return err
+		}
+	}
+	for _, holder := range d.tokenHolders {
+		if err := refreshTokenBalance(tx, holder); err != nil {
+			return err
+		}
+	}
+	for _, user := range d.influenceUsers {
+		if err := refreshLatestInfluence(tx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildProjections 可变投影无法按区块删除，需要从剩余事件重建
+func rebuildProjections(tx *gorm.DB, number uint64) error {
+	if err := rebuildUserProfiles(tx, number); err != nil {
+		return err
+	}
+	if err := rebuildProofTypes(tx, number); err != nil {
+		return err
+	}
+	return rebuildConstraints(tx, number)
+}
+
+// 查询全部合约的同步游标
+func (r *Repository) GetSyncCursors() ([]model.SyncCursor, error) {
+	var cursors []model.SyncCursor
+	err := r.db.Find(&cursors).Error
+	return cursors, err
+}
+
+// 保存合约的同步游标（覆盖写入）
+func (r *Repository) SaveSyncCursor(contractAddr string, lastBlock uint64) error {
+	return r.db.Save(&model.SyncCursor{ContractAddr: contractAddr, LastBlock: lastBlock}).Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 查询约束
+func (r *Repository) GetConstraint(constraintID string) (*model.Constraint, error) {
+	var constraint model.Constraint
+	err := r.db.Where("constraint_id = ?", constraintID).First(&constraint).Error
+	return &constraint, err
+}
+
+// 保存约束（新建或覆盖）
+func (r *Repository) SaveConstraint(constraint *model.Constraint) error {
+	return r.db.Save(constraint).Error
+}
+
+// 查询约束列表，可按类别过滤
+func (r *Repository) ListConstraints(category string, activeOnly bool) ([]model.Constraint, error) {
+	var constraints []model.Constraint
+	query := r.read().Model(&model.Constraint{})
+	if category != "" {
+		query = query.Where("category_name = ?", category)
+	}
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("block_number ASC, name ASC").Find(&constraints).Error
+	return constraints, err
+}
+
+// 插入约束组（按 group_id 去重）
+func (r *Repository) InsertConstraintGroup(group *model.ConstraintGroup) error {
+	return r.db.FirstOrCreate(group, "group_id = ?", group.GroupID).Error
+}
+
+// 查询全部约束组
+func (r *Repository) ListConstraintGroups() ([]model.ConstraintGroup, error) {
+	var groups []model.ConstraintGroup
+	err := r.db.Order("block_number ASC, name ASC").Find(&groups).Error
+	return groups, err
+}
+
+// 插入验证规则（按 rule_id 去重）
+func (r *Repository) InsertValidationRule(rule *model.ValidationRule) error {
+	return r.db.FirstOrCreate(rule, "rule_id = ?", rule.RuleID).Error
+}
+
+// 查询全部验证规则
+func (r *Repository) ListValidationRules() ([]model.ValidationRule, error) {
+	var rules []model.ValidationRule
+	err := r.db.Order("block_number ASC, name ASC").Find(&rules).Error
+	return rules, err
+}
+
+// 插入约束评估结果（按 tx_hash + log_index 去重）
+func (r *Repository) InsertConstraintEvaluation(evaluation *model.ConstraintEvaluation) error {
+	return r.db.FirstOrCreate(evaluation, "tx_hash = ? AND log_index = ?", evaluation.TxHash, evaluation.LogIndex).Error
+}
+
+// 查询约束的评估历史（最新在前）
+func (r *Repository) ListConstraintEvaluations(constraintID string, limit int) ([]model.ConstraintEvaluation, error) {
+	var evaluations []model.ConstraintEvaluation
+	query := r.read().Where("constraint_id = ?", constraintID).Order("block_number DESC, log_index DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&evaluations).Error
+	return evaluations, err
+}
+
+// 分页查询与数据集事件同一交易中产生的约束评估（默认最新在前）
+func (r *Repository) ListDatasetConstraintEvaluations(datasetID string, page PageRequest) (*Page[model.ConstraintEvaluation], error) {
+	query := r.read().Model(&model.ConstraintEvaluation{}).Where("tx_hash IN (?)", r.datasetEventTxs(datasetID))
+	return paginate[model.ConstraintEvaluation](query, page, eventSortKeys)
+}
+
+// 查询数据集每个约束最近一次的评估结果，用于计算验证规则得分
+func (r *Repository) ListLatestDatasetConstraintResults(datasetID string) (map[string]bool, error) {
+	var rows []struct {
+		ConstraintID string
+		Result       bool
+	}
+	err := r.read().Model(&model.ConstraintEvaluation{}).Select("constraint_id, result").
+		Where("tx_hash IN (?)", r.datasetEventTxs(datasetID)).
+		Order("block_number DESC, log_index DESC").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]bool)
+	for _, row := range rows {
+		if _, ok := latest[row.ConstraintID]; !ok {
+			latest[row.ConstraintID] = row.Result
+		}
+	}
+	return latest, nil
+}
+
+// datasetEventTxs 数据集已确认事件所在交易的子查询
+func (r *Repository) datasetEventTxs(datasetID string) *gorm.DB {
+	return r.read().Model(&model.EventLog{}).Select("tx_hash").
+		Where("entity_id = ? AND event_name IN ? AND status = ?", datasetID, model.DatasetEvents, model.EventStatusConfirmed)
+}
+
+// rebuildConstraints 链重组回滚后，用剩余的已确认事件重建在分叉点之后更新过的约束
+func rebuildConstraints(tx *gorm.DB, number uint64) error {
+	var stale []model.Constraint
+	if err := tx.Where("block_number >= ?", number).Find(&stale).Error; err != nil {
+		return err
+	}
+
+	for _, old := range stale {
+		var events []model.EventLog
+		err := tx.Where("event_name IN ? AND entity_id = ? AND status = ?", model.ConstraintEvents, old.ConstraintID, model.EventStatusConfirmed).
+			Order("block_number ASC, log_index ASC").Find(&events).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("constraint_id = ?", old.ConstraintID).Delete(&model.Constraint{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		constraint := &model.Constraint{ConstraintID: old.ConstraintID}
+		for _, event := range events {
+			var payload model.ConstraintEventPayload
+			if err := json.Unmarshal([]byte(event.PayloadRaw), &payload); err != nil {
+				return err
+			}
+			constraint.Apply(event.EventName, &payload, event.BlockNumber)
+		}
+		if err := tx.Create(constraint).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"strings"
+
+	"desci-backend/internal/model"
+)
+
+// 插入数据集访问记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertDatasetAccess(access *model.DatasetAccess) error {
+	access.User = strings.ToLower(access.User)
+	return r.db.FirstOrCreate(access, "tx_hash = ? AND log_index = ?", access.TxHash, access.LogIndex).Error
+}
+
+// 分页查询数据集的访问记录（默认最新在前）
+func (r *Repository) ListDatasetAccesses(datasetID string, page PageRequest) (*Page[model.DatasetAccess], error) {
+	query := r.read().Model(&model.DatasetAccess{}).Where("dataset_id = ?", datasetID)
+	return paginate[model.DatasetAccess](query, page, eventSortKeys)
+}
+
+// 插入数据集引用记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertDatasetCitation(citation *model.DatasetCitation) error {
+	citation.Researcher = strings.ToLower(citation.Researcher)
+	return r.db.FirstOrCreate(citation, "tx_hash = ? AND log_index = ?", citation.TxHash, citation.LogIndex).Error
+}
+
+// 分页查询数据集的引用记录（默认最新在前）
+func (r *Repository) ListDatasetCitations(datasetID string, page PageRequest) (*Page[model.DatasetCitation], error) {
+	query := r.read().Model(&model.DatasetCitation{}).Where("dataset_id = ?", datasetID)
+	return paginate[model.DatasetCitation](query, page, eventSortKeys)
+}
+
+// 插入数据集质量变更记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertDatasetQualityChange(change *model.DatasetQualityChange) error {
+	return r.db.FirstOrCreate(change, "tx_hash = ? AND log_index = ?", change.TxHash, change.LogIndex).Error
+}
+
+// 分页查询数据集的质量变更历史（默认最新在前）
+func (r *Repository) ListDatasetQualityChanges(datasetID string, page PageRequest) (*Page[model.DatasetQualityChange], error) {
+	query := r.read().Model(&model.DatasetQualityChange{}).Where("dataset_id = ?", datasetID)
+	return paginate[model.DatasetQualityChange](query, page, eventSortKeys)
+}
+
+// 查询数据集最新一次质量变更，即当前质量等级
+func (r *Repository) GetLatestDatasetQualityChange(datasetID string) (*model.DatasetQualityChange, error) {
+	var change model.DatasetQualityChange
+	err := r.db.Where("dataset_id = ?", datasetID).Order("block_number DESC, log_index DESC").First(&change).Error
+	return &change, err
+}
+
+// 插入数据集收益分配记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertDatasetRevenue(revenue *model.DatasetRevenue) error {
+	revenue.Owner = strings.ToLower(revenue.Owner)
+	return r.db.FirstOrCreate(revenue, "tx_hash = ? AND log_index = ?", revenue.TxHash, revenue.LogIndex).Error
+}
+
+// 查询数据集的全部收益分配记录（按链上顺序）
+func (r *Repository) ListDatasetRevenue(datasetID string) ([]model.DatasetRevenue, error) {
+	var revenue []model.DatasetRevenue
+	err := r.db.Where("dataset_id = ?", datasetID).Order("block_number ASC, log_index ASC").Find(&revenue).Error
+	return revenue, err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 写入一条死信
+func (r *Repository) InsertDeadLetter(letter *model.DeadLetter) error {
+	if letter.Status == "" {
+		letter.Status = model.DeadLetterStatusDead
+	}
+	return r.db.Create(letter).Error
+}
+
+// 将事件转入死信队列：已有待处理死信时更新错误和尝试次数，否则新建，并标记事件不再自动重试
+func (r *Repository) DeadLetterEvent(event *model.EventLog, errMsg, stack string) (*model.DeadLetter, error) {
+	var letter model.DeadLetter
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("event_log_id = ? AND status = ?", event.ID, model.DeadLetterStatusDead).First(&letter).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			letter = model.DeadLetter{
+				EventLogID:   event.ID,
+				Source:       model.DeadLetterSourceService,
+				EventName:    event.EventName,
+				TxHash:       event.TxHash,
+				LogIndex:     event.LogIndex,
+				BlockNumber:  event.BlockNumber,
+				ContractAddr: event.ContractAddr,
+				PayloadRaw:   event.PayloadRaw,
+				Status:       model.DeadLetterStatusDead,
+			}
+		case err != nil:
+			return err
+		}
+		letter.Attempts = event.Attempts
+		letter.Error = errMsg
+		letter.Stack = stack
+		if err := tx.Save(&letter).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.EventLog{}).Where("id = ?", event.ID).Update("dead_lettered", true).Error
+	})
+	return &letter, err
+}
+
+// 按状态分页查询死信（默认最新在前），status 为空时返回全部
+func (r *Repository) ListDeadLetters(status string, page PageRequest) (*Page[model.DeadLetter], error) {
+	query := r.read().Model(&model.DeadLetter{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return paginate[model.DeadLetter](query, page, []SortKey{eventSortKeys[1], eventSortKeys[0]})
+}
+
+// 查询死信详情
+func (r *Repository) GetDeadLetter(id uint) (*model.DeadLetter, error) {
+	var letter model.DeadLetter
+	err := r.db.First(&letter, id).Error
+	return &letter, err
+}
+
+// 保存死信的状态、错误和尝试次数
+func (r *Repository) UpdateDeadLetter(letter *model.DeadLetter) error {
+	return r.db.Save(letter).Error
+}
+
+// 事件重新物化成功后，将其待处理的死信标记为已解决
+func (r *Repository) ResolveDeadLetters(eventLogID uint) error {
+	return r.db.Model(&model.DeadLetter{}).
+		Where("event_log_id = ? AND status = ?", eventLogID, model.DeadLetterStatusDead).
+		Update("status", model.DeadLetterStatusResolved).Error
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 插入影响力变化记录（按 tx_hash + log_index 去重）；事件未给出旧排名时取该用户上一条记录的新排名
+func (r *Repository) InsertInfluenceChange(change *model.InfluenceChange) error {
+	change.User = strings.ToLower(change.User)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if change.OldRank == "" {
+			var prev model.InfluenceChange
+			err := tx.Where("user_address = ? AND (block_number < ? OR (block_number = ? AND log_index < ?))",
+				change.User, change.BlockNumber, change.BlockNumber, change.LogIndex).
+				Order("block_number DESC, log_index DESC").Limit(1).Find(&prev).Error
+			if err != nil {
+				return err
+			}
+			change.OldRank = prev.NewRank
+		}
+		change.InfluenceKey = amountKey(change.NewInfluence)
+		if err := tx.FirstOrCreate(change, "tx_hash = ? AND log_index = ?", change.TxHash, change.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshLatestInfluence(tx, change.User)
+	})
+}
+
+// 分页查询用户的影响力变化历史（默认最新在前）
+func (r *Repository) ListInfluenceChanges(user string, page PageRequest) (*Page[model.InfluenceChange], error) {
+	query := r.read().Model(&model.InfluenceChange{}).Where("user_address = ?", strings.ToLower(user))
+	return paginate[model.InfluenceChange](query, page, eventSortKeys)
+}
+
+// 查询用户最新一条影响力变化记录
+func (r *Repository) GetLatestInfluenceChange(user string) (*model.InfluenceChange, error) {
+	var change model.InfluenceChange
+	err := r.read().Where("user_address = ?", strings.ToLower(user)).Order("block_number DESC, log_index DESC").First(&change).Error
+	return &change, err
+}
+
+// 分页查询每个用户最新一次 InfluenceUpdated 记录，默认按影响力从高到低排列（按排行榜索引键集分页）
+func (r *Repository) ListLatestInfluence(page PageRequest) (*Page[model.InfluenceChange], error) {
+	query := r.read().Model(&model.InfluenceChange{}).Where("is_latest = ?", true)
+	return paginate[model.InfluenceChange](query, page, leaderboardSortKeys)
+}
+
+// 插入影响力权重变更（按 tx_hash + log_index 去重）
+func (r *Repository) InsertInfluenceWeights(weights *model.InfluenceWeights) error {
+	return r.db.FirstOrCreate(weights, "tx_hash = ? AND log_index = ?", weights.TxHash, weights.LogIndex).Error
+}
+
+// 分页查询影响力权重变更记录（默认最新在前）
+func (r *Repository) ListInfluenceWeights(page PageRequest) (*Page[model.InfluenceWeights], error) {
+	return paginate[model.InfluenceWeights](r.read().Model(&model.InfluenceWeights{}), page, eventSortKeys)
+}
+
+// 查询当前生效的影响力权重（最新一条变更）
+func (r *Repository) GetLatestInfluenceWeights() (*model.InfluenceWeights, error) {
+	var weights model.InfluenceWeights
+	err := r.db.Order("block_number DESC, log_index DESC").First(&weights).Error
+	return &weights, err
+}
+
+// 插入排名刷新记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertRankingRefresh(refresh *model.RankingRefresh) error {
+	return r.db.FirstOrCreate(refresh, "tx_hash = ? AND log_index = ?", refresh.TxHash, refresh.LogIndex).Error
+}
+
+// 分页查询排名刷新记录（默认最新在前）
+func (r *Repository) ListRankingRefreshes(page PageRequest) (*Page[model.RankingRefresh], error) {
+	return paginate[model.RankingRefresh](r.read().Model(&model.RankingRefresh{}), page, eventSortKeys)
+}
+
+// 插入奖励发放记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertRewardDistribution(reward *model.RewardDistribution) error {
+	reward.User = strings.ToLower(reward.User)
+	return r.db.FirstOrCreate(reward, "tx_hash = ? AND log_index = ?", reward.TxHash, reward.LogIndex).Error
+}
+
+// 分页查询奖励流水（默认最新在前），user 为空时返回全部用户
+func (r *Repository) ListRewardDistributions(user string, page PageRequest) (*Page[model.RewardDistribution], error) {
+	query := r.read().Model(&model.RewardDistribution{})
+	if user != "" {
+		query = query.Where("user_address = ?", strings.ToLower(user))
+	}
+	return paginate[model.RewardDistribution](query, page, eventSortKeys)
+}
+
+// 查询用户的全部奖励流水（最新在前），用于汇总
+func (r *Repository) ListUserRewards(user string) ([]model.RewardDistribution, error) {
+	var rewards []model.RewardDistribution
+	err := r.read().Where("user_address = ?", strings.ToLower(user)).
+		Order("block_number DESC, log_index DESC").Find(&rewards).Error
+	return rewards, err
+}
+
+// 插入合作关系（按 tx_hash + log_index 去重）
+func (r *Repository) InsertCollaboration(collaboration *model.Collaboration) error {
+	collaboration.User1 = strings.ToLower(collaboration.User1)
+	collaboration.User2 = strings.ToLower(collaboration.User2)
+	return r.db.FirstOrCreate(collaboration, "tx_hash = ? AND log_index = ?", collaboration.TxHash, collaboration.LogIndex).Error
+}
+
+// 分页查询合作关系边（默认最新在前），user 非空时只返回与该用户相关的边
+func (r *Repository) ListCollaborations(user string, page PageRequest) (*Page[model.Collaboration], error) {
+	query := r.read().Model(&model.Collaboration{})
+	if user != "" {
+		user = strings.ToLower(user)
+		query = query.Where("user1 = ? OR user2 = ?", user, user)
+	}
+	return paginate[model.Collaboration](query, page, eventSortKeys)
+}
+
+// refreshLatestInfluence 将用户最新一条 InfluenceUpdated 记录标记为排行榜条目
+func refreshLatestInfluence(tx *gorm.DB, user string) error {
+	if err := tx.Model(&model.InfluenceChange{}).Where("user_address = ? AND is_latest = ?", user, true).
+		Update("is_latest", false).Error; err != nil {
+		return err
+	}
+	var latest model.InfluenceChange
+	err := tx.Where("user_address = ? AND event_name = ?", user, "InfluenceUpdated").
+		Order("block_number DESC, log_index DESC").Limit(1).Find(&latest).Error
+	if err != nil || latest.ID == 0 {
+		return err
+	}
+	return tx.Model(&latest).Update("is_latest", true).Error
+}
+
+// influenceUsersInRange 返回区块范围内有影响力记录的用户
+func influenceUsersInRange(tx *gorm.DB, blocks blockRange) ([]string, error) {
+	var users []string
+	err := tx.Model(&model.InfluenceChange{}).Scopes(blocks.scope).Distinct().Pluck("user_address", &users).Error
+	return users, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// nftToken 集合内的一个 token
+type nftToken struct {
+	Collection string
+	TokenID    string
+}
+
+// 记录一次 ERC-721 转移并更新当前持有人（按 tx_hash + log_index 去重）
+func (r *Repository) ApplyNFTTransfer(transfer *model.NFTTransfer) error {
+	transfer.FromAddress = strings.ToLower(transfer.FromAddress)
+	transfer.ToAddress = strings.ToLower(transfer.ToAddress)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(transfer, "tx_hash = ? AND log_index = ?", transfer.TxHash, transfer.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshNFTOwner(tx, transfer.Collection, transfer.TokenID)
+	})
+}
+
+// 查询 token 的当前持有人
+func (r *Repository) GetNFTOwner(collection, tokenID string) (*model.NFTOwner, error) {
+	var owner model.NFTOwner
+	err := r.db.Where("collection = ? AND token_id = ?", collection, tokenID).First(&owner).Error
+	return &owner, err
+}
+
+// 查询 token 的流转记录（按链上顺序，第一条为铸造）
+func (r *Repository) ListNFTTransfers(collection, tokenID string) ([]model.NFTTransfer, error) {
+	var transfers []model.NFTTransfer
+	err := r.db.Where("collection = ? AND token_id = ?", collection, tokenID).
+		Order("block_number ASC, log_index ASC").Find(&transfers).Error
+	return transfers, err
+}
+
+// 分页查询地址当前持有的 token（默认最近转入在前），collection 为空时返回全部集合
+func (r *Repository) ListNFTsByOwner(owner, collection string, page PageRequest) (*Page[model.NFTOwner], error) {
+	query := r.read().Model(&model.NFTOwner{}).Where("owner = ? AND burned = ?", strings.ToLower(owner), false)
+	if collection != "" {
+		query = query.Where("collection = ?", collection)
+	}
+	return paginate[model.NFTOwner](query, page, []SortKey{
+		{Name: "block", Columns: []string{"block_number", "collection", "token_id"}},
+		{Name: "updated", Columns: []string{"updated_at", "collection", "token_id"}},
+	})
+}
+
+// refreshNFTOwner 按最新一条转移记录重算持有人，并同步到研究成果/数据集记录
+func refreshNFTOwner(tx *gorm.DB, collection, tokenID string) error {
+	var latest model.NFTTransfer
+	err := tx.Where("collection = ? AND token_id = ?", collection, tokenID).
+		Order("block_number DESC, log_index DESC").First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Where("collection = ? AND token_id = ?", collection, tokenID).Delete(&model.NFTOwner{}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	owner := &model.NFTOwner{
+		Collection:  collection,
+		TokenID:     tokenID,
+		Owner:       latest.ToAddress,
+		Burned:      latest.ToAddress == model.ZeroAddress,
+		BlockNumber: latest.BlockNumber,
+	}
+	if err := tx.Save(owner).Error; err != nil {
+		return err
+	}
+
+	switch collection {
+	case model.CollectionResearch:
+		return tx.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).Update("owner", owner.Owner).Error
+	case model.CollectionDataset:
+		return tx.Model(&model.DatasetRecord{}).Where("dataset_id = ?", tokenID).Update("owner", owner.Owner).Error
+	}
+	return nil
+}
+
+// nftTokensInRange 返回区块范围内发生过转移的 token
+func nftTokensInRange(tx *gorm.DB, blocks blockRange) ([]nftToken, error) {
+	var tokens []nftToken
+	err := tx.Model(&model.NFTTransfer{}).Select("collection, token_id").
+		Scopes(blocks.scope).Distinct().Scan(&tokens).Error
+	return tokens, err
+}
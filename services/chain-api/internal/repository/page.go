@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPageSize 未指定 limit 时的每页条数
+	DefaultPageSize = 20
+	// MaxPageSize 单页最多返回的条数
+	MaxPageSize = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// PageRequest 列表查询的分页参数；Cursor 非空时沿用游标中记录的排序
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Order  string // asc 或 desc，默认 desc
+}
+
+// Page 列表接口统一的分页结果，NextCursor 为空表示没有下一页
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextCursor    string `json:"next_cursor"`
+	TotalEstimate int64  `json:"total_estimate"`
+}
+
+// SortKey 可选的排序字段；Columns 依次比较，须能唯一确定一行（通常以主键结尾）
+type SortKey struct {
+	Name    string
+	Columns []string
+}
+
+var (
+	// 链上事件记录：按区块内顺序或入库时间
+	eventSortKeys = []SortKey{
+		{Name: "block", Columns: []string{"block_number", "log_index", "id"}},
+		{Name: "created", Columns: []string{"created_at", "id"}},
+	}
+	// 没有 log_index 的实体记录
+	recordSortKeys = []SortKey{
+		{Name: "block", Columns: []string{"block_number", "id"}},
+		{Name: "created", Columns: []string{"created_at", "id"}},
+	}
+	// 排行榜按左补零的数额排名，数额相同时按主键
+	holderSortKeys      = []SortKey{{Name: "rank", Columns: []string{"balance_key", "holder"}}}
+	leaderboardSortKeys = []SortKey{{Name: "rank", Columns: []string{"influence_key", "id"}}}
+)
+
+// cursor 游标内容，base64 编码后对客户端不透明
+type cursor struct {
+	Sort   string            `json:"s"`
+	Desc   bool              `json:"d"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// FullPage 将不分页的小型目录包装为单页结果
+func FullPage[T any](items []T) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &Page[T]{Items: items, TotalEstimate: int64(len(items))}
+}
+
+func (p PageRequest) limit() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageSize
+	case p.Limit > MaxPageSize:
+		return MaxPageSize
+	}
+	return p.Limit
+}
+
+// resolve 确定排序字段和方向；带游标时以游标为准
+func (p PageRequest) resolve(keys []SortKey) (SortKey, bool, *cursor, error) {
+	var after *cursor
+	name, desc := p.Sort, true
+	if p.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+		if err != nil {
+			return SortKey{}, false, nil, ErrInvalidCursor
+		}
+		after = &cursor{}
+		if err := json.Unmarshal(raw, after); err != nil {
+			return SortKey{}, false, nil, ErrInvalidCursor
+		}
+		name, desc = after.Sort, after.Desc
+	} else {
+		switch p.Order {
+		case "", "desc":
+		case "asc":
+			desc = false
+		default:
+			return SortKey{}, false, nil, ErrInvalidSort
+		}
+	}
+
+	if name == "" {
+		return keys[0], desc, after, nil
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			if after != nil && len(after.Values) != len(key.Columns) {
+				return SortKey{}, false, nil, ErrInvalidCursor
+			}
+			return key, desc, after, nil
+		}
+	}
+	if after != nil {
+		return SortKey{}, false, nil, ErrInvalidCursor
+	}
+	return SortKey{}, false, nil, ErrInvalidSort
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// paginate 按键集游标分页：以 (排序列...) 与上一页最后一行比较，而不是 OFFSET 跳过；
+// 总数在同一只读连接上统计，副本延迟时可能略有偏差，因此称为估计值
+func paginate[T any](query *gorm.DB, page PageRequest, keys []SortKey) (*Page[T], error) {
+	key, desc, after, err := page.resolve(keys)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Session(&gorm.Session{})
+
+	var sample T
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(&sample); err != nil {
+		return nil, err
+	}
+	fields := make([]reflect.Type, len(key.Columns))
+	for i, column := range key.Columns {
+		field := stmt.Schema.LookUpField(columnName(column))
+		if field == nil {
+			return nil, ErrInvalidSort
+		}
+		fields[i] = field.FieldType
+	}
+
+	result := &Page[T]{Items: []T{}}
+	err = query.Session(&gorm.Session{NewDB: true}).Table("(?) AS page_rows", query).Count(&result.TotalEstimate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	direction, op := " DESC", "<"
+	if !desc {
+		direction, op = " ASC", ">"
+	}
+	order := make([]string, len(key.Columns))
+	for i, column := range key.Columns {
+		order[i] = column + direction
+	}
+	query = query.Order(strings.Join(order, ", "))
+
+	if after != nil {
+		args := make([]interface{}, len(fields))
+		for i, typ := range fields {
+			value := reflect.New(typ)
+			if err := json.Unmarshal(after.Values[i], value.Interface()); err != nil {
+				return nil, ErrInvalidCursor
+			}
+			args[i] = value.Elem().Interface()
+			// SQLite 按文本比较时间，需与写入时的本地时区格式一致
+			if t, ok := args[i].(time.Time); ok {
+				args[i] = t.Local()
+			}
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+		query = query.Where("("+strings.Join(key.Columns, ", ")+") "+op+" ("+placeholders+")", args...)
+	}
+
+	limit := page.limit()
+	if err := query.Limit(limit + 1).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(result.Items) <= limit {
+		return result, nil
+	}
+
+	result.Items = result.Items[:limit]
+	last := reflect.ValueOf(&result.Items[limit-1]).Elem()
+	next := cursor{Sort: key.Name, Desc: desc, Values: make([]json.RawMessage, len(key.Columns))}
+	for i, column := range key.Columns {
+		value, _ := stmt.Schema.LookUpField(columnName(column)).ValueOf(query.Statement.Context, last)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		next.Values[i] = raw
+	}
+	result.NextCursor = encodeCursor(next)
+	return result, nil
+}
+
+// columnName 去掉联表查询中的表名前缀
+func columnName(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
+
+// amountKeyWidth uint256 十进制表示的最大位数
+const amountKeyWidth = 78
+
+// amountKey 将十进制金额左补零到固定宽度，使按字符串排序与按数值排序一致；无效或负值按0处理
+func amountKey(amount string) string {
+	v := DecimalAmount(amount)
+	if v.Sign() < 0 {
+		v = new(big.Int)
+	}
+	digits := v.String()
+	if len(digits) >= amountKeyWidth {
+		return digits
+	}
+	return strings.Repeat("0", amountKeyWidth-len(digits)) + digits
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 写入提交的证明：proof_id 已存在时以事件中的字段覆盖，来自其他交易的旧记录同时清空链下校验结果，
+// 状态按已记录的验证结果重新计算
+func (r *Repository) InsertProof(proof *model.ZKProof) error {
+	proof.Submitter = strings.ToLower(proof.Submitter)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.ZKProof
+		err := tx.Where("proof_id = ?", proof.ProofID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(proof).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"submitter":     proof.Submitter,
+			"proof_type":    proof.ProofType,
+			"metadata_hash": proof.MetadataHash,
+			"submitted_at":  proof.SubmittedAt,
+			"block_number":  proof.BlockNumber,
+			"tx_hash":       proof.TxHash,
+		}
+		if existing.TxHash != proof.TxHash {
+			updates["proof_hash"] = ""
+			updates["check_status"] = ""
+			updates["check_reason"] = ""
+			updates["onchain_result"] = ""
+			updates["checked_at"] = nil
+			updates["trusted"] = false
+			updates["check_attempts"] = 0
+			updates["next_check_at"] = nil
+		}
+		if err := tx.Model(&model.ZKProof{}).Where("id = ?", existing.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := refreshProofStatus(tx, proof.ProofID); err != nil {
+			return err
+		}
+		return tx.First(proof, existing.ID).Error
+	})
+}
+
+// 查询证明
+func (r *Repository) GetProof(proofID string) (*model.ZKProof, error) {
+	var proof model.ZKProof
+	err := r.db.Where("proof_id = ?", proofID).First(&proof).Error
+	return &proof, err
+}
+
+// 更新证明的指定字段
+func (r *Repository) UpdateProof(proofID string, updates map[string]interface{}) error {
+	return r.db.Model(&model.ZKProof{}).Where("proof_id = ?", proofID).Updates(updates).Error
+}
+
+// 查询待校验的证明：从未校验过的优先（按提交顺序），其次是链上还没有验证记录的证明，按上次校验时间轮转；
+// 只返回由已确认的合约 ProofSubmitted 事件写入、已到下次核对时间且重试次数未达到 maxAttempts 的证明
+func (r *Repository) ListProofsToCheck(now time.Time, maxAttempts, limit int) ([]model.ZKProof, error) {
+	var proofs []model.ZKProof
+	indexed := r.db.Model(&model.EventLog{}).Select("1").
+		Where("event_logs.event_name = ? AND event_logs.entity_id = proofs.proof_id AND event_logs.tx_hash = proofs.tx_hash", "ProofSubmitted").
+		Where("event_logs.status = ? AND event_logs.contract_addr NOT IN ?", model.EventStatusConfirmed, []string{"", model.ZeroAddress})
+	query := r.db.
+		Where("EXISTS (?)", indexed).
+		Where("check_status = '' OR check_status IS NULL OR onchain_result = ?", model.ProofCheckUnknown).
+		Where("check_attempts < ? AND (next_check_at IS NULL OR next_check_at <= ?)", maxAttempts, now).
+		Order("CASE WHEN checked_at IS NULL THEN 0 ELSE 1 END, checked_at ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&proofs).Error
+	return proofs, err
+}
+
+// 分页查询证明列表，可按状态和证明类型过滤（默认最新在前）
+func (r *Repository) ListProofs(status, proofType string, page PageRequest) (*Page[model.ZKProof], error) {
+	query := r.read().Model(&model.ZKProof{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if proofType != "" {
+		query = query.Where("proof_type = ?", proofType)
+	}
+	return paginate[model.ZKProof](query, page, recordSortKeys)
+}
+
+// 分页查询地址提交的证明（默认最新在前）
+func (r *Repository) ListProofsBySubmitter(submitter string, page PageRequest) (*Page[model.ZKProof], error) {
+	query := r.read().Model(&model.ZKProof{}).Where("submitter = ?", strings.ToLower(submitter))
+	return paginate[model.ZKProof](query, page, recordSortKeys)
+}
+
+// 记录一次验证结果（按 tx_hash + log_index 去重），ZKProof 的结果同步到证明状态
+func (r *Repository) InsertProofVerification(verification *model.ProofVerification) error {
+	verification.Verifier = strings.ToLower(verification.Verifier)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(verification, "tx_hash = ? AND log_index = ?", verification.TxHash, verification.LogIndex).Error; err != nil {
+			return err
+		}
+		if verification.ProofID == "" {
+			return nil
+		}
+		return refreshProofStatus(tx, verification.ProofID)
+	})
+}
+
+// 查询证明的验证记录（按链上顺序）
+func (r *Repository) ListProofVerifications(proofID string) ([]model.ProofVerification, error) {
+	var verifications []model.ProofVerification
+	err := r.db.Where("proof_id = ?", proofID).Order("block_number ASC, log_index ASC").Find(&verifications).Error
+	return verifications, err
+}
+
+// 查询证明类型
+func (r *Repository) GetProofType(name string) (*model.ProofType, error) {
+	var proofType model.ProofType
+	err := r.db.Where("name = ?", name).First(&proofType).Error
+	return &proofType, err
+}
+
+// 保存证明类型（新建或覆盖）
+func (r *Repository) SaveProofType(proofType *model.ProofType) error {
+	return r.db.Save(proofType).Error
+}
+
+// 查询全部证明类型
+func (r *Repository) ListProofTypes() ([]model.ProofType, error) {
+	var types []model.ProofType
+	err := r.db.Order("name ASC").Find(&types).Error
+	return types, err
+}
+
+// refreshProofStatus 按最新一条 ZKProof 验证记录重算证明状态
+func refreshProofStatus(tx *gorm.DB, proofID string) error {
+	updates := map[string]interface{}{
+		"status":         model.ProofStatusSubmitted,
+		"verifier":       "",
+		"verified_at":    nil,
+		"verified_block": 0,
+	}
+
+	var latest model.ProofVerification
+	err := tx.Where("proof_id = ? AND source = ?", proofID, model.ProofSourceZKProof).
+		Order("block_number DESC, log_index DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		updates["status"] = model.ProofStatusRejected
+		if latest.IsValid {
+			updates["status"] = model.ProofStatusVerified
+		}
+		updates["verifier"] = latest.Verifier
+		updates["verified_at"] = latest.VerifiedAt
+		updates["verified_block"] = latest.BlockNumber
+	}
+	return tx.Model(&model.ZKProof{}).Where("proof_id = ?", proofID).Updates(updates).Error
+}
+
+// proofsVerifiedInRange 返回区块范围内被验证过的证明
+func proofsVerifiedInRange(tx *gorm.DB, blocks blockRange) ([]string, error) {
+	var ids []string
+	err := tx.Model(&model.ProofVerification{}).Scopes(blocks.scope).Where("proof_id <> ''").
+		Distinct().Pluck("proof_id", &ids).Error
+	return ids, err
+}
+
+// rebuildProofTypes 链重组回滚后，用剩余的已确认事件重建在分叉点之后更新过的证明类型
+func rebuildProofTypes(tx *gorm.DB, number uint64) error {
+	var stale []model.ProofType
+	if err := tx.Where("block_number >= ?", number).Find(&stale).Error; err != nil {
+		return err
+	}
+
+	for _, old := range stale {
+		var events []model.EventLog
+		err := tx.Where("event_name IN ? AND entity_id = ? AND status = ?", model.ProofTypeEvents, old.Name, model.EventStatusConfirmed).
+			Order("block_number ASC, log_index ASC").Find(&events).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("name = ?", old.Name).Delete(&model.ProofType{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		proofType := &model.ProofType{Name: old.Name}
+		for _, event := range events {
+			var payload model.ProofEventPayload
+			if err := json.Unmarshal([]byte(event.PayloadRaw), &payload); err != nil {
+				return err
+			}
+			proofType.Apply(event.EventName, &payload, event.BlockNumber)
+		}
+		if err := tx.Create(proofType).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 插入数据质量指标（按 tx_hash + log_index 去重），并刷新对应的质量投影
+func (r *Repository) InsertDataQualityMetric(metric *model.DataQualityMetric) error {
+	metric.Submitter = strings.ToLower(metric.Submitter)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(metric, "tx_hash = ? AND log_index = ?", metric.TxHash, metric.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshDataQuality(tx, metric.Source, metric.SubjectID)
+	})
+}
+
+// 查询数据质量投影
+func (r *Repository) GetDataQuality(source, subjectID string) (*model.DataQuality, error) {
+	var quality model.DataQuality
+	err := r.db.Where("source = ? AND subject_id = ?", source, subjectID).First(&quality).Error
+	return &quality, err
+}
+
+// 查询与数据集关联的质量投影：数据哈希相同，或指标与数据集事件在同一交易中产生
+func (r *Repository) ListDatasetDataQuality(datasetID, dataHash string) ([]model.DataQuality, error) {
+	var qualities []model.DataQuality
+	txs := r.read().Model(&model.EventLog{}).Select("tx_hash").
+		Where("entity_id = ? AND event_name IN ? AND status = ?", datasetID, model.DatasetEvents, model.EventStatusConfirmed)
+	subjects := r.read().Model(&model.DataQualityMetric{}).Select("subject_id").Where("tx_hash IN (?)", txs)
+	query := r.read().Where("subject_id IN (?)", subjects)
+	if dataHash != "" {
+		query = query.Or("data_hash = ?", dataHash)
+	}
+	err := query.Order("block_number DESC").Find(&qualities).Error
+	return qualities, err
+}
+
+// 查询指标时间序列（最新在前）
+func (r *Repository) ListDataQualityMetrics(source, subjectID string, limit int) ([]model.DataQualityMetric, error) {
+	var metrics []model.DataQualityMetric
+	query := r.read().Where("source = ? AND subject_id = ?", source, subjectID).Order("block_number DESC, log_index DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&metrics).Error
+	return metrics, err
+}
+
+// 插入验证器约束阈值（按 tx_hash + log_index 去重）
+func (r *Repository) InsertVerifierConstraint(constraint *model.VerifierConstraint) error {
+	return r.db.FirstOrCreate(constraint, "tx_hash = ? AND log_index = ?", constraint.TxHash, constraint.LogIndex).Error
+}
+
+// 查询每种约束类型最新的阈值
+func (r *Repository) ListVerifierConstraints() ([]model.VerifierConstraint, error) {
+	var all []model.VerifierConstraint
+	if err := r.db.Order("block_number DESC, log_index DESC").Find(&all).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	constraints := make([]model.VerifierConstraint, 0, len(all))
+	for _, c := range all {
+		if seen[c.ConstraintType] {
+			continue
+		}
+		seen[c.ConstraintType] = true
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// refreshDataQuality 按剩余的指标记录重新合并质量投影，没有记录时删除投影
+func refreshDataQuality(tx *gorm.DB, source, subjectID string) error {
+	var metrics []model.DataQualityMetric
+	err := tx.Where("source = ? AND subject_id = ?", source, subjectID).
+		Order("block_number ASC, log_index ASC").Find(&metrics).Error
+	if err != nil {
+		return err
+	}
+
+	var quality model.DataQuality
+	if err := tx.Where("source = ? AND subject_id = ?", source, subjectID).Limit(1).Find(&quality).Error; err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		if quality.ID == 0 {
+			return nil
+		}
+		return tx.Delete(&quality).Error
+	}
+
+	rebuilt := model.DataQuality{ID: quality.ID, Source: source, SubjectID: subjectID}
+	for i := range metrics {
+		rebuilt.Apply(&metrics[i])
+	}
+	return tx.Save(&rebuilt).Error
+}
+
+// dataQualityInRange 返回区块范围内有指标记录的数据/特征
+func dataQualityInRange(tx *gorm.DB, blocks blockRange) ([]model.DataQualityMetric, error) {
+	var subjects []model.DataQualityMetric
+	err := tx.Model(&model.DataQualityMetric{}).Scopes(blocks.scope).
+		Distinct("source", "subject_id").Find(&subjects).Error
+	return subjects, err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 查询可以重试的事件：已确认、未处理、未进入死信队列且已到下次重试时间（按链上顺序），now 为零值时忽略重试时间
+func (r *Repository) GetRetryableEvents(eventNames []string, now time.Time, limit int) ([]model.EventLog, error) {
+	var events []model.EventLog
+	query := r.db.Where("processed = ? AND status = ? AND dead_lettered = ? AND event_name IN ?", false, model.EventStatusConfirmed, false, eventNames)
+	if !now.IsZero() {
+		query = query.Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now)
+	}
+	query = query.Order("block_number ASC, log_index ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&events).Error
+	return events, err
+}
+
+// 以租约领取待物化的事件：在同一条 UPDATE 中把 next_attempt_at 推迟到 leaseUntil，领取成功时返回 true。
+// 条件更新保证同一事件同一时间只有一个调用方物化；now 为零值时忽略重试时间（强制领取）
+func (r *Repository) ClaimEvent(eventID uint, now, leaseUntil time.Time) (bool, error) {
+	query := r.db.Model(&model.EventLog{}).Where("id = ? AND processed = ?", eventID, false)
+	if !now.IsZero() {
+		query = query.Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now)
+	}
+	result := query.Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+// 记录一次物化失败：累加尝试次数，保存错误信息和下次重试时间
+func (r *Repository) RecordEventFailure(eventID uint, errMsg string, nextAttempt time.Time) error {
+	return r.db.Model(&model.EventLog{}).Where("id = ?", eventID).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      errMsg,
+		"next_attempt_at": nextAttempt,
+	}).Error
+}
@@ -2,12 +2,13 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
+	"desci-backend/internal/db"
 	"desci-backend/internal/model"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
+	"desci-backend/migrations"
 	"gorm.io/gorm"
 )
 
@@ -19,31 +20,164 @@ type IRepository interface {
 	// Research data operations
 	InsertResearchData(data *model.ResearchData) error
 	GetResearchData(tokenID string) (*model.ResearchData, error)
-	ListResearchDataByAuthor(author string, limit int) ([]*model.ResearchData, error)
+	ListResearchDataByAuthor(author string, page PageRequest) (*Page[model.AuthoredResearch], error)
 	UpdateResearchData(tokenID string, updates map[string]interface{}) error
 
+	// Research activity operations
+	InsertResearchCitation(citation *model.ResearchCitation) error
+	ListResearchCitedBy(tokenID string, page PageRequest) (*Page[model.ResearchCitation], error)
+	ListResearchReferences(tokenID string, page PageRequest) (*Page[model.ResearchCitation], error)
+	InsertResearchReview(review *model.ResearchReview) error
+	ListResearchReviews(tokenID string, page PageRequest) (*Page[model.ResearchReview], error)
+	InsertResearchImpactChange(change *model.ResearchImpactChange) error
+	ListResearchImpactChanges(tokenID string, page PageRequest) (*Page[model.ResearchImpactChange], error)
+	GetLatestResearchImpactChange(tokenID string) (*model.ResearchImpactChange, error)
+	InsertResearchRevenue(revenue *model.ResearchRevenue) error
+	ListResearchRevenue(tokenID string, page PageRequest) (*Page[model.ResearchRevenue], error)
+	SumResearchRevenue(tokenID string) (string, error)
+	InsertResearchMetadataUpdate(update *model.ResearchMetadataUpdate) error
+
+	// NFT ownership operations
+	ApplyNFTTransfer(transfer *model.NFTTransfer) error
+	GetNFTOwner(collection, tokenID string) (*model.NFTOwner, error)
+	ListNFTTransfers(collection, tokenID string) ([]model.NFTTransfer, error)
+	ListNFTsByOwner(owner, collection string, page PageRequest) (*Page[model.NFTOwner], error)
+
+	// Proof operations
+	InsertProof(proof *model.ZKProof) error
+	GetProof(proofID string) (*model.ZKProof, error)
+	ListProofs(status, proofType string, page PageRequest) (*Page[model.ZKProof], error)
+	ListProofsBySubmitter(submitter string, page PageRequest) (*Page[model.ZKProof], error)
+	UpdateProof(proofID string, updates map[string]interface{}) error
+	ListProofsToCheck(now time.Time, maxAttempts, limit int) ([]model.ZKProof, error)
+	InsertProofVerification(verification *model.ProofVerification) error
+	ListProofVerifications(proofID string) ([]model.ProofVerification, error)
+	GetProofType(name string) (*model.ProofType, error)
+	SaveProofType(proofType *model.ProofType) error
+	ListProofTypes() ([]model.ProofType, error)
+
+	// Constraint operations
+	GetConstraint(constraintID string) (*model.Constraint, error)
+	SaveConstraint(constraint *model.Constraint) error
+	ListConstraints(category string, activeOnly bool) ([]model.Constraint, error)
+	InsertConstraintGroup(group *model.ConstraintGroup) error
+	ListConstraintGroups() ([]model.ConstraintGroup, error)
+	InsertValidationRule(rule *model.ValidationRule) error
+	ListValidationRules() ([]model.ValidationRule, error)
+	InsertConstraintEvaluation(evaluation *model.ConstraintEvaluation) error
+	ListConstraintEvaluations(constraintID string, limit int) ([]model.ConstraintEvaluation, error)
+	ListDatasetConstraintEvaluations(datasetID string, page PageRequest) (*Page[model.ConstraintEvaluation], error)
+	ListLatestDatasetConstraintResults(datasetID string) (map[string]bool, error)
+
+	// Data quality operations
+	InsertDataQualityMetric(metric *model.DataQualityMetric) error
+	GetDataQuality(source, subjectID string) (*model.DataQuality, error)
+	ListDatasetDataQuality(datasetID, dataHash string) ([]model.DataQuality, error)
+	ListDataQualityMetrics(source, subjectID string, limit int) ([]model.DataQualityMetric, error)
+	InsertVerifierConstraint(constraint *model.VerifierConstraint) error
+	ListVerifierConstraints() ([]model.VerifierConstraint, error)
+
+	// Influence and reward operations
+	InsertInfluenceChange(change *model.InfluenceChange) error
+	ListInfluenceChanges(user string, page PageRequest) (*Page[model.InfluenceChange], error)
+	GetLatestInfluenceChange(user string) (*model.InfluenceChange, error)
+	ListLatestInfluence(page PageRequest) (*Page[model.InfluenceChange], error)
+	InsertInfluenceWeights(weights *model.InfluenceWeights) error
+	ListInfluenceWeights(page PageRequest) (*Page[model.InfluenceWeights], error)
+	GetLatestInfluenceWeights() (*model.InfluenceWeights, error)
+	InsertRankingRefresh(refresh *model.RankingRefresh) error
+	ListRankingRefreshes(page PageRequest) (*Page[model.RankingRefresh], error)
+	InsertRewardDistribution(reward *model.RewardDistribution) error
+	ListRewardDistributions(user string, page PageRequest) (*Page[model.RewardDistribution], error)
+	ListUserRewards(user string) ([]model.RewardDistribution, error)
+	InsertCollaboration(collaboration *model.Collaboration) error
+	ListCollaborations(user string, page PageRequest) (*Page[model.Collaboration], error)
+
+	// SciToken ledger operations
+	ApplyTokenTransfer(transfer *model.TokenTransfer) error
+	GetTokenBalance(holder string) (*model.TokenBalance, error)
+	ListTokenHolders(page PageRequest) (*Page[model.TokenBalance], error)
+	ListTokenTransfers(address string, page PageRequest) (*Page[model.TokenTransfer], error)
+	GetTokenSupply() (*model.TokenSupply, error)
+	InsertTokenApproval(approval *model.TokenApproval) error
+	ListTokenAllowances(owner string) ([]model.TokenApproval, error)
+
 	// Dataset operations
 	InsertDatasetRecord(record *model.DatasetRecord) error
 	GetDatasetRecord(datasetID string) (*model.DatasetRecord, error)
-	ListDatasetsByOwner(owner string, limit int) ([]*model.DatasetRecord, error)
+	ListDatasetsByOwner(owner string, page PageRequest) (*Page[model.DatasetRecord], error)
 	UpdateDatasetRecord(datasetID string, updates map[string]interface{}) error
 
+	// Dataset activity operations
+	InsertDatasetAccess(access *model.DatasetAccess) error
+	ListDatasetAccesses(datasetID string, page PageRequest) (*Page[model.DatasetAccess], error)
+	InsertDatasetCitation(citation *model.DatasetCitation) error
+	ListDatasetCitations(datasetID string, page PageRequest) (*Page[model.DatasetCitation], error)
+	InsertDatasetQualityChange(change *model.DatasetQualityChange) error
+	ListDatasetQualityChanges(datasetID string, page PageRequest) (*Page[model.DatasetQualityChange], error)
+	GetLatestDatasetQualityChange(datasetID string) (*model.DatasetQualityChange, error)
+	InsertDatasetRevenue(revenue *model.DatasetRevenue) error
+	ListDatasetRevenue(datasetID string) ([]model.DatasetRevenue, error)
+
 	// Extended query operations
-	GetLatestResearchData(limit, offset int) ([]*model.ResearchData, error)
+	GetLatestResearchData(page PageRequest) (*Page[model.ResearchData], error)
 	GetLastEventBlock() (uint64, error)
 
+	// Search operations
+	Search(filter SearchFilter, page PageRequest) (*Page[model.SearchResult], error)
+
 	// Event log operations
 	InsertEventLog(log *model.EventLog) error
 	GetUnprocessedEvents() ([]model.EventLog, error)
+	GetEventLog(id uint) (*model.EventLog, error)
 	MarkEventProcessed(eventID uint) error
 	GetEventsByBlockRange(fromBlock, toBlock uint64) ([]model.EventLog, error)
 
+	// Replay operations
+	GetRetryableEvents(eventNames []string, now time.Time, limit int) ([]model.EventLog, error)
+	ClaimEvent(eventID uint, now, leaseUntil time.Time) (bool, error)
+	RecordEventFailure(eventID uint, errMsg string, nextAttempt time.Time) error
+	ResetBlockRange(fromBlock, toBlock uint64) (int64, error)
+	RefreshProjections(fromBlock uint64) error
+
+	// Dead letter operations
+	InsertDeadLetter(letter *model.DeadLetter) error
+	DeadLetterEvent(event *model.EventLog, errMsg, stack string) (*model.DeadLetter, error)
+	ListDeadLetters(status string, page PageRequest) (*Page[model.DeadLetter], error)
+	GetDeadLetter(id uint) (*model.DeadLetter, error)
+	UpdateDeadLetter(letter *model.DeadLetter) error
+	ResolveDeadLetters(eventLogID uint) error
+
+	// User profile operations
+	GetUserProfile(address string) (*model.UserProfile, error)
+	SaveUserProfile(profile *model.UserProfile) error
+	InsertReputationChange(change *model.ReputationChange) error
+	ListReputationChanges(address string, limit int) ([]model.ReputationChange, error)
+
+	// Confirmation operations
+	GetPendingEvents(maxBlock uint64) ([]model.EventLog, error)
+	ListPendingEvents(page PageRequest) (*Page[model.EventLog], error)
+	GetPendingEventByEntity(eventName, entityID string) (*model.EventLog, error)
+	MarkEventConfirmed(eventID uint) error
+
+	// Chain reorg operations
+	GetIndexedBlock(number uint64) (*model.IndexedBlock, error)
+	GetLatestIndexedBlockBefore(number uint64) (*model.IndexedBlock, error)
+	SaveIndexedBlock(block *model.IndexedBlock) error
+	RollbackFromBlock(number uint64) error
+
+	// Sync cursor operations
+	GetSyncCursors() ([]model.SyncCursor, error)
+	SaveSyncCursor(contractAddr string, lastBlock uint64) error
+
 	// Health check
 	Ping(ctx context.Context) error
 }
 
 type Repository struct {
 	db *gorm.DB
+	// reader 只读副本，承担列表类查询；为空时使用 db
+	reader *gorm.DB
 }
 
 // 确保Repository实现了IRepository接口
@@ -54,31 +188,83 @@ func NewTestRepository(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func NewRepository(databaseURL string) (*Repository, error) {
-	var dialector gorm.Dialector
+// NewRepository 通过 db 连接层打开主库（应用未执行的迁移）和可选的只读副本
+func NewRepository(cfg *db.Config) (*Repository, error) {
+	ctx := context.Background()
+	primary, err := db.Open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// 根据URL选择合适的数据库驱动
-	if strings.HasPrefix(databaseURL, "sqlite://") || strings.HasPrefix(databaseURL, "sqlite:") {
-		// SQLite
-		dbPath := strings.TrimPrefix(databaseURL, "sqlite://")
-		dbPath = strings.TrimPrefix(dbPath, "sqlite:")
-		dialector = sqlite.Open(dbPath)
-	} else {
-		// PostgreSQL (默认)
-		dialector = postgres.Open(databaseURL)
+	// 应用未执行的版本化迁移
+	if err := Migrate(primary); err != nil {
+		return nil, err
 	}
 
-	db, err := gorm.Open(dialector, &gorm.Config{})
+	replica, err := db.OpenReplica(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("open read replica: %w", err)
 	}
 
-	// 自动迁移
-	if err := db.AutoMigrate(&model.ResearchData{}, &model.DatasetRecord{}, &model.EventLog{}); err != nil {
-		return nil, err
+	return &Repository{db: primary, reader: replica}, nil
+}
+
+// read 列表查询使用的连接：配置了只读副本时走副本（可能略有延迟），事务内始终走当前事务
+func (r *Repository) read() *gorm.DB {
+	if r.reader != nil {
+		return r.reader
+	}
+	return r.db
+}
+
+// Migrate 应用 migrations 目录中对应方言的全部未执行迁移，并建立 SQLite 的全文检索索引
+func Migrate(gormDB *gorm.DB) error {
+	if err := db.RunMigrations(context.Background(), gormDB, migrations.FS); err != nil {
+		return err
 	}
+	return ensureSearchIndex(gormDB)
+}
 
-	return &Repository{db: db}, nil
+// schemaModels repository 使用的全部模型；迁移后的表结构需与其一致（由测试校验）
+var schemaModels = []interface{}{
+	&model.ResearchData{},
+	&model.ResearchAuthor{},
+	&model.DatasetRecord{},
+	&model.EventLog{},
+	&model.IndexedBlock{},
+	&model.SyncCursor{},
+	&model.UserProfile{},
+	&model.ReputationChange{},
+	&model.DatasetAccess{},
+	&model.DatasetCitation{},
+	&model.DatasetQualityChange{},
+	&model.DatasetRevenue{},
+	&model.ResearchCitation{},
+	&model.ResearchReview{},
+	&model.ResearchImpactChange{},
+	&model.ResearchRevenue{},
+	&model.ResearchMetadataUpdate{},
+	&model.NFTTransfer{},
+	&model.NFTOwner{},
+	&model.ZKProof{},
+	&model.ProofVerification{},
+	&model.ProofType{},
+	&model.Constraint{},
+	&model.ConstraintGroup{},
+	&model.ValidationRule{},
+	&model.ConstraintEvaluation{},
+	&model.DataQuality{},
+	&model.DataQualityMetric{},
+	&model.VerifierConstraint{},
+	&model.InfluenceChange{},
+	&model.InfluenceWeights{},
+	&model.RankingRefresh{},
+	&model.RewardDistribution{},
+	&model.Collaboration{},
+	&model.TokenTransfer{},
+	&model.TokenBalance{},
+	&model.TokenApproval{},
+	&model.DeadLetter{},
 }
 
 // WithTx 执行事务操作
@@ -89,9 +275,16 @@ func (r *Repository) WithTx(ctx context.Context, fn func(tx IRepository) error)
 	})
 }
 
-// 插入研究数据（幂等）
+// 插入研究数据（幂等），新建时同步署名作者索引
 func (r *Repository) InsertResearchData(data *model.ResearchData) error {
-	return r.db.FirstOrCreate(data, "token_id = ?", data.TokenID).Error
+	data.Owner = strings.ToLower(data.Owner)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.FirstOrCreate(data, "token_id = ?", data.TokenID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return syncResearchAuthors(tx, data.TokenID, data.Authors, data.BlockNumber)
+	})
 }
 
 // 查询研究数据
@@ -101,26 +294,103 @@ func (r *Repository) GetResearchData(tokenID string) (*model.ResearchData, error
 	return &data, err
 }
 
-// 根据作者查询研究数据
-func (r *Repository) ListResearchDataByAuthor(author string, limit int) ([]*model.ResearchData, error) {
-	var data []*model.ResearchData
-	// 使用LIKE查询兼容SQLite和PostgreSQL
-	query := r.db.Where("authors LIKE ?", "%\""+author+"\"%")
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-	err := query.Find(&data).Error
-	return data, err
+// 按作者地址分页查询研究成果（默认最新区块在前），同时返回作者的署名顺序
+func (r *Repository) ListResearchDataByAuthor(author string, page PageRequest) (*Page[model.AuthoredResearch], error) {
+	query := r.read().Model(&model.ResearchData{}).
+		Select("research_data.*, research_authors.position AS author_position").
+		Joins("JOIN research_authors ON research_authors.token_id = research_data.token_id").
+		Where("research_authors.author = ?", strings.ToLower(author))
+	return paginate[model.AuthoredResearch](query, page, []SortKey{
+		{Name: "block", Columns: []string{"research_data.block_number", "research_data.id"}},
+		{Name: "created", Columns: []string{"research_data.created_at", "research_data.id"}},
+	})
 }
 
 // 更新研究数据
 func (r *Repository) UpdateResearchData(tokenID string, updates map[string]interface{}) error {
 	updates["updated_at"] = time.Now()
-	return r.db.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).Updates(updates).Error
+	lowerOwner(updates)
+	if _, ok := updates["authors"]; !ok {
+		return r.db.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).Updates(updates).Error
+	}
+
+	// 作者变更时同步署名作者索引
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).Updates(updates).Error; err != nil {
+			return err
+		}
+		var data model.ResearchData
+		if err := tx.Where("token_id = ?", tokenID).First(&data).Error; err != nil {
+			return err
+		}
+		return syncResearchAuthors(tx, tokenID, data.Authors, data.BlockNumber)
+	})
+}
+
+// syncResearchAuthors 按署名顺序重建研究成果的作者索引；地址转小写，重复署名只保留第一次出现的位置
+func syncResearchAuthors(tx *gorm.DB, tokenID string, authors []string, blockNumber uint64) error {
+	if err := tx.Where("token_id = ?", tokenID).Delete(&model.ResearchAuthor{}).Error; err != nil {
+		return err
+	}
+
+	rows := make([]model.ResearchAuthor, 0, len(authors))
+	seen := make(map[string]bool, len(authors))
+	for i, author := range authors {
+		author = strings.ToLower(strings.TrimSpace(author))
+		if author == "" || seen[author] {
+			continue
+		}
+		seen[author] = true
+		rows = append(rows, model.ResearchAuthor{TokenID: tokenID, Author: author, Position: i, BlockNumber: blockNumber})
+	}
+	if len(rows) > 0 {
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	return refreshResearchAuthorsText(tx, tokenID)
+}
+
+// refreshResearchAuthorsText 按署名顺序拼接作者的显示名称（来自用户档案）和地址，作为研究成果的检索文本
+func refreshResearchAuthorsText(tx *gorm.DB, tokenID string) error {
+	var authors []struct {
+		Author string
+		Name   string
+	}
+	err := tx.Model(&model.ResearchAuthor{}).
+		Select("research_authors.author, COALESCE(user_profiles.name, '') AS name").
+		Joins("LEFT JOIN user_profiles ON user_profiles.wallet_address = research_authors.author").
+		Where("research_authors.token_id = ?", tokenID).
+		Order("research_authors.position").Scan(&authors).Error
+	if err != nil {
+		return err
+	}
+
+	entries := make([]string, len(authors))
+	for i, a := range authors {
+		entries[i] = strings.TrimSpace(a.Name + " " + a.Author)
+	}
+	return tx.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).
+		UpdateColumn("authors_text", strings.Join(entries, ", ")).Error
+}
+
+// refreshAuthoredResearchText 用户档案变更后，刷新该地址署名的全部研究成果的检索文本
+func refreshAuthoredResearchText(tx *gorm.DB, address string) error {
+	var tokenIDs []string
+	if err := tx.Model(&model.ResearchAuthor{}).Where("author = ?", address).Pluck("token_id", &tokenIDs).Error; err != nil {
+		return err
+	}
+	for _, tokenID := range tokenIDs {
+		if err := refreshResearchAuthorsText(tx, tokenID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // 插入数据集记录（幂等）
 func (r *Repository) InsertDatasetRecord(record *model.DatasetRecord) error {
+	record.Owner = strings.ToLower(record.Owner)
 	return r.db.FirstOrCreate(record, "dataset_id = ?", record.DatasetID).Error
 }
 
@@ -131,23 +401,30 @@ func (r *Repository) GetDatasetRecord(datasetID string) (*model.DatasetRecord, e
 	return &record, err
 }
 
-// 根据拥有者查询数据集
-func (r *Repository) ListDatasetsByOwner(owner string, limit int) ([]*model.DatasetRecord, error) {
-	var records []*model.DatasetRecord
-	query := r.db.Where("owner = ?", owner)
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-	err := query.Find(&records).Error
-	return records, err
+// 根据拥有者分页查询数据集（默认最新创建在前）
+func (r *Repository) ListDatasetsByOwner(owner string, page PageRequest) (*Page[model.DatasetRecord], error) {
+	query := r.read().Model(&model.DatasetRecord{}).Where("owner = ?", strings.ToLower(owner))
+	return paginate[model.DatasetRecord](query, page, []SortKey{
+		{Name: "created", Columns: []string{"created_at", "id"}},
+		{Name: "block", Columns: []string{"block_number", "id"}},
+		{Name: "updated", Columns: []string{"updated_at", "id"}},
+	})
 }
 
 // 更新数据集记录
 func (r *Repository) UpdateDatasetRecord(datasetID string, updates map[string]interface{}) error {
 	updates["updated_at"] = time.Now()
+	lowerOwner(updates)
 	return r.db.Model(&model.DatasetRecord{}).Where("dataset_id = ?", datasetID).Updates(updates).Error
 }
 
+// lowerOwner 持有人地址统一以小写存储，与 Transfer 投影和按持有人查询一致
+func lowerOwner(updates map[string]interface{}) {
+	if owner, ok := updates["owner"].(string); ok {
+		updates["owner"] = strings.ToLower(owner)
+	}
+}
+
 // 插入事件日志（去重）
 func (r *Repository) InsertEventLog(log *model.EventLog) error {
 	// 使用复合键确保幂等性
@@ -161,9 +438,17 @@ func (r *Repository) GetUnprocessedEvents() ([]model.EventLog, error) {
 	return events, err
 }
 
-// 标记事件为已处理
+// 按ID查询事件日志
+func (r *Repository) GetEventLog(id uint) (*model.EventLog, error) {
+	var event model.EventLog
+	err := r.db.First(&event, id).Error
+	return &event, err
+}
+
+// 标记事件为已处理，并清除重试和死信状态
 func (r *Repository) MarkEventProcessed(eventID uint) error {
-	return r.db.Model(&model.EventLog{}).Where("id = ?", eventID).Update("processed", true).Error
+	return r.db.Model(&model.EventLog{}).Where("id = ?", eventID).
+		Updates(map[string]interface{}{"processed": true, "last_error": "", "next_attempt_at": nil, "dead_lettered": false}).Error
 }
 
 // 按区块范围查询事件
@@ -174,27 +459,54 @@ func (r *Repository) GetEventsByBlockRange(fromBlock, toBlock uint64) ([]model.E
 	return events, err
 }
 
+// 查询已达到确认高度的待确认事件（按链上顺序）
+func (r *Repository) GetPendingEvents(maxBlock uint64) ([]model.EventLog, error) {
+	var events []model.EventLog
+	err := r.db.Where("status = ? AND block_number <= ?", model.EventStatusPending, maxBlock).
+		Order("block_number ASC, log_index ASC").Find(&events).Error
+	return events, err
+}
+
+// 分页列出待确认事件（默认最新区块在前）
+func (r *Repository) ListPendingEvents(page PageRequest) (*Page[model.EventLog], error) {
+	query := r.read().Model(&model.EventLog{}).Where("status = ?", model.EventStatusPending)
+	return paginate[model.EventLog](query, page, eventSortKeys)
+}
+
+// 查询某实体最近的待确认事件
+func (r *Repository) GetPendingEventByEntity(eventName, entityID string) (*model.EventLog, error) {
+	var event model.EventLog
+	err := r.db.Where("status = ? AND event_name = ? AND entity_id = ?", model.EventStatusPending, eventName, entityID).
+		Order("block_number DESC").First(&event).Error
+	return &event, err
+}
+
+// 标记事件为已确认
+func (r *Repository) MarkEventConfirmed(eventID uint) error {
+	return r.db.Model(&model.EventLog{}).Where("id = ?", eventID).Update("status", model.EventStatusConfirmed).Error
+}
+
 // 健康检查
 func (r *Repository) Ping(ctx context.Context) error {
-	db, err := r.db.DB()
-	if err != nil {
-		return err
+	for _, conn := range []*gorm.DB{r.db, r.reader} {
+		if conn == nil {
+			continue
+		}
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return err
+		}
 	}
-	return db.PingContext(ctx)
+	return nil
 }
 
-// 获取最新研究数据（按创建时间倒序）
-func (r *Repository) GetLatestResearchData(limit, offset int) ([]*model.ResearchData, error) {
-	var data []*model.ResearchData
-	query := r.db.Order("created_at DESC")
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
-	err := query.Find(&data).Error
-	return data, err
+// 分页获取研究数据（默认按创建时间倒序）
+func (r *Repository) GetLatestResearchData(page PageRequest) (*Page[model.ResearchData], error) {
+	query := r.read().Model(&model.ResearchData{})
+	return paginate[model.ResearchData](query, page, []SortKey{recordSortKeys[1], recordSortKeys[0]})
 }
 
 // 获取最后的事件区块号
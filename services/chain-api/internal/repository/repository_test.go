@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"desci-backend/internal/db"
 	"desci-backend/internal/model"
+	"desci-backend/migrations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
@@ -16,12 +22,8 @@ func setupTestDB(t *testing.T) *Repository {
 	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	// 自动迁移所有模型
-	err = gormDB.AutoMigrate(
-		&model.ResearchData{},
-		&model.DatasetRecord{},
-		&model.EventLog{},
-	)
+	// 执行版本化迁移
+	err = Migrate(gormDB)
 	require.NoError(t, err)
 
 	return NewTestRepository(gormDB)
@@ -85,9 +87,136 @@ func TestRepository_ListResearchDataByAuthor(t *testing.T) {
 	require.NoError(t, err)
 
 	// 查询Alice的研究
-	results, err := repo.ListResearchDataByAuthor("Alice", 10)
+	results, err := repo.ListResearchDataByAuthor("Alice", PageRequest{Limit: 10})
 	assert.NoError(t, err)
-	assert.Len(t, results, 2)
+	assert.Len(t, results.Items, 2)
+	assert.Equal(t, int64(2), results.TotalEstimate)
+}
+
+func TestRepository_ResearchAuthorsIndex(t *testing.T) {
+	repo := setupTestDB(t)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	bob := "0xabc0000000000000000000000000000000000002"
+	for i, authors := range []model.StringArray{{bob, alice}, {alice}, {bob}} {
+		require.NoError(t, repo.InsertResearchData(&model.ResearchData{
+			TokenID:     fmt.Sprintf("%d", i+1),
+			Authors:     authors,
+			BlockNumber: uint64(10 * (i + 1)),
+		}))
+	}
+
+	// 校验和格式与小写地址均可命中，返回署名顺序，最新在前
+	results, err := repo.ListResearchDataByAuthor(strings.ToLower(alice), PageRequest{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), results.TotalEstimate)
+	require.Len(t, results.Items, 1)
+	assert.Equal(t, "2", results.Items[0].TokenID)
+	assert.Equal(t, 0, results.Items[0].AuthorPosition)
+
+	results, err = repo.ListResearchDataByAuthor(alice, PageRequest{Limit: 1, Cursor: results.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	assert.Equal(t, "1", results.Items[0].TokenID)
+	assert.Equal(t, 1, results.Items[0].AuthorPosition)
+	assert.Equal(t, model.StringArray{bob, alice}, results.Items[0].Authors)
+	assert.Empty(t, results.NextCursor)
+
+	// 更新作者时同步索引
+	require.NoError(t, repo.UpdateResearchData("3", map[string]interface{}{"authors": model.StringArray{alice, bob}}))
+	results, err = repo.ListResearchDataByAuthor(alice, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), results.TotalEstimate)
+
+	// 回滚区块时一并删除
+	require.NoError(t, repo.RollbackFromBlock(20))
+	results, err = repo.ListResearchDataByAuthor(bob, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), results.TotalEstimate)
+	assert.Equal(t, "1", results.Items[0].TokenID)
+}
+
+func TestMigrate_BackfillsResearchAuthors(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := db.NewMigrator(gormDB, migrations.FS)
+	require.NoError(t, err)
+
+	// 引入作者索引前已有的研究成果
+	_, err = migrator.Up(context.Background(), 1)
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`INSERT INTO research_data (token_id, authors, block_number) VALUES ('7', '["0xAA","0xbb","0xaa"]', 70)`).Error)
+
+	_, err = migrator.Up(context.Background(), 0)
+	require.NoError(t, err)
+
+	var authors []model.ResearchAuthor
+	require.NoError(t, gormDB.Order("position").Find(&authors).Error)
+	require.Len(t, authors, 2)
+	assert.Equal(t, "0xaa", authors[0].Author)
+	assert.Equal(t, 0, authors[0].Position)
+	assert.Equal(t, "0xbb", authors[1].Author)
+	assert.Equal(t, 1, authors[1].Position)
+	assert.Equal(t, uint64(70), authors[1].BlockNumber)
+}
+
+func TestRepository_OwnerCaseInsensitive(t *testing.T) {
+	repo := setupTestDB(t)
+
+	owner := "0xAbC0000000000000000000000000000000000001"
+	buyer := "0xDeF0000000000000000000000000000000000002"
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "1", Owner: owner, BlockNumber: 10}))
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "2", Owner: owner, BlockNumber: 11}))
+
+	// 仅经创建事件写入的记录同样以小写存储
+	for _, address := range []string{owner, strings.ToLower(owner)} {
+		datasets, err := repo.ListDatasetsByOwner(address, PageRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), datasets.TotalEstimate, address)
+	}
+
+	// Transfer 投影写入的持有人可用校验和格式查询
+	require.NoError(t, repo.ApplyNFTTransfer(&model.NFTTransfer{Collection: model.CollectionDataset, TokenID: "1", FromAddress: owner, ToAddress: buyer, BlockNumber: 12, TxHash: "0x1"}))
+	datasets, err := repo.ListDatasetsByOwner(buyer, PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, datasets.Items, 1)
+	assert.Equal(t, "1", datasets.Items[0].DatasetID)
+	assert.Equal(t, strings.ToLower(buyer), datasets.Items[0].Owner)
+
+	require.NoError(t, repo.UpdateDatasetRecord("2", map[string]interface{}{"owner": buyer}))
+	datasets, err = repo.ListDatasetsByOwner(buyer, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), datasets.TotalEstimate)
+	datasets, err = repo.ListDatasetsByOwner(owner, PageRequest{})
+	require.NoError(t, err)
+	assert.Zero(t, datasets.TotalEstimate)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", Owner: owner}))
+	research, err := repo.GetResearchData("1")
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToLower(owner), research.Owner)
+}
+
+func TestMigrate_LowercasesOwners(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := db.NewMigrator(gormDB, migrations.FS)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(context.Background(), 2)
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`INSERT INTO dataset_records (dataset_id, owner) VALUES ('1', '0xAbC')`).Error)
+	require.NoError(t, gormDB.Exec(`INSERT INTO research_data (token_id, owner) VALUES ('1', '0xDeF')`).Error)
+
+	_, err = migrator.Up(context.Background(), 0)
+	require.NoError(t, err)
+
+	var dataset model.DatasetRecord
+	require.NoError(t, gormDB.First(&dataset).Error)
+	assert.Equal(t, "0xabc", dataset.Owner)
+	var research model.ResearchData
+	require.NoError(t, gormDB.First(&research).Error)
+	assert.Equal(t, "0xdef", research.Owner)
 }
 
 func TestRepository_UpdateResearchData(t *testing.T) {
@@ -175,9 +304,9 @@ func TestRepository_ListDatasetsByOwner(t *testing.T) {
 	require.NoError(t, err)
 
 	// 查询owner的数据集
-	results, err := repo.ListDatasetsByOwner(owner, 10)
+	results, err := repo.ListDatasetsByOwner(owner, PageRequest{Limit: 10})
 	assert.NoError(t, err)
-	assert.Len(t, results, 2)
+	assert.Len(t, results.Items, 2)
 }
 
 func TestRepository_InsertEventLog(t *testing.T) {
@@ -209,6 +338,36 @@ func TestRepository_InsertEventLog(t *testing.T) {
 	assert.Equal(t, int64(1), count)
 }
 
+func TestRepository_EventLogRawFields(t *testing.T) {
+	repo := setupTestDB(t)
+
+	blockTime := time.Unix(1700000000, 0).UTC()
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{
+		TxHash:       "0xraw",
+		BlockNumber:  42,
+		EventName:    "TokenTransfer",
+		ContractAddr: "0x8acd85898458400f7db866d53fcff6f0d49741ff",
+		Topic0:       "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		Topics:       model.StringArray{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", "0x01", "0x02"},
+		Data:         "0x05dc",
+		BlockHash:    "0xblock42",
+		BlockTime:    &blockTime,
+		Args:         model.JSONMap{"value": "1500", "decimals": 18},
+	}))
+
+	logs, err := repo.GetEventsByBlockRange(42, 42)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	got := logs[0]
+	assert.Len(t, got.Topics, 3)
+	assert.Equal(t, "0x05dc", got.Data)
+	assert.Equal(t, "0xblock42", got.BlockHash)
+	assert.True(t, blockTime.Equal(*got.BlockTime))
+	// 数字以 json.Number 读回，不丢失精度
+	assert.Equal(t, "1500", got.Args["value"])
+	assert.Equal(t, json.Number("18"), got.Args["decimals"])
+}
+
 func TestRepository_GetUnprocessedEvents(t *testing.T) {
 	repo := setupTestDB(t)
 
@@ -381,6 +540,304 @@ func TestRepository_ConcurrentInsert(t *testing.T) {
 	assert.Equal(t, "Concurrent Test", retrieved.Title)
 }
 
+func TestRepository_RollbackFromBlock(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "canonical", BlockNumber: 100}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "orphaned", BlockNumber: 105}))
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "ds-orphaned", BlockNumber: 106}))
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0xa", BlockNumber: 100, EventName: "ResearchCreated"}))
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0xb", BlockNumber: 105, EventName: "ResearchCreated"}))
+	require.NoError(t, repo.SaveIndexedBlock(&model.IndexedBlock{Number: 100, Hash: "0x100"}))
+	require.NoError(t, repo.SaveIndexedBlock(&model.IndexedBlock{Number: 105, Hash: "0x105"}))
+
+	err := repo.RollbackFromBlock(105)
+	assert.NoError(t, err)
+
+	_, err = repo.GetResearchData("canonical")
+	assert.NoError(t, err)
+	_, err = repo.GetResearchData("orphaned")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	_, err = repo.GetDatasetRecord("ds-orphaned")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	events, err := repo.GetEventsByBlockRange(0, 1000)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	latest, err := repo.GetLatestIndexedBlockBefore(1000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), latest.Number)
+}
+
+func TestRepository_SyncCursors(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.SaveSyncCursor("0xaaa", 100))
+	require.NoError(t, repo.SaveSyncCursor("0xbbb", 300))
+	require.NoError(t, repo.SaveSyncCursor("0xaaa", 200))
+
+	cursors, err := repo.GetSyncCursors()
+	require.NoError(t, err)
+	require.Len(t, cursors, 2)
+
+	// 回滚会把越过分叉点的游标退回
+	require.NoError(t, repo.RollbackFromBlock(250))
+	cursors, err = repo.GetSyncCursors()
+	require.NoError(t, err)
+	byAddr := map[string]uint64{}
+	for _, c := range cursors {
+		byAddr[c.ContractAddr] = c.LastBlock
+	}
+	assert.Equal(t, uint64(200), byAddr["0xaaa"])
+	assert.Equal(t, uint64(249), byAddr["0xbbb"])
+}
+
+func TestRepository_PendingEvents(t *testing.T) {
+	repo := setupTestDB(t)
+
+	events := []*model.EventLog{
+		{TxHash: "0xp1", BlockNumber: 100, EventName: "ResearchCreated", EntityID: "1", Status: model.EventStatusPending},
+		{TxHash: "0xp2", BlockNumber: 110, EventName: "ResearchCreated", EntityID: "2", Status: model.EventStatusPending},
+		{TxHash: "0xc1", BlockNumber: 90, EventName: "ResearchCreated", EntityID: "0"},
+	}
+	for _, event := range events {
+		require.NoError(t, repo.InsertEventLog(event))
+	}
+
+	// 未指定状态的事件默认已确认
+	all, err := repo.GetEventsByBlockRange(0, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, model.EventStatusConfirmed, all[0].Status)
+
+	ready, err := repo.GetPendingEvents(105)
+	assert.NoError(t, err)
+	require.Len(t, ready, 1)
+	assert.Equal(t, "1", ready[0].EntityID)
+
+	pending, err := repo.GetPendingEventByEntity("ResearchCreated", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(110), pending.BlockNumber)
+
+	require.NoError(t, repo.MarkEventConfirmed(ready[0].ID))
+	remaining, err := repo.ListPendingEvents(PageRequest{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, remaining.Items, 1)
+}
+
+func TestRepository_RollbackRebuildsUserProfiles(t *testing.T) {
+	repo := setupTestDB(t)
+	addr := "0x00000000000000000000000000000000000000aa"
+
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{
+		TxHash: "0xr1", BlockNumber: 100, EventName: "UserRegistered", EntityID: addr,
+		PayloadRaw: `{"user":"0x00000000000000000000000000000000000000AA","name":"Alice","role":1,"timestamp":"1700000000"}`,
+	}))
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{
+		TxHash: "0xr2", BlockNumber: 105, EventName: "ReputationUpdated", EntityID: addr,
+		PayloadRaw: `{"user":"0x00000000000000000000000000000000000000AA","oldReputation":"0","newReputation":"50","reason":"review"}`,
+	}))
+	require.NoError(t, repo.SaveUserProfile(&model.UserProfile{
+		WalletAddress: addr, Name: "Alice", Role: 1, Registered: true, Reputation: "50", BlockNumber: 105,
+	}))
+	require.NoError(t, repo.InsertReputationChange(&model.ReputationChange{
+		WalletAddress: addr, OldReputation: "0", NewReputation: "50", BlockNumber: 105, TxHash: "0xr2",
+	}))
+
+	require.NoError(t, repo.RollbackFromBlock(105))
+
+	// 档案回到分叉点之前的状态
+	profile, err := repo.GetUserProfile(addr)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", profile.Name)
+	assert.Equal(t, "researcher", profile.RoleName)
+	assert.Equal(t, "0", profile.Reputation)
+	assert.Equal(t, uint64(100), profile.BlockNumber)
+
+	changes, err := repo.ListReputationChanges(addr, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// 注册本身被回滚时档案被删除
+	require.NoError(t, repo.RollbackFromBlock(100))
+	_, err = repo.GetUserProfile(addr)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestRepository_DatasetActivityRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertDatasetAccess(&model.DatasetAccess{DatasetID: "1", User: "0xA", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.InsertDatasetAccess(&model.DatasetAccess{DatasetID: "1", User: "0xB", BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.InsertDatasetQualityChange(&model.DatasetQualityChange{DatasetID: "1", NewLevel: 1, BlockNumber: 100, TxHash: "0x1", LogIndex: 1}))
+	require.NoError(t, repo.InsertDatasetQualityChange(&model.DatasetQualityChange{DatasetID: "1", NewLevel: 3, BlockNumber: 110, TxHash: "0x2", LogIndex: 1}))
+
+	require.NoError(t, repo.RollbackFromBlock(110))
+
+	accesses, err := repo.ListDatasetAccesses("1", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accesses.Items, 1)
+	assert.Equal(t, "0xa", accesses.Items[0].User)
+
+	// 当前质量等级回到分叉点之前
+	quality, err := repo.GetLatestDatasetQualityChange("1")
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), quality.NewLevel)
+}
+
+func TestRepository_ResearchStatsRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", BlockNumber: 90}))
+	require.NoError(t, repo.InsertResearchCitation(&model.ResearchCitation{FromTokenID: "2", ToTokenID: "1", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.InsertResearchCitation(&model.ResearchCitation{FromTokenID: "3", ToTokenID: "1", BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.InsertResearchImpactChange(&model.ResearchImpactChange{TokenID: "1", NewLevel: 3, BlockNumber: 110, TxHash: "0x2", LogIndex: 1}))
+
+	research, err := repo.GetResearchData("1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), research.CitationCount)
+	assert.Equal(t, "breakthrough", research.ImpactLevelName)
+
+	// 回滚后统计按剩余记录重新计算
+	require.NoError(t, repo.RollbackFromBlock(110))
+	research, err = repo.GetResearchData("1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), research.CitationCount)
+	assert.Equal(t, uint8(0), research.ImpactLevel)
+	assert.Equal(t, "low", research.ImpactLevelName)
+}
+
+func TestRepository_NFTOwnershipRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "5", Owner: "0xa", BlockNumber: 100}))
+	require.NoError(t, repo.ApplyNFTTransfer(&model.NFTTransfer{Collection: model.CollectionDataset, TokenID: "5", FromAddress: model.ZeroAddress, ToAddress: "0xA", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.ApplyNFTTransfer(&model.NFTTransfer{Collection: model.CollectionDataset, TokenID: "5", FromAddress: "0xA", ToAddress: "0xB", BlockNumber: 110, TxHash: "0x2"}))
+
+	owner, err := repo.GetNFTOwner(model.CollectionDataset, "5")
+	require.NoError(t, err)
+	assert.Equal(t, "0xb", owner.Owner)
+	record, err := repo.GetDatasetRecord("5")
+	require.NoError(t, err)
+	assert.Equal(t, "0xb", record.Owner)
+
+	// 回滚孤立的转移后持有人恢复
+	require.NoError(t, repo.RollbackFromBlock(110))
+	owner, err = repo.GetNFTOwner(model.CollectionDataset, "5")
+	require.NoError(t, err)
+	assert.Equal(t, "0xa", owner.Owner)
+	record, err = repo.GetDatasetRecord("5")
+	require.NoError(t, err)
+	assert.Equal(t, "0xa", record.Owner)
+
+	owned, err := repo.ListNFTsByOwner("0xA", "", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, owned.Items, 1)
+}
+
+func TestRepository_ProofRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "0", Submitter: "0xA", ProofType: "data_integrity", Status: model.ProofStatusSubmitted, BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "1", Submitter: "0xA", ProofType: "data_integrity", Status: model.ProofStatusSubmitted, BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.InsertProofVerification(&model.ProofVerification{Source: model.ProofSourceZKProof, ProofID: "0", IsValid: false, Verifier: "0xV", BlockNumber: 110, TxHash: "0x3"}))
+
+	proof, err := repo.GetProof("0")
+	require.NoError(t, err)
+	assert.Equal(t, model.ProofStatusRejected, proof.Status)
+	assert.Equal(t, "0xv", proof.Verifier)
+	assert.Equal(t, uint64(110), proof.VerifiedBlock)
+
+	// 证明类型由剩余的已确认事件重建
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0x4", BlockNumber: 90, EventName: "ProofTypeAdded", EntityID: "data_integrity",
+		PayloadRaw: `{"proofType":"data_integrity","verifierContract":"0xC"}`, Status: model.EventStatusConfirmed}))
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0x5", BlockNumber: 120, EventName: "ProofTypeUpdated", EntityID: "data_integrity",
+		PayloadRaw: `{"proofType":"data_integrity","isActive":false}`, Status: model.EventStatusConfirmed}))
+	require.NoError(t, repo.SaveProofType(&model.ProofType{Name: "data_integrity", VerifierContract: "0xc", IsActive: false, BlockNumber: 120}))
+
+	// 回滚孤立的验证和提交
+	require.NoError(t, repo.RollbackFromBlock(110))
+	proof, err = repo.GetProof("0")
+	require.NoError(t, err)
+	assert.Equal(t, model.ProofStatusSubmitted, proof.Status)
+	assert.Empty(t, proof.Verifier)
+	assert.Nil(t, proof.VerifiedAt)
+
+	_, err = repo.GetProof("1")
+	assert.Error(t, err)
+
+	proofType, err := repo.GetProofType("data_integrity")
+	require.NoError(t, err)
+	assert.True(t, proofType.IsActive)
+	assert.Equal(t, "0xc", proofType.VerifierContract)
+	assert.Equal(t, uint64(90), proofType.BlockNumber)
+}
+
+func TestRepository_InsertProofOverwritesExistingRecord(t *testing.T) {
+	repo := setupTestDB(t)
+
+	// 先占用 proofId 的记录（如模拟接口写入）被链上事件覆盖，旧的链下校验结果同时清空
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "7", Submitter: "0xF", ProofType: "fake", Status: model.ProofStatusSubmitted, BlockNumber: 1, TxHash: "0xfake"}))
+	require.NoError(t, repo.UpdateProof("7", map[string]interface{}{"check_status": model.ProofCheckInvalid, "check_attempts": 3}))
+
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "7", Submitter: "0xA", ProofType: "data_integrity", Status: model.ProofStatusSubmitted, BlockNumber: 100, TxHash: "0x1"}))
+	proof, err := repo.GetProof("7")
+	require.NoError(t, err)
+	assert.Equal(t, "0xa", proof.Submitter)
+	assert.Equal(t, "data_integrity", proof.ProofType)
+	assert.Equal(t, uint64(100), proof.BlockNumber)
+	assert.Equal(t, "0x1", proof.TxHash)
+	assert.Empty(t, proof.CheckStatus)
+	assert.Zero(t, proof.CheckAttempts)
+
+	// 重放同一事件保留验证状态和校验结果
+	require.NoError(t, repo.InsertProofVerification(&model.ProofVerification{Source: model.ProofSourceZKProof, ProofID: "7", IsValid: true, Verifier: "0xV", BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.UpdateProof("7", map[string]interface{}{"check_status": model.ProofCheckValid}))
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "7", Submitter: "0xA", ProofType: "data_integrity", Status: model.ProofStatusSubmitted, BlockNumber: 100, TxHash: "0x1"}))
+	proof, err = repo.GetProof("7")
+	require.NoError(t, err)
+	assert.Equal(t, model.ProofStatusVerified, proof.Status)
+	assert.Equal(t, model.ProofCheckValid, proof.CheckStatus)
+}
+
+func TestRepository_ConstraintRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	// 约束目录由剩余的已确认事件重建
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0x1", BlockNumber: 90, EventName: "ConstraintCreated", EntityID: "0xc1",
+		PayloadRaw: `{"constraintId":"0xc1","name":"row count","category":2,"creator":"0xA","operator":6,"thresholds":["10","20"],"isActive":true}`, Status: model.EventStatusConfirmed}))
+	require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0x2", BlockNumber: 120, EventName: "ConstraintUpdated", EntityID: "0xc1",
+		PayloadRaw: `{"constraintId":"0xc1","operator":6,"thresholds":["50","60"],"isActive":false}`, Status: model.EventStatusConfirmed}))
+	require.NoError(t, repo.SaveConstraint(&model.Constraint{ConstraintID: "0xc1", Name: "row count", Category: 2, CategoryName: "range",
+		Thresholds: model.StringArray{"50", "60"}, IsActive: false, BlockNumber: 120}))
+
+	require.NoError(t, repo.InsertConstraintGroup(&model.ConstraintGroup{GroupID: "0xg1", ConstraintIDs: model.StringArray{"0xc1"}, BlockNumber: 100}))
+	require.NoError(t, repo.InsertValidationRule(&model.ValidationRule{RuleID: "0xr1", GroupIDs: model.StringArray{"0xg1"}, BlockNumber: 110}))
+	require.NoError(t, repo.InsertConstraintEvaluation(&model.ConstraintEvaluation{ConstraintID: "0xc1", Result: true, BlockNumber: 100, TxHash: "0x3"}))
+	require.NoError(t, repo.InsertConstraintEvaluation(&model.ConstraintEvaluation{ConstraintID: "0xc1", Result: false, BlockNumber: 115, TxHash: "0x4"}))
+
+	require.NoError(t, repo.RollbackFromBlock(110))
+
+	constraint, err := repo.GetConstraint("0xc1")
+	require.NoError(t, err)
+	assert.True(t, constraint.IsActive)
+	assert.Equal(t, "range", constraint.CategoryName)
+	assert.Equal(t, model.StringArray{"10", "20"}, constraint.Thresholds)
+	assert.Equal(t, uint64(90), constraint.BlockNumber)
+
+	groups, err := repo.ListConstraintGroups()
+	require.NoError(t, err)
+	assert.Len(t, groups, 1)
+	rules, err := repo.ListValidationRules()
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	evaluations, err := repo.ListConstraintEvaluations("0xc1", 10)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 1)
+	assert.True(t, evaluations[0].Result)
+}
+
 // Benchmark测试
 func BenchmarkRepository_InsertResearchData(b *testing.B) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
@@ -402,3 +859,525 @@ func BenchmarkRepository_InsertResearchData(b *testing.B) {
 		repo.InsertResearchData(testData)
 	}
 }
+
+func TestRepository_DataQualityRollback(t *testing.T) {
+	repo := setupTestDB(t)
+	valid := true
+
+	require.NoError(t, repo.InsertDataQualityMetric(&model.DataQualityMetric{Source: model.DataQualitySourceVerifier, SubjectID: "0xd1", EventName: "DataSubmitted",
+		DataHash: "QmData", DataType: "genomic", Submitter: "0xA", VerificationStatus: "pending", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.InsertDataQualityMetric(&model.DataQualityMetric{Source: model.DataQualitySourceVerifier, SubjectID: "0xd1", EventName: "FeaturesExtracted",
+		Mean: "50", StandardDeviation: "5", MinValue: "10", MaxValue: "90", BlockNumber: 105, TxHash: "0x2"}))
+	require.NoError(t, repo.InsertDataQualityMetric(&model.DataQualityMetric{Source: model.DataQualitySourceVerifier, SubjectID: "0xd1", EventName: "DataVerified",
+		Score: "100", IsValid: &valid, VerificationStatus: "zkp_verified", BlockNumber: 110, TxHash: "0x3"}))
+	require.NoError(t, repo.InsertDataQualityMetric(&model.DataQualityMetric{Source: model.DataQualitySourceExtractor, SubjectID: "0xf1", EventName: "FeaturesCalculated",
+		DataCount: "10", BlockNumber: 120, TxHash: "0x4"}))
+
+	quality, err := repo.GetDataQuality(model.DataQualitySourceVerifier, "0xd1")
+	require.NoError(t, err)
+	assert.Equal(t, "QmData", quality.DataHash)
+	assert.Equal(t, "50", quality.Mean)
+	assert.Equal(t, "zkp_verified", quality.VerificationStatus)
+	require.NotNil(t, quality.IsValid)
+	assert.Equal(t, "0xa", quality.Submitter)
+
+	// 回滚后投影退回到剩余指标合并的结果
+	require.NoError(t, repo.RollbackFromBlock(110))
+	quality, err = repo.GetDataQuality(model.DataQualitySourceVerifier, "0xd1")
+	require.NoError(t, err)
+	assert.Equal(t, "pending", quality.VerificationStatus)
+	assert.Nil(t, quality.IsValid)
+	assert.Empty(t, quality.Score)
+	assert.Equal(t, "90", quality.MaxValue)
+	assert.Equal(t, uint64(105), quality.BlockNumber)
+
+	_, err = repo.GetDataQuality(model.DataQualitySourceExtractor, "0xf1")
+	assert.Error(t, err)
+
+	metrics, err := repo.ListDataQualityMetrics(model.DataQualitySourceVerifier, "0xd1", 10)
+	require.NoError(t, err)
+	assert.Len(t, metrics, 2)
+}
+
+func TestRepository_InfluenceChanges(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertInfluenceChange(&model.InfluenceChange{User: "0xA", EventName: "InfluenceUpdated", OldInfluence: "0", NewInfluence: "100", NewRank: "3", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.InsertInfluenceChange(&model.InfluenceChange{User: "0xA", EventName: "InfluenceUpdated", OldInfluence: "100", NewInfluence: "250", NewRank: "1", BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.InsertInfluenceChange(&model.InfluenceChange{User: "0xA", EventName: "InfluenceRankingUpdated", OldRank: "1", NewRank: "2", BlockNumber: 120, TxHash: "0x3"}))
+
+	// 事件未给出旧排名时沿用上一条记录的新排名
+	page, err := repo.ListInfluenceChanges("0xa", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	changes := page.Items
+	require.Len(t, changes, 3)
+	assert.Equal(t, "1", changes[0].OldRank)
+	assert.Equal(t, "3", changes[1].OldRank)
+	assert.Empty(t, changes[2].OldRank)
+
+	latest, err := repo.ListLatestInfluence(PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, latest.Items, 1)
+	assert.Equal(t, "250", latest.Items[0].NewInfluence)
+
+	require.NoError(t, repo.RollbackFromBlock(110))
+	latest, err = repo.ListLatestInfluence(PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, latest.Items, 1)
+	assert.Equal(t, "100", latest.Items[0].NewInfluence)
+}
+
+func TestRepository_InfluenceLeaderboardKeyset(t *testing.T) {
+	repo := setupTestDB(t)
+
+	scores := map[string]string{"0xa": "9", "0xb": "100000000000000000000", "0xc": "50", "0xd": "50"}
+	block := uint64(100)
+	for _, user := range []string{"0xa", "0xb", "0xc", "0xd"} {
+		block++
+		require.NoError(t, repo.InsertInfluenceChange(&model.InfluenceChange{User: user, EventName: "InfluenceUpdated", NewInfluence: "1", BlockNumber: block, TxHash: fmt.Sprintf("0x%d", block)}))
+		block++
+		require.NoError(t, repo.InsertInfluenceChange(&model.InfluenceChange{User: user, EventName: "InfluenceUpdated", NewInfluence: scores[user], BlockNumber: block, TxHash: fmt.Sprintf("0x%d", block)}))
+	}
+
+	// 按数值而不是字符串排序，每个用户只出现最新一条
+	var users []string
+	page := PageRequest{Limit: 3}
+	for {
+		result, err := repo.ListLatestInfluence(page)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), result.TotalEstimate)
+		for _, c := range result.Items {
+			users = append(users, c.User+"="+c.NewInfluence)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		page = PageRequest{Limit: 3, Cursor: result.NextCursor}
+	}
+	assert.Equal(t, []string{"0xb=100000000000000000000", "0xd=50", "0xc=50", "0xa=9"}, users)
+
+	_, err := repo.ListLatestInfluence(PageRequest{Sort: "block"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestRepository_TokenLedgerRollback(t *testing.T) {
+	repo := setupTestDB(t)
+
+	zero := model.ZeroAddress
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: zero, ToAddress: "0xA", Value: "1000", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: "0xA", ToAddress: "0xB", Value: "300", BlockNumber: 110, TxHash: "0x2"}))
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: "0xB", ToAddress: zero, Value: "100", BlockNumber: 120, TxHash: "0x3"}))
+	// 重复投递同一日志不改变余额
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: "0xA", ToAddress: "0xB", Value: "300", BlockNumber: 110, TxHash: "0x2"}))
+
+	holders, err := repo.ListTokenHolders(PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, holders.Items, 2)
+	assert.Equal(t, "0xa", holders.Items[0].Holder)
+	assert.Equal(t, "700", holders.Items[0].Balance)
+	assert.Equal(t, "200", holders.Items[1].Balance)
+
+	supply, err := repo.GetTokenSupply()
+	require.NoError(t, err)
+	assert.Equal(t, "900", supply.TotalSupply)
+	assert.Equal(t, "100", supply.Burned)
+
+	require.NoError(t, repo.RollbackFromBlock(110))
+	balance, err := repo.GetTokenBalance("0xA")
+	require.NoError(t, err)
+	assert.Equal(t, "1000", balance.Balance)
+	_, err = repo.GetTokenBalance("0xB")
+	assert.Error(t, err)
+
+	supply, err = repo.GetTokenSupply()
+	require.NoError(t, err)
+	assert.Equal(t, "1000", supply.TotalSupply)
+	assert.Equal(t, int64(1), supply.Holders)
+}
+
+func TestRepository_DeadLetterEvent(t *testing.T) {
+	repo := setupTestDB(t)
+
+	event := &model.EventLog{TxHash: "0xdead", BlockNumber: 7, EventName: "GrantAwarded", Status: model.EventStatusConfirmed, PayloadRaw: `{}`, Attempts: 3}
+	require.NoError(t, repo.InsertEventLog(event))
+
+	letter, err := repo.DeadLetterEvent(event, "bad payload", "handler: grant")
+	require.NoError(t, err)
+	assert.Equal(t, model.DeadLetterSourceService, letter.Source)
+	assert.Equal(t, model.DeadLetterStatusDead, letter.Status)
+
+	// 再次失败时更新同一条死信
+	event.Attempts = 4
+	again, err := repo.DeadLetterEvent(event, "still bad", "")
+	require.NoError(t, err)
+	assert.Equal(t, letter.ID, again.ID)
+	assert.Equal(t, 4, again.Attempts)
+	assert.Equal(t, "still bad", again.Error)
+
+	// 进入死信队列的事件不再自动重试
+	retryable, err := repo.GetRetryableEvents([]string{"GrantAwarded"}, time.Time{}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, retryable)
+
+	require.NoError(t, repo.MarkEventProcessed(event.ID))
+	require.NoError(t, repo.ResolveDeadLetters(event.ID))
+	letters, err := repo.ListDeadLetters(model.DeadLetterStatusResolved, PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), letters.TotalEstimate)
+	assert.Equal(t, letter.ID, letters.Items[0].ID)
+
+	stored, err := repo.GetEventLog(event.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.DeadLettered)
+
+	// 链重组回滚同时删除孤立区块的死信
+	require.NoError(t, repo.RollbackFromBlock(7))
+	letters, err = repo.ListDeadLetters("", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, letters.TotalEstimate)
+}
+
+func TestMigrate_MatchesModels(t *testing.T) {
+	migrated := setupTestDB(t).db
+	expected, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, expected.AutoMigrate(schemaModels...))
+
+	columns := func(db *gorm.DB, m interface{}) map[string]string {
+		types, err := db.Migrator().ColumnTypes(m)
+		require.NoError(t, err)
+		out := make(map[string]string, len(types))
+		for _, c := range types {
+			out[c.Name()] = strings.ToLower(c.DatabaseTypeName())
+		}
+		return out
+	}
+	// 新增或修改模型字段时需要同时新增迁移
+	for _, m := range schemaModels {
+		stmt := &gorm.Statement{DB: migrated}
+		require.NoError(t, stmt.Parse(m))
+		table := stmt.Schema.Table
+		assert.True(t, migrated.Migrator().HasTable(m), table)
+		assert.Equal(t, columns(expected, m), columns(migrated, m), table)
+		for name := range stmt.Schema.ParseIndexes() {
+			assert.True(t, migrated.Migrator().HasIndex(m, name), "%s.%s", table, name)
+		}
+	}
+}
+
+func TestRepository_ListQueriesUseReplica(t *testing.T) {
+	primary := setupTestDB(t)
+	replica := setupTestDB(t)
+	repo := &Repository{db: primary.db, reader: replica.db}
+
+	alice := "0xabc0000000000000000000000000000000000001"
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{TxHash: "0xprimary", FromAddress: model.ZeroAddress, ToAddress: alice, Value: "10", BlockNumber: 1}))
+
+	// 写入主库，副本尚未同步时列表查询读不到
+	holders, err := repo.ListTokenHolders(PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, holders.Items)
+	balance, err := repo.GetTokenBalance(alice)
+	require.NoError(t, err)
+	assert.Equal(t, "10", balance.Balance)
+
+	require.NoError(t, repo.InsertResearchCitation(&model.ResearchCitation{FromTokenID: "1", ToTokenID: "2", BlockNumber: 1, TxHash: "0xcite"}))
+	require.NoError(t, repo.InsertDatasetAccess(&model.DatasetAccess{DatasetID: "9", User: alice, BlockNumber: 1, TxHash: "0xaccess"}))
+	require.NoError(t, repo.InsertDeadLetter(&model.DeadLetter{Source: model.DeadLetterSourceListener, EventName: "GrantAwarded", TxHash: "0xdead"}))
+	citedBy, err := repo.ListResearchCitedBy("2", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, citedBy.Items)
+	accesses, err := repo.ListDatasetAccesses("9", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, accesses.Items)
+	letters, err := repo.ListDeadLetters("", PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, letters.TotalEstimate)
+
+	// 事务内始终读当前事务
+	require.NoError(t, repo.WithTx(context.Background(), func(tx IRepository) error {
+		holders, err := tx.ListTokenHolders(PageRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, holders.Items, 1)
+		return nil
+	}))
+	require.NoError(t, repo.Ping(context.Background()))
+}
+
+func TestPaginate_KeysetCursor(t *testing.T) {
+	repo := setupTestDB(t)
+
+	// 同一区块内多条转账，游标须按 log_index 和 id 区分
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{
+			FromAddress: model.ZeroAddress,
+			ToAddress:   "0xa",
+			Value:       "1",
+			BlockNumber: uint64(10 + i/2),
+			TxHash:      fmt.Sprintf("0x%d", i),
+			LogIndex:    uint(i % 2),
+		}))
+	}
+
+	walk := func(page PageRequest) []uint64 {
+		var blocks []uint64
+		for {
+			result, err := repo.ListTokenTransfers("", page)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), result.TotalEstimate)
+			for _, transfer := range result.Items {
+				blocks = append(blocks, transfer.BlockNumber*10+uint64(transfer.LogIndex))
+			}
+			if result.NextCursor == "" {
+				return blocks
+			}
+			page = PageRequest{Limit: page.Limit, Cursor: result.NextCursor}
+		}
+	}
+
+	assert.Equal(t, []uint64{120, 111, 110, 101, 100}, walk(PageRequest{Limit: 2}))
+	assert.Equal(t, []uint64{100, 101, 110, 111, 120}, walk(PageRequest{Limit: 2, Order: "asc"}))
+	assert.Len(t, walk(PageRequest{Limit: 2, Sort: "created"}), 5)
+
+	_, err := repo.ListTokenTransfers("", PageRequest{Sort: "value"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = repo.ListTokenTransfers("", PageRequest{Order: "up"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = repo.ListTokenTransfers("", PageRequest{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	result, err := repo.ListTokenTransfers("", PageRequest{Limit: MaxPageSize + 1})
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 5)
+}
+
+func TestPaginate_TimeCursor(t *testing.T) {
+	repo := setupTestDB(t)
+
+	created := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, repo.InsertResearchData(&model.ResearchData{
+			TokenID:   fmt.Sprintf("%d", i),
+			CreatedAt: created.Add(time.Duration(i/2) * time.Minute),
+		}))
+	}
+
+	var tokens []string
+	page := PageRequest{Limit: 3}
+	for {
+		result, err := repo.GetLatestResearchData(page)
+		require.NoError(t, err)
+		for _, data := range result.Items {
+			tokens = append(tokens, data.TokenID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		page = PageRequest{Limit: 3, Cursor: result.NextCursor}
+	}
+	assert.Equal(t, []string{"3", "2", "1", "0"}, tokens)
+}
+
+func TestListTokenHolders_KeysetByBalance(t *testing.T) {
+	repo := setupTestDB(t)
+
+	balances := []struct{ holder, value string }{
+		{"0xc", "5"}, {"0xa", "100000000000000000000"}, {"0xb", "5"}, {"0xd", "7"},
+	}
+	for i, b := range balances {
+		require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: model.ZeroAddress, ToAddress: b.holder, Value: b.value, BlockNumber: uint64(i + 1), TxHash: fmt.Sprintf("0x%d", i)}))
+	}
+
+	first, err := repo.ListTokenHolders(PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	assert.Equal(t, "0xa", first.Items[0].Holder)
+	assert.Equal(t, "0xd", first.Items[1].Holder)
+	assert.Equal(t, int64(4), first.TotalEstimate)
+
+	// 翻页间排名变化：d 降到末尾后不会重复出现
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: "0xd", ToAddress: model.ZeroAddress, Value: "6", BlockNumber: 10, TxHash: "0xburn"}))
+	second, err := repo.ListTokenHolders(PageRequest{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 2)
+	assert.Equal(t, "0xc", second.Items[0].Holder)
+	assert.Equal(t, "0xb", second.Items[1].Holder)
+
+	_, err = repo.ListTokenHolders(PageRequest{Sort: "block"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestMigrate_BackfillsRankingKeys(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := db.NewMigrator(gormDB, migrations.FS)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(context.Background(), 5)
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`INSERT INTO token_balances (holder, balance) VALUES ('0xa', '9'), ('0xb', '10')`).Error)
+	require.NoError(t, gormDB.Exec(`INSERT INTO influence_changes (user_address, event_name, new_influence, block_number, log_index) VALUES
+		('0xa', 'InfluenceUpdated', '100', 1, 0), ('0xa', 'InfluenceUpdated', '20', 2, 0), ('0xa', 'InfluenceRankingUpdated', '', 3, 0)`).Error)
+
+	_, err = migrator.Up(context.Background(), 0)
+	require.NoError(t, err)
+	repo := &Repository{db: gormDB}
+
+	holders, err := repo.ListTokenHolders(PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, holders.Items, 2)
+	assert.Equal(t, "0xb", holders.Items[0].Holder)
+	assert.Equal(t, amountKey("10"), holders.Items[0].BalanceKey)
+
+	latest, err := repo.ListLatestInfluence(PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, latest.Items, 1)
+	assert.Equal(t, "20", latest.Items[0].NewInfluence)
+}
+
+func TestRepository_Search(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", Title: "<b>Protein</b> folding & design", Authors: model.StringArray{"alice"}, Owner: "0xa", BlockNumber: 10}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "2", Title: "Graph neural networks for molecules and materials", Authors: model.StringArray{"protein lab"}, Owner: "0xb", BlockNumber: 20}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "3", Title: "Unrelated", Authors: model.StringArray{"carol"}, Owner: "0xa", BlockNumber: 30}))
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "ds-1", Title: "Protein structures", Description: "Cryo-EM maps", Owner: "0xA", BlockNumber: 15}))
+
+	ids := func(page *Page[model.SearchResult]) []string {
+		var out []string
+		for _, item := range page.Items {
+			out = append(out, item.DocType+":"+item.DocID)
+		}
+		return out
+	}
+
+	result, err := repo.Search(SearchFilter{Query: "protein"}, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.TotalEstimate)
+	assert.ElementsMatch(t, []string{"research:1", "research:2", "dataset:ds-1"}, ids(result))
+	// 标题命中排在仅作者命中之前
+	assert.Equal(t, "research:2", ids(result)[2])
+	for _, item := range result.Items {
+		if item.DocID == "1" {
+			assert.Equal(t, "&lt;b&gt;<mark>Protein</mark>&lt;/b&gt; folding &amp; design", item.TitleHighlight)
+		}
+		if item.DocID == "2" {
+			assert.Contains(t, item.Snippet, "<mark>protein</mark>")
+		}
+	}
+
+	// 类型、持有人（不区分大小写）和区块范围过滤
+	result, err = repo.Search(SearchFilter{Query: "protein", Type: model.SearchTypeDataset}, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dataset:ds-1"}, ids(result))
+	result, err = repo.Search(SearchFilter{Query: "protein", Owner: "0xa"}, PageRequest{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"research:1", "dataset:ds-1"}, ids(result))
+	result, err = repo.Search(SearchFilter{Query: "protein", FromBlock: 12, ToBlock: 20}, PageRequest{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"research:2", "dataset:ds-1"}, ids(result))
+
+	// 多个检索词须同时命中
+	result, err = repo.Search(SearchFilter{Query: "protein folding"}, PageRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"research:1"}, ids(result))
+
+	// 游标翻页不重复不遗漏
+	var walked []string
+	page := PageRequest{Limit: 2}
+	for {
+		result, err := repo.Search(SearchFilter{Query: "protein"}, page)
+		require.NoError(t, err)
+		walked = append(walked, ids(result)...)
+		if result.NextCursor == "" {
+			break
+		}
+		page = PageRequest{Limit: 2, Cursor: result.NextCursor}
+	}
+	assert.ElementsMatch(t, []string{"research:1", "research:2", "dataset:ds-1"}, walked)
+	assert.Len(t, walked, 3)
+
+	// 索引随更新和删除同步
+	require.NoError(t, repo.UpdateResearchData("3", map[string]interface{}{"title": "Protein dynamics"}))
+	require.NoError(t, repo.db.Where("dataset_id = ?", "ds-1").Delete(&model.DatasetRecord{}).Error)
+	result, err = repo.Search(SearchFilter{Query: "protein"}, PageRequest{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"research:1", "research:2", "research:3"}, ids(result))
+
+	_, err = repo.Search(SearchFilter{Query: "  "}, PageRequest{})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+	_, err = repo.Search(SearchFilter{Query: "protein", Type: "user"}, PageRequest{})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+	_, err = repo.Search(SearchFilter{Query: "protein"}, PageRequest{Order: "asc"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestRepository_SearchByAuthorName(t *testing.T) {
+	repo := setupTestDB(t)
+	ada := "0x00000000000000000000000000000000000000Ad"
+	grace := "0x00000000000000000000000000000000000000Ac"
+
+	require.NoError(t, repo.SaveUserProfile(&model.UserProfile{WalletAddress: ada, Name: "Ada Lovelace"}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", Title: "Analytical engine notes", Authors: model.StringArray{ada, grace}, BlockNumber: 10}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "2", Title: "Compilers", Authors: model.StringArray{grace}, BlockNumber: 11}))
+
+	ids := func(query string) []string {
+		result, err := repo.Search(SearchFilter{Query: query, Type: model.SearchTypeResearch}, PageRequest{})
+		require.NoError(t, err)
+		var out []string
+		for _, item := range result.Items {
+			out = append(out, item.DocID)
+		}
+		return out
+	}
+
+	// 按作者显示名称和地址检索
+	assert.Equal(t, []string{"1"}, ids("Lovelace"))
+	assert.ElementsMatch(t, []string{"1", "2"}, ids(grace))
+
+	// 研究成果发布后作者才设置或修改显示名称
+	require.NoError(t, repo.SaveUserProfile(&model.UserProfile{WalletAddress: grace, Name: "Grace Hopper"}))
+	assert.ElementsMatch(t, []string{"1", "2"}, ids("hopper"))
+	profile, err := repo.GetUserProfile(ada)
+	require.NoError(t, err)
+	profile.Name = "Augusta King"
+	require.NoError(t, repo.SaveUserProfile(profile))
+	assert.Empty(t, ids("Lovelace"))
+	assert.Equal(t, []string{"1"}, ids("Augusta"))
+
+	// 作者变更后不再命中原作者
+	require.NoError(t, repo.UpdateResearchData("1", map[string]interface{}{"authors": model.StringArray{ada}}))
+	assert.Equal(t, []string{"2"}, ids("Hopper"))
+
+	research, err := repo.GetResearchData("1")
+	require.NoError(t, err)
+	assert.Equal(t, "Augusta King "+strings.ToLower(ada), research.AuthorsText)
+}
+
+func TestMarkTerms(t *testing.T) {
+	assert.Equal(t, "\x02Deep\x03 \x02deep\x03er", markTerms("Deep deeper", []string{"DEEP"}, 0))
+	// 摘要截取首个命中附近的片段
+	text := strings.Repeat("a ", 50) + "needle" + strings.Repeat(" b", 50)
+	snippet := markTerms(text, []string{"needle"}, 20)
+	assert.True(t, strings.HasPrefix(snippet, ellipsis))
+	assert.True(t, strings.HasSuffix(snippet, ellipsis))
+	assert.Contains(t, snippet, "\x02needle\x03")
+	assert.Equal(t, `100\%\_\\`, escapeLike(`100%_\`))
+}
+
+func TestDerivedInRange_BoundedByToBlock(t *testing.T) {
+	repo := setupTestDB(t)
+
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: model.ZeroAddress, ToAddress: "0xa", Value: "10", BlockNumber: 100, TxHash: "0x1"}))
+	require.NoError(t, repo.ApplyTokenTransfer(&model.TokenTransfer{FromAddress: model.ZeroAddress, ToAddress: "0xb", Value: "10", BlockNumber: 200, TxHash: "0x2"}))
+
+	// 重建范围内收集的实体不包含范围之后的记录
+	toBlock := uint64(150)
+	affected, err := derivedInRange(repo.db, blockRange{from: 100, to: &toBlock})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{model.ZeroAddress, "0xa"}, affected.tokenHolders)
+
+	affected, err = derivedFromBlock(repo.db, 100)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{model.ZeroAddress, "0xa", "0xb"}, affected.tokenHolders)
+}
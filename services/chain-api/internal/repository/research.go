@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 插入研究成果引用关系并刷新被引成果的统计（按 tx_hash + log_index 去重）
+func (r *Repository) InsertResearchCitation(citation *model.ResearchCitation) error {
+	citation.Citer = strings.ToLower(citation.Citer)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(citation, "tx_hash = ? AND log_index = ?", citation.TxHash, citation.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshResearchStats(tx, citation.ToTokenID)
+	})
+}
+
+// 分页查询引用了该成果的记录（默认最新在前）
+func (r *Repository) ListResearchCitedBy(tokenID string, page PageRequest) (*Page[model.ResearchCitation], error) {
+	query := r.read().Model(&model.ResearchCitation{}).Where("to_token_id = ?", tokenID)
+	return paginate[model.ResearchCitation](query, page, eventSortKeys)
+}
+
+// 分页查询该成果引用的其他成果（默认最新在前）
+func (r *Repository) ListResearchReferences(tokenID string, page PageRequest) (*Page[model.ResearchCitation], error) {
+	query := r.read().Model(&model.ResearchCitation{}).Where("from_token_id = ?", tokenID)
+	return paginate[model.ResearchCitation](query, page, eventSortKeys)
+}
+
+// 插入同行评审记录并刷新统计（按 tx_hash + log_index 去重）
+func (r *Repository) InsertResearchReview(review *model.ResearchReview) error {
+	review.Reviewer = strings.ToLower(review.Reviewer)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(review, "tx_hash = ? AND log_index = ?", review.TxHash, review.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshResearchStats(tx, review.TokenID)
+	})
+}
+
+// 分页查询成果的评审记录（默认最新在前）
+func (r *Repository) ListResearchReviews(tokenID string, page PageRequest) (*Page[model.ResearchReview], error) {
+	query := r.read().Model(&model.ResearchReview{}).Where("token_id = ?", tokenID)
+	return paginate[model.ResearchReview](query, page, eventSortKeys)
+}
+
+// 插入影响力等级变更并刷新统计（按 tx_hash + log_index 去重）
+func (r *Repository) InsertResearchImpactChange(change *model.ResearchImpactChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(change, "tx_hash = ? AND log_index = ?", change.TxHash, change.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshResearchStats(tx, change.TokenID)
+	})
+}
+
+// 分页查询成果的影响力等级历史（默认最新在前）
+func (r *Repository) ListResearchImpactChanges(tokenID string, page PageRequest) (*Page[model.ResearchImpactChange], error) {
+	query := r.read().Model(&model.ResearchImpactChange{}).Where("token_id = ?", tokenID)
+	return paginate[model.ResearchImpactChange](query, page, eventSortKeys)
+}
+
+// 查询成果最新一次影响力等级变更
+func (r *Repository) GetLatestResearchImpactChange(tokenID string) (*model.ResearchImpactChange, error) {
+	var change model.ResearchImpactChange
+	err := r.db.Where("token_id = ?", tokenID).Order("block_number DESC, log_index DESC").First(&change).Error
+	return &change, err
+}
+
+// 插入收益分配记录并刷新统计（按 tx_hash + log_index 去重）
+func (r *Repository) InsertResearchRevenue(revenue *model.ResearchRevenue) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(revenue, "tx_hash = ? AND log_index = ?", revenue.TxHash, revenue.LogIndex).Error; err != nil {
+			return err
+		}
+		return refreshResearchStats(tx, revenue.TokenID)
+	})
+}
+
+// 分页查询成果的收益分配记录（默认最新在前）
+func (r *Repository) ListResearchRevenue(tokenID string, page PageRequest) (*Page[model.ResearchRevenue], error) {
+	query := r.read().Model(&model.ResearchRevenue{}).Where("token_id = ?", tokenID)
+	return paginate[model.ResearchRevenue](query, page, eventSortKeys)
+}
+
+// 累计成果的全部收益分配金额
+func (r *Repository) SumResearchRevenue(tokenID string) (string, error) {
+	total, err := sumResearchRevenue(r.db, tokenID)
+	if err != nil {
+		return "", err
+	}
+	return total.String(), nil
+}
+
+// 插入元数据更新记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertResearchMetadataUpdate(update *model.ResearchMetadataUpdate) error {
+	return r.db.FirstOrCreate(update, "tx_hash = ? AND log_index = ?", update.TxHash, update.LogIndex).Error
+}
+
+// refreshResearchStats 由引用、评审、影响力和收益记录重新计算研究成果的统计字段
+func refreshResearchStats(tx *gorm.DB, tokenID string) error {
+	var citations int64
+	if err := tx.Model(&model.ResearchCitation{}).Where("to_token_id = ?", tokenID).Count(&citations).Error; err != nil {
+		return err
+	}
+
+	var reviews struct {
+		Count int64
+		Avg   float64
+	}
+	err := tx.Model(&model.ResearchReview{}).Select("COUNT(*) AS count, COALESCE(AVG(score), 0) AS avg").
+		Where("token_id = ?", tokenID).Scan(&reviews).Error
+	if err != nil {
+		return err
+	}
+
+	var impact model.ResearchImpactChange
+	err = tx.Where("token_id = ?", tokenID).Order("block_number DESC, log_index DESC").First(&impact).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	total, err := sumResearchRevenue(tx, tokenID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.ResearchData{}).Where("token_id = ?", tokenID).Updates(map[string]interface{}{
+		"citation_count":    citations,
+		"review_count":      reviews.Count,
+		"average_score":     reviews.Avg,
+		"impact_level":      impact.NewLevel,
+		"impact_level_name": model.ImpactLevelName(impact.NewLevel),
+		"total_revenue":     total.String(),
+	}).Error
+}
+
+// sumResearchRevenue uint256 金额以字符串存储，只取金额列在内存中累加
+func sumResearchRevenue(tx *gorm.DB, tokenID string) (*big.Int, error) {
+	var amounts []string
+	if err := tx.Model(&model.ResearchRevenue{}).Where("token_id = ?", tokenID).Pluck("total_amount", &amounts).Error; err != nil {
+		return nil, err
+	}
+	return sumAmounts(amounts), nil
+}
+
+// researchTokensInRange 返回区块范围内有统计相关记录的研究成果
+func researchTokensInRange(tx *gorm.DB, blocks blockRange) ([]string, error) {
+	seen := map[string]bool{}
+	var tokens []string
+	sources := []struct {
+		model  interface{}
+		column string
+	}{
+		{&model.ResearchCitation{}, "to_token_id"},
+		{&model.ResearchReview{}, "token_id"},
+		{&model.ResearchImpactChange{}, "token_id"},
+		{&model.ResearchRevenue{}, "token_id"},
+	}
+	for _, src := range sources {
+		var ids []string
+		if err := tx.Model(src.model).Scopes(blocks.scope).Distinct().Pluck(src.column, &ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				tokens = append(tokens, id)
+			}
+		}
+	}
+	return tokens, nil
+}
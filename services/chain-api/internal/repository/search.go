@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSearch = errors.New("invalid search query")
+
+const (
+	// searchMaxTerms 单次检索最多使用的检索词数量
+	searchMaxTerms = 16
+	// searchSnippetRunes 回退检索时摘要片段的字符数
+	searchSnippetRunes = 64
+
+	// 数据库生成高亮时使用的临时标记，转义 HTML 后再替换为 <mark>
+	markStart = "\x02"
+	markEnd   = "\x03"
+	ellipsis  = "…"
+)
+
+// SearchFilter 全文检索条件；Type 为空时同时检索研究成果和数据集，ToBlock 为 0 表示不限
+type SearchFilter struct {
+	Query     string
+	Type      string
+	Owner     string
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+// searchSource 参与检索的业务表：标题权重最高，Body 为另一个检索列
+type searchSource struct {
+	DocType  string
+	Table    string
+	IDColumn string
+	Body     string
+	Index    string // SQLite FTS5 外部内容表
+}
+
+var searchSources = []searchSource{
+	{DocType: model.SearchTypeResearch, Table: "research_data", IDColumn: "token_id", Body: "authors_text", Index: "research_search"},
+	{DocType: model.SearchTypeDataset, Table: "dataset_records", IDColumn: "dataset_id", Body: "description", Index: "dataset_search"},
+}
+
+// 检索结果按相关度从高到低，相同时按类型和 ID 确定顺序
+var searchSortKeys = []SortKey{
+	{Name: "rank", Columns: []string{"score", "doc_type", "doc_id"}},
+}
+
+// 检索方式：Postgres tsvector、SQLite FTS5，以及未编译 FTS5 时的 LIKE 回退
+const (
+	searchPostgres = "postgres"
+	searchFTS5     = "fts5"
+	searchLike     = "like"
+)
+
+// Search 在研究成果（标题、作者显示名称和地址）和数据集（标题、描述）中全文检索，按相关度分页
+func (r *Repository) Search(filter SearchFilter, page PageRequest) (*Page[model.SearchResult], error) {
+	terms := strings.Fields(filter.Query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearch
+	}
+	if len(terms) > searchMaxTerms {
+		terms = terms[:searchMaxTerms]
+	}
+	sources := searchSources
+	switch filter.Type {
+	case "":
+	case model.SearchTypeResearch:
+		sources = searchSources[:1]
+	case model.SearchTypeDataset:
+		sources = searchSources[1:]
+	default:
+		return nil, ErrInvalidSearch
+	}
+
+	key, desc, after, err := page.resolve(searchSortKeys)
+	if err != nil {
+		return nil, err
+	}
+	if !desc {
+		return nil, ErrInvalidSort
+	}
+
+	conn := r.read()
+	backend := searchBackend(conn)
+	branches := make([]string, len(sources))
+	var args []interface{}
+	for i, source := range sources {
+		var branchArgs []interface{}
+		branches[i], branchArgs = searchBranch(backend, source, terms, filter)
+		args = append(args, branchArgs...)
+	}
+	union := "(" + strings.Join(branches, " UNION ALL ") + ") AS search_results"
+
+	result := &Page[model.SearchResult]{Items: []model.SearchResult{}}
+	if err := conn.Raw("SELECT COUNT(*) FROM "+union, args...).Scan(&result.TotalEstimate).Error; err != nil {
+		return nil, err
+	}
+
+	query := "SELECT * FROM " + union
+	if after != nil {
+		var score float64
+		var docType, docID string
+		if json.Unmarshal(after.Values[0], &score) != nil ||
+			json.Unmarshal(after.Values[1], &docType) != nil ||
+			json.Unmarshal(after.Values[2], &docID) != nil {
+			return nil, ErrInvalidCursor
+		}
+		query += " WHERE (score, doc_type, doc_id) < (?, ?, ?)"
+		args = append(args, score, docType, docID)
+	}
+	limit := page.limit()
+	query += " ORDER BY score DESC, doc_type DESC, doc_id DESC LIMIT ?"
+	args = append(args, limit+1)
+	if err := conn.Raw(query, args...).Scan(&result.Items).Error; err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+		last := result.Items[limit-1]
+		score, _ := json.Marshal(last.Score)
+		docType, _ := json.Marshal(last.DocType)
+		docID, _ := json.Marshal(last.DocID)
+		result.NextCursor = encodeCursor(cursor{Sort: key.Name, Desc: true, Values: []json.RawMessage{score, docType, docID}})
+	}
+	for i := range result.Items {
+		item := &result.Items[i]
+		if backend == searchLike {
+			item.TitleHighlight = markTerms(item.TitleHighlight, terms, 0)
+			item.Snippet = markTerms(item.Snippet, terms, searchSnippetRunes)
+		}
+		item.TitleHighlight = renderMarks(item.TitleHighlight)
+		item.Snippet = renderMarks(item.Snippet)
+	}
+	return result, nil
+}
+
+// searchBackend 根据数据库方言及 SQLite 驱动是否编译了 FTS5 选择检索方式
+func searchBackend(conn *gorm.DB) string {
+	if conn.Dialector.Name() == "postgres" {
+		return searchPostgres
+	}
+	if fts5Enabled(conn) {
+		return searchFTS5
+	}
+	return searchLike
+}
+
+// fts5Enabled 判断 SQLite 是否支持 FTS5（mattn/go-sqlite3 需以 sqlite_fts5 标签编译）
+func fts5Enabled(conn *gorm.DB) bool {
+	var enabled int
+	if err := conn.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return false
+	}
+	return enabled == 1
+}
+
+// searchBranch 生成单个业务表的检索子查询，各分支列一致以便 UNION ALL
+func searchBranch(backend string, source searchSource, terms []string, filter SearchFilter) (string, []interface{}) {
+	var sql string
+	var args []interface{}
+	columns := fmt.Sprintf("'%s' AS doc_type, d.%s AS doc_id, d.title AS title, d.owner AS owner, d.block_number AS block_number", source.DocType, source.IDColumn)
+
+	switch backend {
+	case searchPostgres:
+		// 与 0003_search、0007_author_search 迁移中的索引表达式保持一致才能命中 GIN 索引
+		vector := fmt.Sprintf("setweight(to_tsvector('simple', coalesce(d.title, '')), 'A') || setweight(to_tsvector('simple', coalesce(d.%s, '')), 'B')", source.Body)
+		sql = fmt.Sprintf("SELECT %s, ts_rank_cd(%s, q)::float8 AS score, ts_headline('simple', coalesce(d.title, ''), q, ?) AS title_highlight, ts_headline('simple', coalesce(d.%s, ''), q, ?) AS snippet FROM %s AS d, websearch_to_tsquery('simple', ?) AS q WHERE %s @@ q",
+			columns, vector, source.Body, source.Table, vector)
+		marks := fmt.Sprintf(`StartSel="%s", StopSel="%s"`, markStart, markEnd)
+		args = append(args, marks+", HighlightAll=true", marks+", MaxWords=24, MinWords=8", strings.Join(terms, " "))
+
+	case searchFTS5:
+		// bm25 越小越相关，取负数使分数越大越相关；标题列权重为 2
+		sql = fmt.Sprintf("SELECT %s, -bm25(%s, 2.0, 1.0) AS score, highlight(%s, 0, ?, ?) AS title_highlight, snippet(%s, -1, ?, ?, ?, 16) AS snippet FROM %s JOIN %s AS d ON d.id = %s.rowid WHERE %s MATCH ?",
+			columns, source.Index, source.Index, source.Index, source.Index, source.Table, source.Index, source.Index)
+		args = append(args, markStart, markEnd, markStart, markEnd, ellipsis, fts5Query(terms))
+
+	default:
+		// 每个检索词须出现在标题或 Body 中；标题命中计 2 分，Body 命中计 1 分
+		scores := make([]string, len(terms))
+		conditions := make([]string, len(terms))
+		var scoreArgs, conditionArgs []interface{}
+		for i, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			scores[i] = fmt.Sprintf(`CASE WHEN d.title LIKE ? ESCAPE '\' THEN 2 ELSE 0 END + CASE WHEN d.%s LIKE ? ESCAPE '\' THEN 1 ELSE 0 END`, source.Body)
+			conditions[i] = fmt.Sprintf(`(d.title LIKE ? ESCAPE '\' OR d.%s LIKE ? ESCAPE '\')`, source.Body)
+			scoreArgs = append(scoreArgs, pattern, pattern)
+			conditionArgs = append(conditionArgs, pattern, pattern)
+		}
+		sql = fmt.Sprintf("SELECT %s, CAST(%s AS REAL) AS score, d.title AS title_highlight, d.%s AS snippet FROM %s AS d WHERE %s",
+			columns, strings.Join(scores, " + "), source.Body, source.Table, strings.Join(conditions, " AND "))
+		args = append(scoreArgs, conditionArgs...)
+	}
+
+	if filter.Owner != "" {
+		sql += " AND d.owner = ?"
+		args = append(args, strings.ToLower(filter.Owner))
+	}
+	if filter.FromBlock > 0 {
+		sql += " AND d.block_number >= ?"
+		args = append(args, filter.FromBlock)
+	}
+	if filter.ToBlock > 0 {
+		sql += " AND d.block_number <= ?"
+		args = append(args, filter.ToBlock)
+	}
+	return sql, args
+}
+
+// fts5Query 将检索词逐个加引号后以 AND 组合，避免用户输入被解析为 FTS5 查询语法
+func fts5Query(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '\' 使用
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// markTerms 用临时标记包裹文本中命中的检索词（不区分大小写）；
+// window 大于 0 时只保留首个命中附近的 window 个字符作为摘要
+func markTerms(text string, terms []string, window int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, c := range runes {
+		lower[i] = unicode.ToLower(c)
+	}
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if window > 0 && len(runes) > window {
+		first := 0
+		for first < len(marked) && !marked[first] {
+			first++
+		}
+		if first == len(marked) {
+			first = 0
+		}
+		start = max(0, min(first-window/4, len(runes)-window))
+		end = start + window
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString(markStart)
+		}
+		b.WriteRune(runes[i])
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString(markEnd)
+		}
+	}
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// renderMarks 转义 HTML 后将临时标记替换为 <mark>，链上写入的标题和描述不会被当作标签渲染
+func renderMarks(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>").Replace(text)
+}
+
+// ensureSearchIndex 为 SQLite 建立 FTS5 外部内容索引及同步触发器。FTS5 依赖驱动的编译标签，
+// 因此不放在版本化迁移中：未编译 FTS5 时删除旧触发器（否则写入业务表会失败），检索回退到 LIKE
+func ensureSearchIndex(conn *gorm.DB) error {
+	if conn.Dialector.Name() != "sqlite" {
+		return nil
+	}
+	if !fts5Enabled(conn) {
+		for _, source := range searchSources {
+			for _, suffix := range []string{"ai", "ad", "au"} {
+				if err := conn.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s_%s", source.Index, suffix)).Error; err != nil {
+					return err
+				}
+			}
+		}
+		log.Printf("⚠️  SQLite built without FTS5, search falls back to LIKE matching")
+		return nil
+	}
+
+	for _, source := range searchSources {
+		var triggers int64
+		err := conn.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND tbl_name = ? AND name LIKE ?", source.Table, source.Index+"_%").Scan(&triggers).Error
+		if err != nil {
+			return err
+		}
+		if triggers == 3 {
+			continue
+		}
+
+		// 检索列可能随迁移变化，重建时按当前列重新创建虚拟表和触发器
+		cols := "title, " + source.Body
+		insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.id, new.title, new.%s);", source.Index, cols, source.Body)
+		remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, old.title, old.%s);", source.Index, source.Index, cols, source.Body)
+		statements := []string{
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ai", source.Index),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ad", source.Index),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_au", source.Index),
+			fmt.Sprintf("DROP TABLE IF EXISTS %s", source.Index),
+			fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content='%s', content_rowid='id')", source.Index, cols, source.Table),
+			fmt.Sprintf("CREATE TRIGGER %s_ai AFTER INSERT ON %s BEGIN %s END", source.Index, source.Table, insert),
+			fmt.Sprintf("CREATE TRIGGER %s_ad AFTER DELETE ON %s BEGIN %s END", source.Index, source.Table, remove),
+			fmt.Sprintf("CREATE TRIGGER %s_au AFTER UPDATE OF %s ON %s BEGIN %s %s END", source.Index, cols, source.Table, remove, insert),
+			// 触发器缺失期间的写入未进入索引，按业务表重建
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", source.Index, source.Index),
+		}
+		for _, statement := range statements {
+			if err := conn.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"math/big"
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 记录一次 SciToken 转账并重算双方余额（按 tx_hash + log_index 去重）
+func (r *Repository) ApplyTokenTransfer(transfer *model.TokenTransfer) error {
+	transfer.FromAddress = strings.ToLower(transfer.FromAddress)
+	transfer.ToAddress = strings.ToLower(transfer.ToAddress)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.FirstOrCreate(transfer, "tx_hash = ? AND log_index = ?", transfer.TxHash, transfer.LogIndex).Error; err != nil {
+			return err
+		}
+		if err := refreshTokenBalance(tx, transfer.FromAddress); err != nil {
+			return err
+		}
+		return refreshTokenBalance(tx, transfer.ToAddress)
+	})
+}
+
+// 查询地址的 SciToken 余额
+func (r *Repository) GetTokenBalance(holder string) (*model.TokenBalance, error) {
+	var balance model.TokenBalance
+	err := r.db.Where("holder = ?", strings.ToLower(holder)).First(&balance).Error
+	return &balance, err
+}
+
+// 分页查询余额不为零的持有人，默认按余额从高到低排列（按 balance_key 索引键集分页）
+func (r *Repository) ListTokenHolders(page PageRequest) (*Page[model.TokenBalance], error) {
+	query := r.read().Model(&model.TokenBalance{}).Where("balance <> ?", "0")
+	return paginate[model.TokenBalance](query, page, holderSortKeys)
+}
+
+// 分页查询转账记录（默认最新在前），address 非空时只返回该地址转出或转入的记录
+func (r *Repository) ListTokenTransfers(address string, page PageRequest) (*Page[model.TokenTransfer], error) {
+	query := r.read().Model(&model.TokenTransfer{})
+	if address != "" {
+		address = strings.ToLower(address)
+		query = query.Where("from_address = ? OR to_address = ?", address, address)
+	}
+	return paginate[model.TokenTransfer](query, page, eventSortKeys)
+}
+
+// 由铸造（from 为零地址）与销毁（to 为零地址）记录计算总供应量
+func (r *Repository) GetTokenSupply() (*model.TokenSupply, error) {
+	var minted, burned []string
+	if err := r.db.Model(&model.TokenTransfer{}).Where("from_address = ?", model.ZeroAddress).Pluck("value", &minted).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&model.TokenTransfer{}).Where("to_address = ?", model.ZeroAddress).Pluck("value", &burned).Error; err != nil {
+		return nil, err
+	}
+	var holders int64
+	if err := r.db.Model(&model.TokenBalance{}).Where("balance <> ?", "0").Count(&holders).Error; err != nil {
+		return nil, err
+	}
+
+	mintedTotal, burnedTotal := sumAmounts(minted), sumAmounts(burned)
+	return &model.TokenSupply{
+		TotalSupply: new(big.Int).Sub(mintedTotal, burnedTotal).String(),
+		Minted:      mintedTotal.String(),
+		Burned:      burnedTotal.String(),
+		Holders:     holders,
+	}, nil
+}
+
+// 插入授权记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertTokenApproval(approval *model.TokenApproval) error {
+	approval.Owner = strings.ToLower(approval.Owner)
+	approval.Spender = strings.ToLower(approval.Spender)
+	return r.db.FirstOrCreate(approval, "tx_hash = ? AND log_index = ?", approval.TxHash, approval.LogIndex).Error
+}
+
+// 查询 owner 对每个 spender 最新的授权额度
+func (r *Repository) ListTokenAllowances(owner string) ([]model.TokenApproval, error) {
+	var all []model.TokenApproval
+	err := r.db.Where("owner = ?", strings.ToLower(owner)).Order("block_number DESC, log_index DESC").Find(&all).Error
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	allowances := make([]model.TokenApproval, 0, len(all))
+	for _, a := range all {
+		if seen[a.Spender] {
+			continue
+		}
+		seen[a.Spender] = true
+		allowances = append(allowances, a)
+	}
+	return allowances, nil
+}
+
+// refreshTokenBalance 按剩余的转账记录重算地址余额，零地址不记余额，没有记录时删除余额
+func refreshTokenBalance(tx *gorm.DB, holder string) error {
+	if holder == "" || holder == model.ZeroAddress {
+		return nil
+	}
+	var transfers []model.TokenTransfer
+	err := tx.Where("from_address = ? OR to_address = ?", holder, holder).
+		Order("block_number ASC, log_index ASC").Find(&transfers).Error
+	if err != nil {
+		return err
+	}
+	if len(transfers) == 0 {
+		return tx.Where("holder = ?", holder).Delete(&model.TokenBalance{}).Error
+	}
+
+	balance := new(big.Int)
+	for _, t := range transfers {
+		// 自转账不改变余额
+		if t.FromAddress == t.ToAddress {
+			continue
+		}
+		if t.ToAddress == holder {
+			balance.Add(balance, DecimalAmount(t.Value))
+		} else {
+			balance.Sub(balance, DecimalAmount(t.Value))
+		}
+	}
+	return tx.Save(&model.TokenBalance{
+		Holder:      holder,
+		Balance:     balance.String(),
+		BalanceKey:  amountKey(balance.String()),
+		BlockNumber: transfers[len(transfers)-1].BlockNumber,
+	}).Error
+}
+
+// tokenHoldersInRange 返回区块范围内有转账的地址
+func tokenHoldersInRange(tx *gorm.DB, blocks blockRange) ([]string, error) {
+	var from, to []string
+	if err := tx.Model(&model.TokenTransfer{}).Scopes(blocks.scope).Distinct().Pluck("from_address", &from).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.TokenTransfer{}).Scopes(blocks.scope).Distinct().Pluck("to_address", &to).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	holders := make([]string, 0, len(from)+len(to))
+	for _, h := range append(from, to...) {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		holders = append(holders, h)
+	}
+	return holders, nil
+}
+
+// DecimalAmount 解析十进制金额字符串，无效值按0处理
+func DecimalAmount(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// sumAmounts 累加十进制金额字符串
+func sumAmounts(amounts []string) *big.Int {
+	total := new(big.Int)
+	for _, a := range amounts {
+		total.Add(total, DecimalAmount(a))
+	}
+	return total
+}
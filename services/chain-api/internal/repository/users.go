@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"strings"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// 查询用户档案（地址不区分大小写）
+func (r *Repository) GetUserProfile(address string) (*model.UserProfile, error) {
+	var profile model.UserProfile
+	err := r.db.Where("wallet_address = ?", strings.ToLower(address)).First(&profile).Error
+	return &profile, err
+}
+
+// 保存用户档案（新建或覆盖），并刷新其署名研究成果的检索文本（显示名称可能变化）
+func (r *Repository) SaveUserProfile(profile *model.UserProfile) error {
+	profile.WalletAddress = strings.ToLower(profile.WalletAddress)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(profile).Error; err != nil {
+			return err
+		}
+		return refreshAuthoredResearchText(tx, profile.WalletAddress)
+	})
+}
+
+// 插入声誉变更记录（按 tx_hash + log_index 去重）
+func (r *Repository) InsertReputationChange(change *model.ReputationChange) error {
+	change.WalletAddress = strings.ToLower(change.WalletAddress)
+	return r.db.FirstOrCreate(change, "tx_hash = ? AND log_index = ?", change.TxHash, change.LogIndex).Error
+}
+
+// 查询用户的声誉变更历史（最新在前）
+func (r *Repository) ListReputationChanges(address string, limit int) ([]model.ReputationChange, error) {
+	var changes []model.ReputationChange
+	query := r.read().Where("wallet_address = ?", strings.ToLower(address)).Order("block_number DESC, log_index DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&changes).Error
+	return changes, err
+}
+
+// rebuildUserProfiles 链重组回滚后，用剩余的已确认事件重建在分叉点之后更新过的用户档案
+func rebuildUserProfiles(tx *gorm.DB, number uint64) error {
+	var stale []model.UserProfile
+	if err := tx.Where("block_number >= ?", number).Find(&stale).Error; err != nil {
+		return err
+	}
+
+	for _, old := range stale {
+		var events []model.EventLog
+		err := tx.Where("event_name IN ? AND entity_id = ? AND status = ?", model.UserEvents, old.WalletAddress, model.EventStatusConfirmed).
+			Order("block_number ASC, log_index ASC").Find(&events).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&model.UserProfile{}, old.ID).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			if err := refreshAuthoredResearchText(tx, old.WalletAddress); err != nil {
+				return err
+			}
+			continue
+		}
+
+		profile := &model.UserProfile{WalletAddress: old.WalletAddress}
+		for _, event := range events {
+			payload, err := model.ParseUserEventPayload(event.EventName, event.PayloadRaw)
+			if err != nil {
+				return err
+			}
+			profile.Apply(event.EventName, payload, event.BlockNumber)
+		}
+		if err := tx.Create(profile).Error; err != nil {
+			return err
+		}
+		if err := refreshAuthoredResearchText(tx, old.WalletAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
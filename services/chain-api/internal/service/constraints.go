@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RuleResult 验证规则在某个数据集上的得分，按合约 evaluateRule 的方式计算
+type RuleResult struct {
+	RuleID   string `json:"rule_id"`
+	Name     string `json:"name"`
+	Score    uint64 `json:"score"`
+	MinScore string `json:"min_score"`
+	Passed   bool   `json:"passed"`
+	// Evaluated 规则涉及的约束中已有评估结果的数量
+	Evaluated int `json:"evaluated"`
+}
+
+// constraintEvents ConstraintManager 事件
+var constraintEvents = []string{
+	"ConstraintCreated", "ConstraintUpdated", "ConstraintGroupCreated",
+	"ValidationRuleCreated", "ConstraintEvaluated",
+}
+
+// 处理约束事件：约束事件维护约束目录，组和规则创建后不可修改，评估结果按时间序列保存
+func (s *Service) processConstraintEvent(eventLog *model.EventLog) error {
+	var e model.ConstraintEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+
+	switch eventLog.EventName {
+	case "ConstraintGroupCreated":
+		if e.GroupID == "" {
+			return fmt.Errorf("%s event without group id", eventLog.EventName)
+		}
+		return s.repo.InsertConstraintGroup(&model.ConstraintGroup{
+			GroupID:         e.GroupID,
+			Name:            e.Name,
+			Description:     e.Description,
+			ConstraintIDs:   e.ConstraintIDs,
+			MinSatisfaction: e.MinSatisfaction,
+			TotalWeight:     e.TotalWeight,
+			IsActive:        e.IsActive == nil || *e.IsActive,
+			Creator:         strings.ToLower(e.Creator),
+			BlockNumber:     eventLog.BlockNumber,
+		})
+	case "ValidationRuleCreated":
+		if e.RuleID == "" {
+			return fmt.Errorf("%s event without rule id", eventLog.EventName)
+		}
+		return s.repo.InsertValidationRule(&model.ValidationRule{
+			RuleID:      e.RuleID,
+			Name:        e.Name,
+			Description: e.Description,
+			GroupIDs:    e.GroupIDs,
+			MinScore:    e.MinScore,
+			IsActive:    e.IsActive == nil || *e.IsActive,
+			Creator:     strings.ToLower(e.Creator),
+			BlockNumber: eventLog.BlockNumber,
+		})
+	case "ConstraintEvaluated":
+		// 当前合约的评估函数都是 view，不会发出该事件；保留处理以兼容后续版本
+		if e.ConstraintID == "" {
+			return fmt.Errorf("%s event without constraint id", eventLog.EventName)
+		}
+		return s.repo.InsertConstraintEvaluation(&model.ConstraintEvaluation{
+			ConstraintID: e.ConstraintID,
+			Result:       e.Result,
+			Score:        e.Score,
+			EvaluatedAt:  model.UnixTime(e.BlockTime),
+			BlockNumber:  eventLog.BlockNumber,
+			TxHash:       eventLog.TxHash,
+			LogIndex:     eventLog.LogIndex,
+		})
+	}
+
+	if e.ConstraintID == "" {
+		return fmt.Errorf("%s event without constraint id", eventLog.EventName)
+	}
+	return s.repo.WithTx(context.Background(), func(tx repository.IRepository) error {
+		constraint, err := tx.GetConstraint(e.ConstraintID)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			constraint = &model.Constraint{ConstraintID: e.ConstraintID}
+		}
+		constraint.Apply(eventLog.EventName, &e, eventLog.BlockNumber)
+		return tx.SaveConstraint(constraint)
+	})
+}
+
+// GetConstraint 获取约束及其评估历史
+func (s *Service) GetConstraint(constraintID string, limit int) (*model.Constraint, []model.ConstraintEvaluation, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	constraint, err := s.repo.GetConstraint(constraintID)
+	if err != nil {
+		return nil, nil, err
+	}
+	evaluations, err := s.repo.ListConstraintEvaluations(constraintID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return constraint, evaluations, nil
+}
+
+// ListConstraints 获取约束目录
+func (s *Service) ListConstraints(category string, activeOnly bool) ([]model.Constraint, error) {
+	return s.repo.ListConstraints(category, activeOnly)
+}
+
+// ListConstraintGroups 获取约束组
+func (s *Service) ListConstraintGroups() ([]model.ConstraintGroup, error) {
+	return s.repo.ListConstraintGroups()
+}
+
+// ListValidationRules 获取验证规则
+func (s *Service) ListValidationRules() ([]model.ValidationRule, error) {
+	return s.repo.ListValidationRules()
+}
+
+// GetDatasetConstraints 分页获取数据集的约束评估结果，并用每个约束最近一次的结果计算各验证规则的得分
+func (s *Service) GetDatasetConstraints(datasetID string, page repository.PageRequest) (*repository.Page[model.ConstraintEvaluation], []RuleResult, error) {
+	evaluations, err := s.repo.ListDatasetConstraintEvaluations(datasetID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	latest, err := s.repo.ListLatestDatasetConstraintResults(datasetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := s.repo.ListConstraintGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, err := s.repo.ListValidationRules()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupByID := make(map[string]model.ConstraintGroup, len(groups))
+	for _, g := range groups {
+		groupByID[g.GroupID] = g
+	}
+
+	results := make([]RuleResult, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		result := RuleResult{RuleID: rule.RuleID, Name: rule.Name, MinScore: rule.MinScore}
+		var total uint64
+		for _, groupID := range rule.GroupIDs {
+			group, ok := groupByID[groupID]
+			if !ok || len(group.ConstraintIDs) == 0 {
+				continue
+			}
+			var satisfied uint64
+			for _, constraintID := range group.ConstraintIDs {
+				passed, ok := latest[constraintID]
+				if !ok {
+					continue
+				}
+				result.Evaluated++
+				if passed {
+					satisfied++
+				}
+			}
+			total += satisfied * 100 / uint64(len(group.ConstraintIDs))
+		}
+		if len(rule.GroupIDs) > 0 {
+			result.Score = total / uint64(len(rule.GroupIDs))
+		}
+		minScore, _ := strconv.ParseUint(rule.MinScore, 10, 64)
+		result.Passed = result.Score >= minScore
+		results = append(results, result)
+	}
+	return evaluations, results, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// datasetActivityEvents DatasetManager 的访问、引用、质量和收益事件
+var datasetActivityEvents = []string{"DatasetAccessed", "DatasetCited", "QualityUpdated", "DatasetRevenueDistributed"}
+
+// 处理 DatasetManager 的访问、引用、质量和收益事件
+func (s *Service) processDatasetActivity(eventLog *model.EventLog) error {
+	var e model.DatasetEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+	if e.DatasetID == "" {
+		return fmt.Errorf("%s event without dataset id", eventLog.EventName)
+	}
+
+	switch eventLog.EventName {
+	case "DatasetAccessed":
+		return s.repo.InsertDatasetAccess(&model.DatasetAccess{
+			DatasetID:   e.DatasetID,
+			User:        e.User,
+			PricePaid:   e.PricePaid,
+			AccessedAt:  model.EventTime(e.Timestamp),
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "DatasetCited":
+		return s.repo.InsertDatasetCitation(&model.DatasetCitation{
+			DatasetID:       e.DatasetID,
+			Researcher:      e.Researcher,
+			PublicationHash: e.PublicationHash,
+			CitedAt:         model.EventTime(e.Timestamp),
+			BlockNumber:     eventLog.BlockNumber,
+			TxHash:          eventLog.TxHash,
+			LogIndex:        eventLog.LogIndex,
+		})
+	case "QualityUpdated":
+		return s.repo.InsertDatasetQualityChange(&model.DatasetQualityChange{
+			DatasetID:   e.DatasetID,
+			OldLevel:    e.OldLevel,
+			NewLevel:    e.NewLevel,
+			LevelName:   model.QualityLevelName(e.NewLevel),
+			Verifier:    e.Verifier,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "DatasetRevenueDistributed":
+		return s.repo.InsertDatasetRevenue(&model.DatasetRevenue{
+			DatasetID:     e.DatasetID,
+			Owner:         e.Owner,
+			OwnerShare:    e.OwnerShare,
+			PlatformShare: e.PlatformShare,
+			BlockNumber:   eventLog.BlockNumber,
+			TxHash:        eventLog.TxHash,
+			LogIndex:      eventLog.LogIndex,
+		})
+	}
+	return nil
+}
+
+// GetDatasetAccesses 分页获取数据集的访问（购买）记录
+func (s *Service) GetDatasetAccesses(datasetID string, page repository.PageRequest) (*repository.Page[model.DatasetAccess], error) {
+	return s.repo.ListDatasetAccesses(datasetID, page)
+}
+
+// GetDatasetCitations 分页获取数据集的引用记录
+func (s *Service) GetDatasetCitations(datasetID string, page repository.PageRequest) (*repository.Page[model.DatasetCitation], error) {
+	return s.repo.ListDatasetCitations(datasetID, page)
+}
+
+// GetDatasetQualityHistory 分页获取数据集的质量变更历史，并返回当前等级对应的最新变更（没有时为 nil）
+func (s *Service) GetDatasetQualityHistory(datasetID string, page repository.PageRequest) (*repository.Page[model.DatasetQualityChange], *model.DatasetQualityChange, error) {
+	history, err := s.repo.ListDatasetQualityChanges(datasetID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := latestOrNil(s.repo.GetLatestDatasetQualityChange(datasetID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, current, nil
+}
+
+// GetDatasetRevenue 汇总数据集的收益分配（uint256 金额在内存中累加）
+func (s *Service) GetDatasetRevenue(datasetID string) (*model.DatasetRevenueSummary, error) {
+	history, err := s.repo.ListDatasetRevenue(datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerTotal, platformTotal := new(big.Int), new(big.Int)
+	for _, r := range history {
+		ownerTotal.Add(ownerTotal, repository.DecimalAmount(r.OwnerShare))
+		platformTotal.Add(platformTotal, repository.DecimalAmount(r.PlatformShare))
+	}
+
+	return &model.DatasetRevenueSummary{
+		DatasetID:          datasetID,
+		TotalOwnerShare:    ownerTotal.String(),
+		TotalPlatformShare: platformTotal.String(),
+		TotalRevenue:       new(big.Int).Add(ownerTotal, platformTotal).String(),
+		Distributions:      len(history),
+		History:            history,
+	}, nil
+}
+
+// latestOrNil 将“最新一条记录”查询的未找到错误转换为 nil 结果
+func latestOrNil[T any](record *T, err error) (*T, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+)
+
+// defaultDeadLetterAttempts 默认在第几次物化失败后转入死信队列
+const defaultDeadLetterAttempts = 5
+
+var (
+	// ErrDeadLetterClosed 死信已重试成功或已丢弃
+	ErrDeadLetterClosed = errors.New("dead letter is already resolved or discarded")
+	// ErrRetryFailed 重试时事件仍然处理失败
+	ErrRetryFailed = errors.New("retry failed")
+)
+
+// SetDeadLetterAttempts 设置事件转入死信队列前的最大物化失败次数
+func (s *Service) SetDeadLetterAttempts(n int) {
+	if n > 0 {
+		s.deadLetterAttempts = n
+	}
+}
+
+func (s *Service) maxAttempts() int {
+	if s.deadLetterAttempts <= 0 {
+		return defaultDeadLetterAttempts
+	}
+	return s.deadLetterAttempts
+}
+
+// errorStack 返回处理器名称及panic时的调用栈
+func errorStack(err error) string {
+	var herr *HandlerError
+	if !errors.As(err, &herr) {
+		return ""
+	}
+	if herr.Stack == "" {
+		return "handler: " + herr.Handler
+	}
+	return "handler: " + herr.Handler + "\n" + herr.Stack
+}
+
+// ListDeadLetters 按状态分页查询死信
+func (s *Service) ListDeadLetters(status string, page repository.PageRequest) (*repository.Page[model.DeadLetter], error) {
+	return s.repo.ListDeadLetters(status, page)
+}
+
+// GetDeadLetter 查询死信详情
+func (s *Service) GetDeadLetter(id uint) (*model.DeadLetter, error) {
+	return s.repo.GetDeadLetter(id)
+}
+
+// RetryDeadLetter 立即重新处理死信中的事件：物化失败的事件重新物化，
+// 监听器入库失败的事件从保存的 ParsedEvent 重新入库；成功后标记为已解决
+func (s *Service) RetryDeadLetter(id uint) (*model.DeadLetter, error) {
+	letter, err := s.repo.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Status != model.DeadLetterStatusDead {
+		return letter, ErrDeadLetterClosed
+	}
+
+	if letter.Source == model.DeadLetterSourceListener {
+		return s.retryIngest(letter)
+	}
+
+	event, err := s.repo.GetEventLog(letter.EventLogID)
+	if err != nil {
+		return letter, err
+	}
+	if !event.Processed {
+		// 手动重试忽略退避时间；失败时会更新同一条死信的错误和尝试次数
+		if err := s.applyEvent(event, true); err != nil {
+			if latest, getErr := s.repo.GetDeadLetter(id); getErr == nil {
+				letter = latest
+			}
+			return letter, fmt.Errorf("%w: %v", ErrRetryFailed, err)
+		}
+	}
+	letter.Status = model.DeadLetterStatusResolved
+	return letter, s.repo.UpdateDeadLetter(letter)
+}
+
+// retryIngest 重新入库监听器阶段失败的事件
+func (s *Service) retryIngest(letter *model.DeadLetter) (*model.DeadLetter, error) {
+	var event model.ParsedEvent
+	if err := json.Unmarshal([]byte(letter.PayloadRaw), &event); err != nil {
+		return letter, fmt.Errorf("decode dead letter payload: %w", err)
+	}
+
+	letter.Attempts++
+	if err := s.IngestEvent(&event); err != nil {
+		letter.Error = err.Error()
+		if updateErr := s.repo.UpdateDeadLetter(letter); updateErr != nil {
+			log.Printf("Failed to update dead letter %d: %v", letter.ID, updateErr)
+		}
+		return letter, fmt.Errorf("%w: %v", ErrRetryFailed, err)
+	}
+	letter.Status = model.DeadLetterStatusResolved
+	return letter, s.repo.UpdateDeadLetter(letter)
+}
+
+// DiscardDeadLetter 丢弃死信，对应事件不再重试
+func (s *Service) DiscardDeadLetter(id uint) (*model.DeadLetter, error) {
+	letter, err := s.repo.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.Status != model.DeadLetterStatusDead {
+		return letter, ErrDeadLetterClosed
+	}
+	letter.Status = model.DeadLetterStatusDiscarded
+	return letter, s.repo.UpdateDeadLetter(letter)
+}
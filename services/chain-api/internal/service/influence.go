@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+)
+
+// influenceEvents InfluenceRanking/DeSciPlatform 的影响力、奖励和合作事件
+var influenceEvents = []string{
+	"InfluenceUpdated", "RankingUpdated", "WeightsUpdated",
+	"InfluenceRankingUpdated", "RewardDistributed", "CollaborationFormed",
+}
+
+// 处理影响力事件：影响力/排名变化、权重配置、排名刷新、奖励和合作关系分别按时间序列保存
+func (s *Service) processInfluenceEvent(eventLog *model.EventLog) error {
+	var e model.InfluenceEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+	blockTime := model.UnixTime(e.BlockTime)
+
+	switch eventLog.EventName {
+	case "InfluenceUpdated", "InfluenceRankingUpdated":
+		if e.User == "" {
+			return fmt.Errorf("%s event without user", eventLog.EventName)
+		}
+		return s.repo.InsertInfluenceChange(&model.InfluenceChange{
+			User:               e.User,
+			EventName:          eventLog.EventName,
+			OldInfluence:       e.OldInfluence,
+			NewInfluence:       e.NewInfluence,
+			OldRank:            e.OldRank,
+			NewRank:            e.NewRank,
+			PublicationScore:   e.PublicationScore,
+			ReviewScore:        e.ReviewScore,
+			DataContribution:   e.DataContribution,
+			CollaborationScore: e.CollaborationScore,
+			GovernanceScore:    e.GovernanceScore,
+			ChangedAt:          blockTime,
+			BlockNumber:        eventLog.BlockNumber,
+			TxHash:             eventLog.TxHash,
+			LogIndex:           eventLog.LogIndex,
+		})
+	case "WeightsUpdated":
+		return s.repo.InsertInfluenceWeights(&model.InfluenceWeights{
+			PublicationWeight:   e.PublicationWeight,
+			ReviewWeight:        e.ReviewWeight,
+			DataWeight:          e.DataWeight,
+			CollaborationWeight: e.CollaborationWeight,
+			GovernanceWeight:    e.GovernanceWeight,
+			ChangedAt:           blockTime,
+			BlockNumber:         eventLog.BlockNumber,
+			TxHash:              eventLog.TxHash,
+			LogIndex:            eventLog.LogIndex,
+		})
+	case "RankingUpdated":
+		refreshedAt := model.EventTime(e.Timestamp)
+		if refreshedAt == nil {
+			refreshedAt = blockTime
+		}
+		return s.repo.InsertRankingRefresh(&model.RankingRefresh{
+			RankingType:     e.RankingType,
+			RankingTypeName: model.RankingTypeName(e.RankingType),
+			Identifier:      e.Identifier,
+			RefreshedAt:     refreshedAt,
+			BlockNumber:     eventLog.BlockNumber,
+			TxHash:          eventLog.TxHash,
+			LogIndex:        eventLog.LogIndex,
+		})
+	case "RewardDistributed":
+		if e.User == "" {
+			return fmt.Errorf("%s event without user", eventLog.EventName)
+		}
+		return s.repo.InsertRewardDistribution(&model.RewardDistribution{
+			User:          e.User,
+			Amount:        e.Amount,
+			Reason:        e.Reason,
+			DistributedAt: blockTime,
+			BlockNumber:   eventLog.BlockNumber,
+			TxHash:        eventLog.TxHash,
+			LogIndex:      eventLog.LogIndex,
+		})
+	case "CollaborationFormed":
+		return s.repo.InsertCollaboration(&model.Collaboration{
+			User1:       e.User1,
+			User2:       e.User2,
+			ResearchID:  e.ResearchID,
+			FormedAt:    blockTime,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	}
+	return nil
+}
+
+// GetInfluenceLeaderboard 按最新总影响力从高到低返回用户
+func (s *Service) GetInfluenceLeaderboard(page repository.PageRequest) (*repository.Page[model.InfluenceChange], error) {
+	return s.repo.ListLatestInfluence(page)
+}
+
+// GetUserInfluence 分页获取用户的影响力变化历史，并返回最新一条作为当前状态（没有时为 nil）
+func (s *Service) GetUserInfluence(address string, page repository.PageRequest) (*repository.Page[model.InfluenceChange], *model.InfluenceChange, error) {
+	history, err := s.repo.ListInfluenceChanges(address, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := latestOrNil(s.repo.GetLatestInfluenceChange(address))
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, current, nil
+}
+
+// GetInfluenceWeights 分页获取权重配置变更记录，并返回当前生效的配置（没有时为 nil）
+func (s *Service) GetInfluenceWeights(page repository.PageRequest) (*repository.Page[model.InfluenceWeights], *model.InfluenceWeights, error) {
+	history, err := s.repo.ListInfluenceWeights(page)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := latestOrNil(s.repo.GetLatestInfluenceWeights())
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, current, nil
+}
+
+// GetRankingRefreshes 分页获取排名刷新记录
+func (s *Service) GetRankingRefreshes(page repository.PageRequest) (*repository.Page[model.RankingRefresh], error) {
+	return s.repo.ListRankingRefreshes(page)
+}
+
+// GetCollaborations 分页获取合作关系边，address 非空时只返回该用户的合作
+func (s *Service) GetCollaborations(address string, page repository.PageRequest) (*repository.Page[model.Collaboration], error) {
+	return s.repo.ListCollaborations(address, page)
+}
+
+// GetRewards 分页获取最近的奖励发放流水
+func (s *Service) GetRewards(page repository.PageRequest) (*repository.Page[model.RewardDistribution], error) {
+	return s.repo.ListRewardDistributions("", page)
+}
+
+// GetUserRewards 汇总用户获得的奖励（uint256 金额在内存中累加）
+func (s *Service) GetUserRewards(address string) (*model.RewardSummary, error) {
+	history, err := s.repo.ListUserRewards(address)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	for _, r := range history {
+		total.Add(total, repository.DecimalAmount(r.Amount))
+	}
+	return &model.RewardSummary{
+		User:          address,
+		TotalRewards:  total.String(),
+		Distributions: len(history),
+		History:       history,
+	}, nil
+}
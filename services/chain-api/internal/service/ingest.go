@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"desci-backend/internal/model"
+)
+
+// IngestEvent 将监听器解析的事件写入 event_logs 并物化；物化失败只记录错误，由重放任务重试，
+// 返回的错误表示事件未能入库
+func (s *Service) IngestEvent(event *model.ParsedEvent) error {
+	// 事件名已由监听器按注册表规范化，载荷为ABI解码的全部参数加区块时间
+	payload := map[string]interface{}{"blockTimestamp": event.BlockTime}
+	for k, v := range event.Args {
+		payload[k] = v
+	}
+	if len(event.Args) == 0 {
+		payload["tokenId"] = event.TokenID
+		payload["title"] = event.Title
+		payload["description"] = event.Description
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+
+	// 保留原始日志以便脱离链重新处理
+	var topic0 string
+	if len(event.Topics) > 0 {
+		topic0 = event.Topics[0]
+	}
+	eventLog := &model.EventLog{
+		TxHash:       event.TxHash,
+		LogIndex:     event.LogIndex,
+		BlockNumber:  event.Block,
+		EventName:    event.EventName,
+		EntityID:     event.EntityID,
+		Topic0:       topic0,
+		ContractAddr: strings.ToLower(event.Contract),
+		BlockHash:    event.BlockHash,
+		BlockTime:    model.UnixTime(event.BlockTime),
+		Topics:       event.Topics,
+		Data:         event.Data,
+		Args:         event.DecodedArgs,
+		PayloadRaw:   string(b),
+		Status:       model.EventStatusConfirmed,
+		CreatedAt:    time.Now(),
+	}
+	if s.confirmations > 0 {
+		eventLog.Status = model.EventStatusPending
+	}
+
+	if err := s.repo.InsertEventLog(eventLog); err != nil {
+		return fmt.Errorf("insert event log: %w", err)
+	}
+	log.Printf("📝 Event log inserted: %s", event.EventName)
+
+	// 确认模式下由确认器在达到确认深度后物化
+	if eventLog.Status == model.EventStatusPending {
+		log.Printf("⏳ Event %s pending %d confirmations", event.EventName, s.confirmations)
+		return nil
+	}
+	// 交由订阅了该事件的服务处理；失败时记录错误，由重放任务重试
+	if !s.HandlesEvent(event.EventName) {
+		log.Printf("ℹ️  Event logged only: %s", event.EventName)
+		return nil
+	}
+	if err := s.ApplyEvent(eventLog); err != nil {
+		log.Printf("⚠️  Service processing failed, scheduled for replay: %v", err)
+		return nil
+	}
+	log.Printf("✅ Service processed and marked event: %s", event.EventName)
+	return nil
+}
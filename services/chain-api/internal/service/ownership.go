@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// transferCollections Transfer 事件名对应的 NFT 集合
+var transferCollections = map[string]string{
+	"ResearchTransfer": model.CollectionResearch,
+	"DatasetTransfer":  model.CollectionDataset,
+}
+
+// 处理 ERC-721 Transfer 事件（含铸造和销毁）
+func (s *Service) processNFTTransfer(eventLog *model.EventLog) error {
+	var e model.TransferEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+	if e.TokenID == "" {
+		return fmt.Errorf("%s event without token id", eventLog.EventName)
+	}
+
+	return s.repo.ApplyNFTTransfer(&model.NFTTransfer{
+		Collection:  transferCollections[eventLog.EventName],
+		TokenID:     e.TokenID,
+		FromAddress: e.From,
+		ToAddress:   e.To,
+		BlockNumber: eventLog.BlockNumber,
+		TxHash:      eventLog.TxHash,
+		LogIndex:    eventLog.LogIndex,
+	})
+}
+
+// currentOwner 返回已索引的 token 持有人，未知时返回空
+func (s *Service) currentOwner(collection, tokenID string) (string, error) {
+	owner, err := s.repo.GetNFTOwner(collection, tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return owner.Owner, nil
+}
+
+// GetNFTOwnership 获取 token 的当前持有人及完整流转记录
+func (s *Service) GetNFTOwnership(collection, tokenID string) (*model.NFTOwner, []model.NFTTransfer, error) {
+	owner, err := s.repo.GetNFTOwner(collection, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+	transfers, err := s.repo.ListNFTTransfers(collection, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return owner, transfers, nil
+}
+
+// GetTokensByOwner 分页获取地址当前持有的 token
+func (s *Service) GetTokensByOwner(owner, collection string, page repository.PageRequest) (*repository.Page[model.NFTOwner], error) {
+	return s.repo.ListNFTsByOwner(owner, collection, page)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/verify"
+	"desci-backend/internal/zkp"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrProofChainNotConfigured 未配置证明合约时无法进行链下校验
+var ErrProofChainNotConfigured = errors.New("proof contracts not configured")
+
+// ErrProofNotOnChain ZKProof 合约中不存在该证明
+var ErrProofNotOnChain = errors.New("proof not found on-chain")
+
+// ProofChain 链下校验所需的合约数据源（由 zkp.Reader 实现）
+type ProofChain interface {
+	GetSubmittedProof(ctx context.Context, proofID string) (*zkp.SubmittedProof, error)
+	GetVerifyingKey(ctx context.Context, proofType string) (*verify.VerifyingKey, error)
+	GetVerificationResult(ctx context.Context, proofHash common.Hash) (bool, uint64, error)
+}
+
+// SetProofChain 设置证明合约数据源，启用链下 Groth16 校验
+func (s *Service) SetProofChain(chain ProofChain) {
+	s.proofChain = chain
+}
+
+// CheckProof 读取证明和证明类型的验证密钥，在链下执行 Groth16 校验，
+// 并与 ZKPVerifier.verificationResults 交叉核对后保存结果；记录的提交者或证明类型与链上不一致时以链上为准
+func (s *Service) CheckProof(ctx context.Context, proofID string) (*model.ZKProof, error) {
+	if s.proofChain == nil {
+		return nil, ErrProofChainNotConfigured
+	}
+	stored, err := s.repo.GetProof(proofID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted, err := s.proofChain.GetSubmittedProof(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+	if submitted == nil || submitted.Submitter == "" || strings.EqualFold(submitted.Submitter, model.ZeroAddress) {
+		return nil, ErrProofNotOnChain
+	}
+	vk, err := s.proofChain.GetVerifyingKey(ctx, submitted.ProofType)
+	if err != nil {
+		return nil, err
+	}
+
+	status, reason := model.ProofCheckValid, ""
+	if err := verify.VerifyGroth16(vk, submitted.Proof, submitted.PublicInputs); err != nil {
+		status, reason = model.ProofCheckInvalid, err.Error()
+	}
+
+	hash := verify.Groth16ProofHash(submitted.ProofType, submitted.Proof, submitted.PublicInputs)
+	onchainValid, verifiedAt, err := s.proofChain.GetVerificationResult(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	onchain := model.ProofCheckUnknown
+	if verifiedAt > 0 {
+		onchain = model.ProofCheckInvalid
+		if onchainValid {
+			onchain = model.ProofCheckValid
+		}
+	}
+	// 合约还会校验业务约束，链上判定无效时以链上为准
+	if status == model.ProofCheckValid && onchain == model.ProofCheckInvalid {
+		reason = "ZKPVerifier recorded the proof as invalid"
+	}
+
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"proof_hash":     hash.Hex(),
+		"check_status":   status,
+		"check_reason":   reason,
+		"onchain_result": onchain,
+		"checked_at":     &now,
+		"trusted":        status == model.ProofCheckValid && onchain == model.ProofCheckValid,
+	}
+	if !strings.EqualFold(stored.Submitter, submitted.Submitter) || stored.ProofType != submitted.ProofType {
+		log.Printf("⚠️  [ZKP] Proof %s does not match on-chain record (submitter %s/%s, type %s/%s), overwriting",
+			proofID, stored.Submitter, submitted.Submitter, stored.ProofType, submitted.ProofType)
+		updates["submitter"] = strings.ToLower(submitted.Submitter)
+		updates["proof_type"] = submitted.ProofType
+	}
+	if err := s.repo.UpdateProof(proofID, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.GetProof(proofID)
+}
+
+// maxProofCheckAttempts 链上始终没有记录或核对持续失败的证明，自动核对的最大次数；之后仅可手动核对
+const maxProofCheckAttempts = 20
+
+// proofCheckBatch 每轮最多自动核对的证明数
+const proofCheckBatch = 50
+
+// RunProofChecker 周期性校验新索引的证明，并重新核对链上尚无记录的证明，直到ctx取消
+func (s *Service) RunProofChecker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.CheckPendingProofs(ctx); err != nil {
+				log.Printf("Proof checker: failed to list proofs: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckPendingProofs 核对一批到期的证明，返回核对的数量；核对失败或链上仍无记录时按重放的退避策略推迟下次核对
+func (s *Service) CheckPendingProofs(ctx context.Context) (int, error) {
+	proofs, err := s.repo.ListProofsToCheck(time.Now(), maxProofCheckAttempts, proofCheckBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range proofs {
+		checked, err := s.CheckProof(ctx, p.ProofID)
+		if err != nil {
+			log.Printf("Proof checker: proof %s: %v", p.ProofID, err)
+		} else if checked.CheckStatus != p.CheckStatus || checked.OnchainResult != p.OnchainResult {
+			log.Printf("🔍 [ZKP] Proof %s checked: %s (on-chain %s)", p.ProofID, checked.CheckStatus, checked.OnchainResult)
+		}
+		if err == nil && checked.OnchainResult != model.ProofCheckUnknown {
+			continue
+		}
+
+		attempts := p.CheckAttempts + 1
+		next := time.Now().Add(s.backoff(attempts))
+		if err := s.repo.UpdateProof(p.ProofID, map[string]interface{}{"check_attempts": attempts, "next_check_at": &next}); err != nil {
+			log.Printf("Proof checker: failed to schedule proof %s: %v", p.ProofID, err)
+		}
+		if attempts >= maxProofCheckAttempts {
+			log.Printf("⚠️  [ZKP] Proof %s still unverified on-chain after %d checks, automatic checks stopped", p.ProofID, attempts)
+		}
+	}
+	return len(proofs), nil
+}
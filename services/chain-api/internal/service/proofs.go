@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// proofEvents ZKProof/ZKPVerifier 的证明生命周期事件
+var proofEvents = []string{
+	"ProofSubmitted", "ProofVerified", "ProofHashVerified",
+	"ProofTypeAdded", "ProofTypeUpdated", "ProofTypeRegistered",
+}
+
+// 处理证明事件：提交生成证明记录，验证结果同步状态，类型事件维护证明类型目录
+func (s *Service) processProofEvent(eventLog *model.EventLog) error {
+	var e model.ProofEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+
+	switch eventLog.EventName {
+	case "ProofSubmitted":
+		if e.ProofID == "" {
+			return fmt.Errorf("%s event without proof id", eventLog.EventName)
+		}
+		// 证明记录只来自合约发出的事件；模拟接口写入的事件（零地址）不进入证明投影，避免抢占链上的 proofId
+		if eventLog.ContractAddr == "" || eventLog.ContractAddr == model.ZeroAddress {
+			log.Printf("Skipping %s for proof %s: not emitted by a contract", eventLog.EventName, e.ProofID)
+			return nil
+		}
+		return s.repo.InsertProof(&model.ZKProof{
+			ProofID:      e.ProofID,
+			Submitter:    e.Submitter,
+			ProofType:    e.ProofType,
+			MetadataHash: e.MetadataHash,
+			Status:       model.ProofStatusSubmitted,
+			SubmittedAt:  model.UnixTime(e.BlockTime),
+			BlockNumber:  eventLog.BlockNumber,
+			TxHash:       eventLog.TxHash,
+		})
+	case "ProofVerified":
+		if e.ProofID == "" {
+			return fmt.Errorf("%s event without proof id", eventLog.EventName)
+		}
+		return s.repo.InsertProofVerification(&model.ProofVerification{
+			Source:      model.ProofSourceZKProof,
+			ProofID:     e.ProofID,
+			IsValid:     e.IsValid,
+			Verifier:    e.Verifier,
+			VerifiedAt:  model.UnixTime(e.BlockTime),
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "ProofHashVerified":
+		// ZKPVerifier 按证明哈希记录结果，无法关联到 ZKProof 的 proofId
+		return s.repo.InsertProofVerification(&model.ProofVerification{
+			Source:      model.ProofSourceZKPVerifier,
+			ProofHash:   e.ProofHash,
+			IsValid:     e.IsValid,
+			VerifiedAt:  model.EventTime(e.Timestamp),
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	}
+
+	if e.ProofType == "" {
+		return fmt.Errorf("%s event without proof type", eventLog.EventName)
+	}
+	return s.repo.WithTx(context.Background(), func(tx repository.IRepository) error {
+		proofType, err := tx.GetProofType(e.ProofType)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			proofType = &model.ProofType{Name: e.ProofType}
+		}
+		proofType.Apply(eventLog.EventName, &e, eventLog.BlockNumber)
+		return tx.SaveProofType(proofType)
+	})
+}
+
+// GetProof 获取证明及其验证记录
+func (s *Service) GetProof(proofID string) (*model.ZKProof, []model.ProofVerification, error) {
+	proof, err := s.repo.GetProof(proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	verifications, err := s.repo.ListProofVerifications(proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, verifications, nil
+}
+
+// ListProofs 分页获取证明列表
+func (s *Service) ListProofs(status, proofType string, page repository.PageRequest) (*repository.Page[model.ZKProof], error) {
+	return s.repo.ListProofs(status, proofType, page)
+}
+
+// GetProofsBySubmitter 分页获取地址提交的证明
+func (s *Service) GetProofsBySubmitter(submitter string, page repository.PageRequest) (*repository.Page[model.ZKProof], error) {
+	return s.repo.ListProofsBySubmitter(submitter, page)
+}
+
+// ListProofTypes 获取证明类型目录
+func (s *Service) ListProofTypes() ([]model.ProofType, error) {
+	return s.repo.ListProofTypes()
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// dataQualityEvents DataFeatureExtractor/ResearchDataVerifier 的质量指标事件
+var dataQualityEvents = []string{
+	"FeaturesCalculated", "StatisticalMetricsUpdated",
+	"DataSubmitted", "DataVerified", "FeaturesExtracted", "ConstraintAdded",
+}
+
+// 处理质量指标事件：每条事件记录一行指标，并合并到对应数据/特征的质量投影
+func (s *Service) processDataQualityEvent(eventLog *model.EventLog) error {
+	var e model.DataQualityEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+
+	if eventLog.EventName == "ConstraintAdded" {
+		return s.repo.InsertVerifierConstraint(&model.VerifierConstraint{
+			ConstraintType: e.ConstraintType,
+			Threshold:      e.Threshold,
+			Description:    e.Description,
+			BlockNumber:    eventLog.BlockNumber,
+			TxHash:         eventLog.TxHash,
+			LogIndex:       eventLog.LogIndex,
+		})
+	}
+
+	metric := &model.DataQualityMetric{
+		EventName:          eventLog.EventName,
+		DataHash:           e.DataHash,
+		FeatureHash:        e.FeatureHash,
+		DataType:           e.DataTypeName(),
+		DataCount:          e.DataCount,
+		Mean:               e.Mean,
+		StandardDeviation:  e.StandardDeviation,
+		MinValue:           e.MinValue,
+		MaxValue:           e.MaxValue,
+		Score:              e.Score,
+		IsValid:            e.IsValid,
+		VerificationStatus: e.Status,
+		RecordedAt:         model.UnixTime(e.BlockTime),
+		BlockNumber:        eventLog.BlockNumber,
+		TxHash:             eventLog.TxHash,
+		LogIndex:           eventLog.LogIndex,
+	}
+	switch eventLog.EventName {
+	case "FeaturesCalculated", "StatisticalMetricsUpdated":
+		metric.Source = model.DataQualitySourceExtractor
+		metric.SubjectID = e.FeatureID
+		metric.Submitter = e.Calculator
+	default:
+		metric.Source = model.DataQualitySourceVerifier
+		metric.SubjectID = e.DataID
+		metric.Submitter = e.Submitter
+		if eventLog.EventName == "DataSubmitted" {
+			metric.VerificationStatus = "pending"
+		}
+	}
+	if metric.SubjectID == "" {
+		return fmt.Errorf("%s event without data id", eventLog.EventName)
+	}
+	return s.repo.InsertDataQualityMetric(metric)
+}
+
+// GetDatasetFeatures 获取与数据集关联的质量投影及指标历史，并返回验证器的约束阈值
+func (s *Service) GetDatasetFeatures(datasetID string, limit int) ([]model.DataQualityFeatures, []model.VerifierConstraint, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	thresholds, err := s.repo.ListVerifierConstraints()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 数据集记录不存在时仍可按交易关联
+	var dataHash string
+	record, err := s.repo.GetDatasetRecord(datasetID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if err == nil {
+		dataHash = record.DataHash
+	}
+
+	qualities, err := s.repo.ListDatasetDataQuality(datasetID, dataHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	features := make([]model.DataQualityFeatures, 0, len(qualities))
+	for _, q := range qualities {
+		history, err := s.repo.ListDataQualityMetrics(q.Source, q.SubjectID, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		features = append(features, model.DataQualityFeatures{DataQuality: q, History: history})
+	}
+	return features, thresholds, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"desci-backend/internal/model"
+)
+
+const (
+	defaultReplayBackoff    = 10 * time.Second
+	defaultReplayMaxBackoff = time.Hour
+)
+
+// eventLease 物化前领取事件的租约时长：期间重放任务不会再次领取该事件，进程在物化中途退出时租约到期后由重放任务接手
+const eventLease = 5 * time.Minute
+
+// errEventClaimed 事件已被其他调用方领取或已处理
+var errEventClaimed = errors.New("event already claimed")
+
+// ReplayResult 一次重放或重建的处理结果
+type ReplayResult struct {
+	// Reset 重建前被清空派生数据、重置为未处理的事件数
+	Reset     int64 `json:"reset,omitempty"`
+	Processed int   `json:"processed"`
+	Failed    int   `json:"failed"`
+}
+
+// SetReplayBackoff 设置失败事件的重试退避：第 n 次失败后等待 base*2^(n-1)，最长 max
+func (s *Service) SetReplayBackoff(base, max time.Duration) {
+	if base > 0 {
+		s.replayBackoff = base
+	}
+	if max > 0 {
+		s.replayMaxBackoff = max
+	}
+}
+
+// backoff 返回第 attempts 次失败后的重试间隔
+func (s *Service) backoff(attempts int) time.Duration {
+	base, max := s.replayBackoff, s.replayMaxBackoff
+	if base <= 0 {
+		base = defaultReplayBackoff
+	}
+	if max <= 0 {
+		max = defaultReplayMaxBackoff
+	}
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// EventNames 返回已订阅的事件名
+func (s *Service) EventNames() []string {
+	names := make([]string, 0, len(s.handlers))
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyEvent 物化单个事件：成功时标记已处理，失败时记录错误并安排下次重试，
+// 失败次数达到死信上限后转入死信队列；事件未到重试时间或已被其他调用方领取时跳过
+func (s *Service) ApplyEvent(eventLog *model.EventLog) error {
+	err := s.applyEvent(eventLog, false)
+	if errors.Is(err, errEventClaimed) {
+		log.Printf("Event %d (%s) is already being processed, skipped", eventLog.ID, eventLog.EventName)
+		return nil
+	}
+	return err
+}
+
+// applyEvent 以租约领取事件后物化，force 为 true 时忽略重试时间（手动重放、重建和死信重试）
+func (s *Service) applyEvent(eventLog *model.EventLog, force bool) error {
+	now := time.Now()
+	cutoff := now
+	if force {
+		cutoff = time.Time{}
+	}
+	claimed, err := s.repo.ClaimEvent(eventLog.ID, cutoff, now.Add(eventLease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errEventClaimed
+	}
+
+	if err := s.ProcessEvent(eventLog); err != nil {
+		eventLog.Attempts++
+		next := time.Now().Add(s.backoff(eventLog.Attempts))
+		if recordErr := s.repo.RecordEventFailure(eventLog.ID, err.Error(), next); recordErr != nil {
+			log.Printf("Failed to record failure of event %d: %v", eventLog.ID, recordErr)
+		}
+		if eventLog.DeadLettered || eventLog.Attempts >= s.maxAttempts() {
+			if _, dlErr := s.repo.DeadLetterEvent(eventLog, err.Error(), errorStack(err)); dlErr != nil {
+				log.Printf("Failed to dead-letter event %d: %v", eventLog.ID, dlErr)
+			} else {
+				eventLog.DeadLettered = true
+				log.Printf("☠️  Event %d (%s) dead-lettered after %d attempts", eventLog.ID, eventLog.EventName, eventLog.Attempts)
+			}
+		}
+		return err
+	}
+	if err := s.repo.MarkEventProcessed(eventLog.ID); err != nil {
+		return err
+	}
+	if eventLog.DeadLettered {
+		eventLog.DeadLettered = false
+		return s.repo.ResolveDeadLetters(eventLog.ID)
+	}
+	return nil
+}
+
+// ReplayEvents 重试已到重试时间的未处理事件，force 为 true 时忽略退避时间
+func (s *Service) ReplayEvents(limit int, force bool) (*ReplayResult, error) {
+	var now time.Time
+	if !force {
+		now = time.Now()
+	}
+	events, err := s.repo.GetRetryableEvents(s.EventNames(), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.applyEvents(events, force), nil
+}
+
+// RebuildRange 清空区块范围内的派生数据，按链上顺序从 event_logs 重新物化，最后重建受影响的投影
+func (s *Service) RebuildRange(fromBlock, toBlock uint64) (*ReplayResult, error) {
+	reset, err := s.repo.ResetBlockRange(fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.GetEventsByBlockRange(fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]model.EventLog, 0, len(events))
+	for _, event := range events {
+		if event.Status == model.EventStatusConfirmed && s.HandlesEvent(event.EventName) {
+			pending = append(pending, event)
+		}
+	}
+	result := s.applyEvents(pending, true)
+	result.Reset = reset
+
+	// 重放顺序只覆盖范围内的事件，可变投影需按全部事件重新计算
+	if err := s.repo.RefreshProjections(fromBlock); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// applyEvents 依次物化事件，单个失败不影响后续事件；已被其他调用方领取的事件不计入结果
+func (s *Service) applyEvents(events []model.EventLog, force bool) *ReplayResult {
+	result := &ReplayResult{}
+	for i := range events {
+		event := &events[i]
+		err := s.applyEvent(event, force)
+		if errors.Is(err, errEventClaimed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Replay: event %d (%s) failed, attempt %d: %v", event.ID, event.EventName, event.Attempts, err)
+			result.Failed++
+			continue
+		}
+		result.Processed++
+	}
+	return result
+}
+
+// RunReplayer 周期性重试失败的事件，直到ctx取消
+func (s *Service) RunReplayer(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			result, err := s.ReplayEvents(batchSize, false)
+			if err != nil {
+				log.Printf("Replayer: failed to load events: %v", err)
+				continue
+			}
+			if result.Processed > 0 || result.Failed > 0 {
+				log.Printf("🔁 Replayed %d events, %d failed", result.Processed, result.Failed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
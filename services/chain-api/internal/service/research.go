@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+)
+
+// researchActivityEvents ResearchNFT 的引用、评审、影响力、收益和元数据更新事件
+var researchActivityEvents = []string{
+	"CitationAdded", "ReviewSubmitted", "ImpactLevelUpdated", "ResearchRevenueDistributed",
+	"MetadataUpdate", "BatchMetadataUpdate",
+}
+
+// 处理 ResearchNFT 的引用、评审、影响力、收益和元数据更新事件
+func (s *Service) processResearchActivity(eventLog *model.EventLog) error {
+	var e model.ResearchEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+
+	switch eventLog.EventName {
+	case "CitationAdded":
+		if e.FromTokenID == "" || e.ToTokenID == "" {
+			return fmt.Errorf("CitationAdded event without token ids")
+		}
+		return s.repo.InsertResearchCitation(&model.ResearchCitation{
+			FromTokenID: e.FromTokenID,
+			ToTokenID:   e.ToTokenID,
+			Citer:       e.Citer,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "ReviewSubmitted":
+		review := &model.ResearchReview{
+			TokenID:     e.TokenID,
+			Reviewer:    e.Reviewer,
+			Score:       e.Score,
+			IsAnonymous: e.IsAnonymous,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		}
+		// 匿名评审不在投影中保存评审人
+		if review.IsAnonymous {
+			review.Reviewer = ""
+		}
+		return s.repo.InsertResearchReview(review)
+	case "ImpactLevelUpdated":
+		return s.repo.InsertResearchImpactChange(&model.ResearchImpactChange{
+			TokenID:     e.TokenID,
+			OldLevel:    e.OldLevel,
+			NewLevel:    e.NewLevel,
+			LevelName:   model.ImpactLevelName(e.NewLevel),
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "ResearchRevenueDistributed":
+		return s.repo.InsertResearchRevenue(&model.ResearchRevenue{
+			TokenID:     e.TokenID,
+			Authors:     e.Authors,
+			Shares:      e.Shares,
+			TotalAmount: e.TotalAmount,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	case "MetadataUpdate", "BatchMetadataUpdate":
+		update := &model.ResearchMetadataUpdate{
+			FromTokenID: e.MetadataFromToken,
+			ToTokenID:   e.MetadataToToken,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		}
+		if eventLog.EventName == "MetadataUpdate" {
+			update.FromTokenID = e.MetadataTokenID
+			update.ToTokenID = e.MetadataTokenID
+		}
+		return s.repo.InsertResearchMetadataUpdate(update)
+	}
+	return nil
+}
+
+// 引用图的方向
+const (
+	CitationsCitedBy    = "cited_by"   // 引用了该成果的记录
+	CitationsReferences = "references" // 该成果引用的其他成果
+)
+
+// GetResearchCitations 按方向分页获取成果的引用记录
+func (s *Service) GetResearchCitations(tokenID, direction string, page repository.PageRequest) (*repository.Page[model.ResearchCitation], error) {
+	if direction == CitationsReferences {
+		return s.repo.ListResearchReferences(tokenID, page)
+	}
+	return s.repo.ListResearchCitedBy(tokenID, page)
+}
+
+// GetResearchReviews 分页获取成果的同行评审记录
+func (s *Service) GetResearchReviews(tokenID string, page repository.PageRequest) (*repository.Page[model.ResearchReview], error) {
+	return s.repo.ListResearchReviews(tokenID, page)
+}
+
+// GetResearchImpactHistory 分页获取成果的影响力等级历史，并返回当前等级对应的最新变更（没有时为 nil）
+func (s *Service) GetResearchImpactHistory(tokenID string, page repository.PageRequest) (*repository.Page[model.ResearchImpactChange], *model.ResearchImpactChange, error) {
+	history, err := s.repo.ListResearchImpactChanges(tokenID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := latestOrNil(s.repo.GetLatestResearchImpactChange(tokenID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, current, nil
+}
+
+// GetResearchRevenue 分页获取成果的收益分配历史，并返回全部记录的累计金额
+func (s *Service) GetResearchRevenue(tokenID string, page repository.PageRequest) (*repository.Page[model.ResearchRevenue], string, error) {
+	history, err := s.repo.ListResearchRevenue(tokenID, page)
+	if err != nil {
+		return nil, "", err
+	}
+	total, err := s.repo.SumResearchRevenue(tokenID)
+	if err != nil {
+		return nil, "", err
+	}
+	return history, total, nil
+}
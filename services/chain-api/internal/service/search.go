@@ -0,0 +1,11 @@
+package service
+
+import (
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+)
+
+// Search 在研究成果和数据集中全文检索，按相关度分页
+func (s *Service) Search(filter repository.SearchFilter, page repository.PageRequest) (*repository.Page[model.SearchResult], error) {
+	return s.repo.Search(filter, page)
+}
@@ -1,65 +1,243 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
 
 	"desci-backend/internal/model"
 	"desci-backend/internal/repository"
 	"desci-backend/internal/verify"
+	"gorm.io/gorm"
 )
 
+// EventHandler 物化一类链上事件的处理函数
+type EventHandler func(eventLog *model.EventLog) error
+
 type Service struct {
-	repo repository.IRepository
+	repo          repository.IRepository
+	confirmations uint64
+	proofChain    ProofChain
+	handlers      map[string][]EventHandler
+
+	replayBackoff    time.Duration
+	replayMaxBackoff time.Duration
+
+	deadLetterAttempts int
 }
 
 func NewService(repo repository.IRepository) *Service {
-	return &Service{repo: repo}
+	s := &Service{repo: repo, handlers: make(map[string][]EventHandler)}
+	s.subscribeAll()
+	return s
+}
+
+// Subscribe 订阅事件；同一事件可有多个处理器，按订阅顺序执行
+func (s *Service) Subscribe(handler EventHandler, eventNames ...string) {
+	for _, name := range eventNames {
+		s.handlers[name] = append(s.handlers[name], handler)
+	}
+}
+
+// subscribeAll 订阅各业务模块需要物化的事件
+func (s *Service) subscribeAll() {
+	s.Subscribe(s.processResearchCreated, "ResearchCreated")
+	s.Subscribe(s.processDatasetCreated, "DatasetCreated")
+	s.Subscribe(s.processUserEvent, model.UserEvents...)
+	s.Subscribe(s.processDatasetActivity, datasetActivityEvents...)
+	s.Subscribe(s.processResearchActivity, researchActivityEvents...)
+	s.Subscribe(s.processNFTTransfer, "ResearchTransfer", "DatasetTransfer")
+	s.Subscribe(s.processProofEvent, proofEvents...)
+	s.Subscribe(s.processConstraintEvent, constraintEvents...)
+	s.Subscribe(s.processDataQualityEvent, dataQualityEvents...)
+	s.Subscribe(s.processInfluenceEvent, influenceEvents...)
+	s.Subscribe(s.processTokenEvent, tokenEvents...)
+}
+
+// SetConfirmations 设置事件物化前需要等待的区块确认数（0 表示立即物化）
+func (s *Service) SetConfirmations(n uint64) {
+	s.confirmations = n
 }
 
-// ProcessEvent 处理区块链事件
+// Confirmations 返回当前的确认深度
+func (s *Service) Confirmations() uint64 {
+	return s.confirmations
+}
+
+// HandlesEvent 判断是否有服务订阅了该事件
+func (s *Service) HandlesEvent(eventName string) bool {
+	return len(s.handlers[eventName]) > 0
+}
+
+// ProcessEvent 按订阅顺序交由各处理器物化事件，遇到错误即停止
 func (s *Service) ProcessEvent(eventLog *model.EventLog) error {
-	// 解析事件数据
-	switch eventLog.EventName {
-	case "ResearchCreated":
-		return s.processResearchCreated(eventLog)
-	case "DatasetCreated":
-		return s.processDatasetCreated(eventLog)
-	default:
+	handlers := s.handlers[eventLog.EventName]
+	if len(handlers) == 0 {
 		log.Printf("Unknown event type: %s", eventLog.EventName)
+		return nil
+	}
+	for _, handler := range handlers {
+		if err := runHandler(handler, eventLog); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// HandlerError 事件处理器返回的错误或panic，附带处理器名称和panic时的调用栈
+type HandlerError struct {
+	Handler string
+	Err     error
+	Stack   string
+}
+
+func (e *HandlerError) Error() string { return e.Err.Error() }
 
+func (e *HandlerError) Unwrap() error { return e.Err }
+
+// runHandler 执行单个处理器，将错误和panic包装为 HandlerError
+func runHandler(handler EventHandler, eventLog *model.EventLog) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &HandlerError{Err: fmt.Errorf("panic: %v", p), Stack: string(debug.Stack())}
+		}
+		if herr, ok := err.(*HandlerError); ok {
+			herr.Handler = runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+		}
+	}()
+	if err := handler(eventLog); err != nil {
+		return &HandlerError{Err: err}
+	}
 	return nil
 }
 
+// PromoteConfirmedEvents 将达到确认深度的待确认事件物化到业务表，返回处理数量
+func (s *Service) PromoteConfirmedEvents(head uint64) (int, error) {
+	if head < s.confirmations {
+		return 0, nil
+	}
+
+	events, err := s.repo.GetPendingEvents(head - s.confirmations)
+	if err != nil {
+		return 0, err
+	}
+
+	promoted := 0
+	for i := range events {
+		event := &events[i]
+		if err := s.repo.MarkEventConfirmed(event.ID); err != nil {
+			return promoted, err
+		}
+		promoted++
+		if !s.HandlesEvent(event.EventName) {
+			continue
+		}
+		// 物化失败的事件已记录错误，由重放任务按退避策略重试，不阻塞后续事件
+		if err := s.ApplyEvent(event); err != nil {
+			log.Printf("Confirmer: event %d (%s) failed: %v", event.ID, event.EventName, err)
+		}
+	}
+	return promoted, nil
+}
+
+// RunConfirmer 周期性读取链头并提升已确认事件，直到ctx取消
+func (s *Service) RunConfirmer(ctx context.Context, latestBlock func(context.Context) (uint64, error), interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			head, err := latestBlock(ctx)
+			if err != nil {
+				log.Printf("Confirmer: failed to get latest block: %v", err)
+				continue
+			}
+			n, err := s.PromoteConfirmedEvents(head)
+			if err != nil {
+				log.Printf("Confirmer: failed to promote events: %v", err)
+			}
+			if n > 0 {
+				log.Printf("✅ Confirmed %d events at head %d", n, head)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // 处理研究创建事件
 func (s *Service) processResearchCreated(eventLog *model.EventLog) error {
+	researchData, err := researchFromEvent(eventLog)
+	if err != nil {
+		log.Printf("Failed to parse research created event: %v", err)
+		return err
+	}
+	// 铸造时的 Transfer 先于 ResearchMinted 到达
+	if researchData.Owner, err = s.currentOwner(model.CollectionResearch, researchData.TokenID); err != nil {
+		return err
+	}
+	return s.repo.InsertResearchData(researchData)
+}
+
+// researchFromEvent 从 ResearchCreated 事件载荷构造研究数据
+func researchFromEvent(eventLog *model.EventLog) (*model.ResearchData, error) {
 	var eventData struct {
 		TokenID      string   `json:"tokenId"`
 		Authors      []string `json:"authors"`
 		Title        string   `json:"title"`
 		ContentHash  string   `json:"contentHash"`
 		MetadataHash string   `json:"metadataHash"`
+		PubType      uint8    `json:"pubType"`
+		Timestamp    string   `json:"timestamp"`
+		BlockTime    uint64   `json:"blockTimestamp"`
 	}
 
 	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &eventData); err != nil {
-		log.Printf("Failed to parse research created event: %v", err)
-		return err
+		return nil, err
 	}
 
-	researchData := &model.ResearchData{
+	return &model.ResearchData{
 		TokenID:      eventData.TokenID,
 		Title:        eventData.Title,
 		Authors:      eventData.Authors,
 		ContentHash:  eventData.ContentHash,
 		MetadataHash: eventData.MetadataHash,
-	}
-
-	return s.repo.InsertResearchData(researchData)
+		PubType:      eventData.PubType,
+		PubTypeName:  model.PublicationTypeName(eventData.PubType),
+		PublishedAt:  model.EventTime(eventData.Timestamp),
+		BlockTime:    model.UnixTime(eventData.BlockTime),
+		BlockNumber:  eventLog.BlockNumber,
+		Status:       model.EventStatusConfirmed,
+	}, nil
 }
 
 // 处理数据集创建事件
 func (s *Service) processDatasetCreated(eventLog *model.EventLog) error {
+	datasetRecord, err := datasetFromEvent(eventLog)
+	if err != nil {
+		log.Printf("Failed to parse dataset created event: %v", err)
+		return err
+	}
+	owner, err := s.currentOwner(model.CollectionDataset, datasetRecord.DatasetID)
+	if err != nil {
+		return err
+	}
+	if owner != "" {
+		datasetRecord.Owner = owner
+	}
+	return s.repo.InsertDatasetRecord(datasetRecord)
+}
+
+// datasetFromEvent 从 DatasetCreated 事件载荷构造数据集记录
+func datasetFromEvent(eventLog *model.EventLog) (*model.DatasetRecord, error) {
 	var eventData struct {
 		DatasetID   string `json:"datasetId"`
 		Title       string `json:"title"`
@@ -69,19 +247,18 @@ func (s *Service) processDatasetCreated(eventLog *model.EventLog) error {
 	}
 
 	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &eventData); err != nil {
-		log.Printf("Failed to parse dataset created event: %v", err)
-		return err
+		return nil, err
 	}
 
-	datasetRecord := &model.DatasetRecord{
+	return &model.DatasetRecord{
 		DatasetID:   eventData.DatasetID,
 		Title:       eventData.Title,
 		Description: eventData.Description,
-		Owner:       eventData.Owner,
+		Owner:       strings.ToLower(eventData.Owner),
 		DataHash:    eventData.IPFSHash,
-	}
-
-	return s.repo.InsertDatasetRecord(datasetRecord)
+		BlockNumber: eventLog.BlockNumber,
+		Status:      model.EventStatusConfirmed,
+	}, nil
 }
 
 // VerifyResearchContent 验证研究内容哈希
@@ -94,31 +271,62 @@ func (s *Service) VerifyResearchContent(tokenID string, rawContent string) (bool
 	return verify.VerifyHashMatch(rawContent, research.ContentHash), nil
 }
 
-// GetResearchByTokenID 根据TokenID获取研究数据
+// GetResearchByTokenID 根据TokenID获取研究数据，未确认的铸造以 pending 状态返回
 func (s *Service) GetResearchByTokenID(tokenID string) (*model.ResearchData, error) {
-	return s.repo.GetResearchData(tokenID)
+	research, err := s.repo.GetResearchData(tokenID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return research, err
+	}
+
+	pending, pendingErr := s.repo.GetPendingEventByEntity("ResearchCreated", tokenID)
+	if pendingErr != nil {
+		return research, err
+	}
+	research, pendingErr = researchFromEvent(pending)
+	if pendingErr != nil {
+		return nil, err
+	}
+	research.Status = model.EventStatusPending
+	return research, nil
 }
 
-// GetDatasetByID 根据ID获取数据集
+// GetDatasetByID 根据ID获取数据集，未确认的上传以 pending 状态返回
 func (s *Service) GetDatasetByID(datasetID string) (*model.DatasetRecord, error) {
-	return s.repo.GetDatasetRecord(datasetID)
-}
+	dataset, err := s.repo.GetDatasetRecord(datasetID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dataset, err
+	}
 
-// GetLatestResearch 获取最新研究列表
-func (s *Service) GetLatestResearch(limit, offset int) ([]*model.ResearchData, error) {
-	if limit <= 0 {
-		limit = 20 // 默认限制
+	pending, pendingErr := s.repo.GetPendingEventByEntity("DatasetCreated", datasetID)
+	if pendingErr != nil {
+		return dataset, err
+	}
+	dataset, pendingErr = datasetFromEvent(pending)
+	if pendingErr != nil {
+		return nil, err
 	}
-	// 使用repository的方法，按创建时间倒序
-	return s.repo.GetLatestResearchData(limit, offset)
+	dataset.Status = model.EventStatusPending
+	return dataset, nil
 }
 
-// GetResearchByAuthor 按作者获取研究列表
-func (s *Service) GetResearchByAuthor(author string, limit int) ([]*model.ResearchData, error) {
-	if limit <= 0 {
-		limit = 20 // 默认限制
-	}
-	return s.repo.ListResearchDataByAuthor(author, limit)
+// GetPendingEvents 分页列出等待确认的事件
+func (s *Service) GetPendingEvents(page repository.PageRequest) (*repository.Page[model.EventLog], error) {
+	return s.repo.ListPendingEvents(page)
+}
+
+// GetLatestResearch 分页获取最新研究列表，默认按创建时间倒序
+func (s *Service) GetLatestResearch(page repository.PageRequest) (*repository.Page[model.ResearchData], error) {
+	return s.repo.GetLatestResearchData(page)
+}
+
+// GetResearchByAuthor 按作者地址分页获取研究列表（含署名顺序）
+func (s *Service) GetResearchByAuthor(author string, page repository.PageRequest) (*repository.Page[model.AuthoredResearch], error) {
+	return s.repo.ListResearchDataByAuthor(author, page)
+}
+
+// GetDatasetsByOwner 分页获取地址拥有的数据集
+func (s *Service) GetDatasetsByOwner(owner string, page repository.PageRequest) (*repository.Page[model.DatasetRecord], error) {
+	return s.repo.ListDatasetsByOwner(owner, page)
 }
 
 // GetLastEventBlock 获取最后的事件区块号
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+)
+
+// TokenAccount 地址的 SciToken 余额及其对外授权
+type TokenAccount struct {
+	Address    string                `json:"address"`
+	Balance    string                `json:"balance"`
+	Allowances []model.TokenApproval `json:"allowances"`
+}
+
+// tokenEvents SciToken 事件
+var tokenEvents = []string{"TokenTransfer", "TokenApproval"}
+
+// 处理 SciToken 事件：转账进入账本并重算余额，授权按时间序列保存
+func (s *Service) processTokenEvent(eventLog *model.EventLog) error {
+	var e model.TokenEventPayload
+	if err := json.Unmarshal([]byte(eventLog.PayloadRaw), &e); err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+
+	if eventLog.EventName == "TokenApproval" {
+		return s.repo.InsertTokenApproval(&model.TokenApproval{
+			Owner:       e.Owner,
+			Spender:     e.Spender,
+			Value:       e.Value,
+			BlockNumber: eventLog.BlockNumber,
+			TxHash:      eventLog.TxHash,
+			LogIndex:    eventLog.LogIndex,
+		})
+	}
+	return s.repo.ApplyTokenTransfer(&model.TokenTransfer{
+		FromAddress:   e.From,
+		ToAddress:     e.To,
+		Value:         e.Value,
+		TransferredAt: model.UnixTime(e.BlockTime),
+		BlockNumber:   eventLog.BlockNumber,
+		TxHash:        eventLog.TxHash,
+		LogIndex:      eventLog.LogIndex,
+	})
+}
+
+// GetTokenHolders 分页获取持有人排行及总供应量
+func (s *Service) GetTokenHolders(page repository.PageRequest) (*repository.Page[model.TokenBalance], *model.TokenSupply, error) {
+	holders, err := s.repo.ListTokenHolders(page)
+	if err != nil {
+		return nil, nil, err
+	}
+	supply, err := s.repo.GetTokenSupply()
+	if err != nil {
+		return nil, nil, err
+	}
+	return holders, supply, nil
+}
+
+// GetTokenAccount 获取地址的余额和授权，未出现在账本中的地址余额为0
+func (s *Service) GetTokenAccount(address string) (*TokenAccount, error) {
+	account := &TokenAccount{Address: address, Balance: "0"}
+	balance, err := s.repo.GetTokenBalance(address)
+	if err == nil {
+		account.Balance = balance.Balance
+	}
+	allowances, err := s.repo.ListTokenAllowances(address)
+	if err != nil {
+		return nil, err
+	}
+	account.Allowances = allowances
+	return account, nil
+}
+
+// GetTokenTransfers 分页获取转账记录，address 非空时只返回该地址相关的转账
+func (s *Service) GetTokenTransfers(address string, page repository.PageRequest) (*repository.Page[model.TokenTransfer], error) {
+	return s.repo.ListTokenTransfers(address, page)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"desci-backend/internal/model"
+	"desci-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// 处理 DeSciRegistry 用户事件：更新用户档案，声誉变更同时记录历史
+func (s *Service) processUserEvent(eventLog *model.EventLog) error {
+	payload, err := model.ParseUserEventPayload(eventLog.EventName, eventLog.PayloadRaw)
+	if err != nil {
+		log.Printf("Failed to parse %s event: %v", eventLog.EventName, err)
+		return err
+	}
+	address := payload.Subject()
+	if address == "" {
+		return fmt.Errorf("%s event without user address", eventLog.EventName)
+	}
+
+	return s.repo.WithTx(context.Background(), func(tx repository.IRepository) error {
+		profile, err := tx.GetUserProfile(address)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			profile = &model.UserProfile{WalletAddress: address}
+		}
+		profile.Apply(eventLog.EventName, payload, eventLog.BlockNumber)
+		if err := tx.SaveUserProfile(profile); err != nil {
+			return err
+		}
+
+		if eventLog.EventName != "ReputationUpdated" {
+			return nil
+		}
+		return tx.InsertReputationChange(&model.ReputationChange{
+			WalletAddress: address,
+			OldReputation: payload.OldReputation,
+			NewReputation: payload.NewReputation,
+			Reason:        payload.Reason,
+			BlockNumber:   eventLog.BlockNumber,
+			TxHash:        eventLog.TxHash,
+			LogIndex:      eventLog.LogIndex,
+		})
+	})
+}
+
+// GetUserProfile 根据钱包地址获取用户档案
+func (s *Service) GetUserProfile(address string) (*model.UserProfile, error) {
+	return s.repo.GetUserProfile(address)
+}
+
+// GetReputationHistory 获取用户的声誉变更历史
+func (s *Service) GetReputationHistory(address string, limit int) ([]model.ReputationChange, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return s.repo.ListReputationChanges(address, limit)
+}
@@ -0,0 +1,174 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+// G1Point BN254 G1 点 (x, y)
+type G1Point [2]*big.Int
+
+// G2Point BN254 G2 点 [[x.re, x.im], [y.re, y.im]]，与 ZKPVerifier 合约的存储顺序一致
+type G2Point [2][2]*big.Int
+
+// Groth16Proof Groth16 证明 (A, B, C)
+type Groth16Proof struct {
+	A G1Point
+	B G2Point
+	C G1Point
+}
+
+// VerifyingKey Groth16 验证密钥，IC[0] 为常数项，IC[i+1] 对应第 i 个公开输入
+type VerifyingKey struct {
+	Alpha1 G1Point
+	Beta2  G2Point
+	Gamma2 G2Point
+	Delta2 G2Point
+	IC     []G1Point
+}
+
+// Groth16 验证失败的原因
+var (
+	ErrKeyNotRegistered = errors.New("verification key not registered")
+	ErrInvalidPoint     = errors.New("proof point not on curve")
+	ErrInputCount       = errors.New("public input count does not match verification key")
+	ErrInputOutOfField  = errors.New("public input exceeds scalar field")
+	ErrPairingFailed    = errors.New("pairing check failed")
+)
+
+// ProofFromArray 将 ZKProof 合约的 uint256[8] 还原为证明：
+// [a.x, a.y, b.x.re, b.x.im, b.y.re, b.y.im, c.x, c.y]
+func ProofFromArray(p [8]*big.Int) Groth16Proof {
+	return Groth16Proof{
+		A: G1Point{p[0], p[1]},
+		B: G2Point{{p[2], p[3]}, {p[4], p[5]}},
+		C: G1Point{p[6], p[7]},
+	}
+}
+
+// VerifyGroth16 校验 e(A, B) = e(alpha, beta) · e(vk_x, gamma) · e(C, delta)，
+// 失败时返回具体原因
+func VerifyGroth16(vk *VerifyingKey, proof Groth16Proof, inputs []*big.Int) error {
+	if vk == nil || len(vk.IC) == 0 || isZero(vk.Alpha1[0]) {
+		return ErrKeyNotRegistered
+	}
+	if len(inputs) != len(vk.IC)-1 {
+		return fmt.Errorf("%w: got %d, want %d", ErrInputCount, len(inputs), len(vk.IC)-1)
+	}
+	for i, in := range inputs {
+		if in == nil || in.Sign() < 0 || in.Cmp(bn256.Order) >= 0 {
+			return fmt.Errorf("%w: input %d", ErrInputOutOfField, i)
+		}
+	}
+
+	a, err := g1(proof.A)
+	if err != nil {
+		return fmt.Errorf("%w: A: %v", ErrInvalidPoint, err)
+	}
+	b, err := g2(proof.B)
+	if err != nil {
+		return fmt.Errorf("%w: B: %v", ErrInvalidPoint, err)
+	}
+	c, err := g1(proof.C)
+	if err != nil {
+		return fmt.Errorf("%w: C: %v", ErrInvalidPoint, err)
+	}
+
+	alpha, err := g1(vk.Alpha1)
+	if err != nil {
+		return fmt.Errorf("invalid verification key alpha: %v", err)
+	}
+	beta, err := g2(vk.Beta2)
+	if err != nil {
+		return fmt.Errorf("invalid verification key beta: %v", err)
+	}
+	gamma, err := g2(vk.Gamma2)
+	if err != nil {
+		return fmt.Errorf("invalid verification key gamma: %v", err)
+	}
+	delta, err := g2(vk.Delta2)
+	if err != nil {
+		return fmt.Errorf("invalid verification key delta: %v", err)
+	}
+
+	// vk_x = IC[0] + Σ inputs[i]·IC[i+1]
+	vkx, err := g1(vk.IC[0])
+	if err != nil {
+		return fmt.Errorf("invalid verification key IC[0]: %v", err)
+	}
+	for i, in := range inputs {
+		ic, err := g1(vk.IC[i+1])
+		if err != nil {
+			return fmt.Errorf("invalid verification key IC[%d]: %v", i+1, err)
+		}
+		vkx = new(bn256.G1).Add(vkx, new(bn256.G1).ScalarMult(ic, in))
+	}
+
+	// e(-A, B) · e(alpha, beta) · e(vk_x, gamma) · e(C, delta) = 1
+	ok := bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(a), alpha, vkx, c},
+		[]*bn256.G2{b, beta, gamma, delta},
+	)
+	if !ok {
+		return ErrPairingFailed
+	}
+	return nil
+}
+
+// Groth16ProofHash 按 ZKPVerifier.verifyGroth16Proof 的方式计算证明哈希：
+// keccak256(abi.encodePacked(proofType, a, b, c, publicInputs))
+func Groth16ProofHash(proofType string, proof Groth16Proof, inputs []*big.Int) common.Hash {
+	words := []*big.Int{
+		proof.A[0], proof.A[1],
+		proof.B[0][0], proof.B[0][1], proof.B[1][0], proof.B[1][1],
+		proof.C[0], proof.C[1],
+	}
+	words = append(words, inputs...)
+
+	packed := []byte(proofType)
+	for _, w := range words {
+		packed = append(packed, math.U256Bytes(new(big.Int).Set(orZero(w)))...)
+	}
+	return crypto.Keccak256Hash(packed)
+}
+
+func g1(p G1Point) (*bn256.G1, error) {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, math.PaddedBigBytes(orZero(p[0]), 32)...)
+	buf = append(buf, math.PaddedBigBytes(orZero(p[1]), 32)...)
+	point := new(bn256.G1)
+	if _, err := point.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+// g2 预编译合约的编码顺序为虚部在前
+func g2(p G2Point) (*bn256.G2, error) {
+	buf := make([]byte, 0, 128)
+	for _, v := range []*big.Int{p[0][1], p[0][0], p[1][1], p[1][0]} {
+		buf = append(buf, math.PaddedBigBytes(orZero(v), 32)...)
+	}
+	point := new(bn256.G2)
+	if _, err := point.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
+
+func isZero(v *big.Int) bool {
+	return v == nil || v.Sign() == 0
+}
@@ -0,0 +1,107 @@
+package verify
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toG1(p *bn256.G1) G1Point {
+	b := p.Marshal()
+	return G1Point{new(big.Int).SetBytes(b[:32]), new(big.Int).SetBytes(b[32:])}
+}
+
+func toG2(p *bn256.G2) G2Point {
+	b := p.Marshal()
+	word := func(i int) *big.Int { return new(big.Int).SetBytes(b[i*32 : (i+1)*32]) }
+	return G2Point{{word(1), word(0)}, {word(3), word(2)}}
+}
+
+// testCircuit 用已知离散对数构造满足配对等式的验证密钥和证明
+func testCircuit(inputs []*big.Int) (*VerifyingKey, Groth16Proof) {
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, bn256.Order) }
+	alpha, beta, gamma, delta := big.NewInt(11), big.NewInt(13), big.NewInt(17), big.NewInt(19)
+	ic := []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)}
+
+	vk := &VerifyingKey{
+		Alpha1: toG1(new(bn256.G1).ScalarBaseMult(alpha)),
+		Beta2:  toG2(new(bn256.G2).ScalarBaseMult(beta)),
+		Gamma2: toG2(new(bn256.G2).ScalarBaseMult(gamma)),
+		Delta2: toG2(new(bn256.G2).ScalarBaseMult(delta)),
+	}
+	for _, k := range ic {
+		vk.IC = append(vk.IC, toG1(new(bn256.G1).ScalarBaseMult(k)))
+	}
+
+	// vk_x 的离散对数 v = ic0 + Σ x_i·ic_{i+1}
+	v := new(big.Int).Set(ic[0])
+	for i, x := range inputs {
+		v.Add(v, new(big.Int).Mul(x, ic[i+1]))
+	}
+	// 取 B = g2, C = 23·g1，则 A = alpha·beta + v·gamma + 23·delta
+	c := big.NewInt(23)
+	a := new(big.Int).Mul(alpha, beta)
+	a.Add(a, new(big.Int).Mul(v, gamma))
+	a.Add(a, new(big.Int).Mul(c, delta))
+
+	proof := Groth16Proof{
+		A: toG1(new(bn256.G1).ScalarBaseMult(mod(a))),
+		B: toG2(new(bn256.G2).ScalarBaseMult(big.NewInt(1))),
+		C: toG1(new(bn256.G1).ScalarBaseMult(c)),
+	}
+	return vk, proof
+}
+
+func TestVerifyGroth16(t *testing.T) {
+	inputs := []*big.Int{big.NewInt(42), big.NewInt(1000)}
+	vk, proof := testCircuit(inputs)
+
+	require.NoError(t, VerifyGroth16(vk, proof, inputs))
+
+	// 公开输入被篡改
+	err := VerifyGroth16(vk, proof, []*big.Int{big.NewInt(43), big.NewInt(1000)})
+	assert.True(t, errors.Is(err, ErrPairingFailed))
+
+	// 输入数量不匹配
+	err = VerifyGroth16(vk, proof, inputs[:1])
+	assert.True(t, errors.Is(err, ErrInputCount))
+
+	// 输入超出标量域
+	err = VerifyGroth16(vk, proof, []*big.Int{bn256.Order, big.NewInt(1)})
+	assert.True(t, errors.Is(err, ErrInputOutOfField))
+
+	// 不在曲线上的点
+	bad := proof
+	bad.A = G1Point{big.NewInt(1), big.NewInt(3)}
+	err = VerifyGroth16(vk, bad, inputs)
+	assert.True(t, errors.Is(err, ErrInvalidPoint))
+
+	// 未注册的验证密钥
+	assert.True(t, errors.Is(VerifyGroth16(&VerifyingKey{}, proof, inputs), ErrKeyNotRegistered))
+}
+
+func TestProofFromArray(t *testing.T) {
+	var arr [8]*big.Int
+	for i := range arr {
+		arr[i] = big.NewInt(int64(i + 1))
+	}
+	proof := ProofFromArray(arr)
+	assert.Equal(t, int64(1), proof.A[0].Int64())
+	assert.Equal(t, int64(4), proof.B[0][1].Int64())
+	assert.Equal(t, int64(5), proof.B[1][0].Int64())
+	assert.Equal(t, int64(8), proof.C[1].Int64())
+}
+
+func TestGroth16ProofHash(t *testing.T) {
+	inputs := []*big.Int{big.NewInt(42), big.NewInt(1000)}
+	_, proof := testCircuit(inputs)
+
+	h1 := Groth16ProofHash("data_integrity", proof, inputs)
+	assert.Equal(t, h1, Groth16ProofHash("data_integrity", proof, inputs))
+	assert.NotEqual(t, h1, Groth16ProofHash("statistical_analysis", proof, inputs))
+	assert.NotEqual(t, h1, Groth16ProofHash("data_integrity", proof, []*big.Int{big.NewInt(42), big.NewInt(1001)}))
+}
@@ -0,0 +1,178 @@
+// Package zkp 读取 ZKProof/ZKPVerifier 合约中的证明数据、验证密钥和链上验证结果
+package zkp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"desci-backend/internal/verify"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SubmittedProof ZKProof.getProof 返回的证明内容
+type SubmittedProof struct {
+	Submitter    string // 校验和格式；证明不存在时为零地址
+	ProofType    string
+	Proof        verify.Groth16Proof
+	PublicInputs []*big.Int
+}
+
+// Reader 通过只读调用访问证明合约
+type Reader struct {
+	caller       ethereum.ContractCaller
+	proofAddr    common.Address
+	verifierAddr common.Address
+	proofABI     *abi.ABI
+	verifierABI  *abi.ABI
+}
+
+// NewReader 从合约配置文件加载 ZKProof 和 ZKPVerifier 的ABI
+func NewReader(caller ethereum.ContractCaller, configPath, proofAddr, verifierAddr string) (*Reader, error) {
+	abis, err := loadABIs(configPath, "ZKProof", "ZKPVerifier")
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		caller:       caller,
+		proofAddr:    common.HexToAddress(proofAddr),
+		verifierAddr: common.HexToAddress(verifierAddr),
+		proofABI:     abis["ZKProof"],
+		verifierABI:  abis["ZKPVerifier"],
+	}, nil
+}
+
+// GetSubmittedProof 读取提交到 ZKProof 的证明点和公开输入
+func (r *Reader) GetSubmittedProof(ctx context.Context, proofID string) (*SubmittedProof, error) {
+	id, ok := new(big.Int).SetString(proofID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid proof id %q", proofID)
+	}
+	out, err := r.call(ctx, r.proofAddr, r.proofABI, "getProof", id)
+	if err != nil {
+		return nil, err
+	}
+
+	var p struct {
+		Submitter    common.Address
+		ProofType    string
+		Proof        [8]*big.Int
+		PublicInputs [2]*big.Int
+	}
+	if err := copyTuple(out, &p); err != nil {
+		return nil, err
+	}
+	return &SubmittedProof{
+		Submitter:    p.Submitter.Hex(),
+		ProofType:    p.ProofType,
+		Proof:        verify.ProofFromArray(p.Proof),
+		PublicInputs: p.PublicInputs[:],
+	}, nil
+}
+
+// GetVerifyingKey 读取 ZKPVerifier 为证明类型注册的验证密钥
+func (r *Reader) GetVerifyingKey(ctx context.Context, proofType string) (*verify.VerifyingKey, error) {
+	out, err := r.call(ctx, r.verifierAddr, r.verifierABI, "getVerificationParams", proofType)
+	if err != nil {
+		return nil, err
+	}
+
+	var params struct {
+		Alpha1 [2]*big.Int
+		Beta2  [2][2]*big.Int
+		Gamma2 [2][2]*big.Int
+		Delta2 [2][2]*big.Int
+		Ic     [][2]*big.Int
+	}
+	if err := copyTuple(out, &params); err != nil {
+		return nil, err
+	}
+
+	vk := &verify.VerifyingKey{
+		Alpha1: params.Alpha1,
+		Beta2:  params.Beta2,
+		Gamma2: params.Gamma2,
+		Delta2: params.Delta2,
+	}
+	for _, ic := range params.Ic {
+		vk.IC = append(vk.IC, ic)
+	}
+	return vk, nil
+}
+
+// GetVerificationResult 读取 ZKPVerifier.verificationResults；timestamp 为 0 表示链上未验证过
+func (r *Reader) GetVerificationResult(ctx context.Context, proofHash common.Hash) (bool, uint64, error) {
+	out, err := r.call(ctx, r.verifierAddr, r.verifierABI, "getVerificationResult", proofHash)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(out) != 2 {
+		return false, 0, fmt.Errorf("getVerificationResult: unexpected %d outputs", len(out))
+	}
+	valid, _ := out[0].(bool)
+	ts, _ := out[1].(*big.Int)
+	if ts == nil {
+		return valid, 0, nil
+	}
+	return valid, ts.Uint64(), nil
+}
+
+func (r *Reader) call(ctx context.Context, contract common.Address, ab *abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := ab.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return ab.Unpack(method, out)
+}
+
+// copyTuple 将单个 tuple 返回值转换为同名字段的结构体
+func copyTuple(out []interface{}, dst interface{}) error {
+	if len(out) != 1 {
+		return fmt.Errorf("unexpected %d outputs", len(out))
+	}
+	b, err := json.Marshal(out[0])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func loadABIs(configPath string, names ...string) (map[string]*abi.ABI, error) {
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Contracts map[string]struct {
+			ABI json.RawMessage `json:"abi"`
+		} `json:"contracts"`
+	}
+	// 部署脚本可能重复追加输出，只解码第一个JSON文档
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	result := map[string]*abi.ABI{}
+	for _, name := range names {
+		c, ok := payload.Contracts[name]
+		if !ok || len(c.ABI) == 0 {
+			return nil, fmt.Errorf("contract %s not found in %s", name, configPath)
+		}
+		ab, err := abi.JSON(strings.NewReader(string(c.ABI)))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s abi: %w", name, err)
+		}
+		result[name] = &ab
+	}
+	return result, nil
+}
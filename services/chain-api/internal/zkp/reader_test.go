@@ -0,0 +1,103 @@
+package zkp
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"desci-backend/internal/verify"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	proofAddr    = "0x9fE46736679d2D9a65F0992F2272dE9f3c7fa6e0"
+	verifierAddr = "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"
+)
+
+// fakeCaller 按方法选择器返回预先打包的输出
+type fakeCaller struct {
+	outputs map[string][]byte
+}
+
+func (f *fakeCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	return f.outputs[string(msg.Data[:4])], nil
+}
+
+func (f *fakeCaller) set(t *testing.T, ab *abi.ABI, method string, values ...interface{}) {
+	out, err := ab.Methods[method].Outputs.Pack(values...)
+	require.NoError(t, err)
+	f.outputs[string(ab.Methods[method].ID)] = out
+}
+
+func words(p *bn256.G1) [2]*big.Int {
+	b := p.Marshal()
+	return [2]*big.Int{new(big.Int).SetBytes(b[:32]), new(big.Int).SetBytes(b[32:])}
+}
+
+// g2Words 合约存储顺序 [[x.re, x.im], [y.re, y.im]]，Marshal 输出虚部在前
+func g2Words(p *bn256.G2) [2][2]*big.Int {
+	b := p.Marshal()
+	w := func(i int) *big.Int { return new(big.Int).SetBytes(b[i*32 : (i+1)*32]) }
+	return [2][2]*big.Int{{w(1), w(0)}, {w(3), w(2)}}
+}
+
+func TestReader_DecodesContractOutputs(t *testing.T) {
+	caller := &fakeCaller{outputs: map[string][]byte{}}
+	reader, err := NewReader(caller, "../contracts/contracts.json", proofAddr, verifierAddr)
+	require.NoError(t, err)
+
+	g1 := func(k int64) *bn256.G1 { return new(bn256.G1).ScalarBaseMult(big.NewInt(k)) }
+	g2 := func(k int64) *bn256.G2 { return new(bn256.G2).ScalarBaseMult(big.NewInt(k)) }
+
+	// alpha=2, beta=3, gamma=5, delta=7, IC=[1,1,1]，输入 (4, 6) → vk_x = 11
+	// A·B = 2·3 + 11·5 + C·7，取 B=1、C=1 → A = 68
+	a, c, b := words(g1(68)), words(g1(1)), g2Words(g2(1))
+	proof := [8]*big.Int{a[0], a[1], b[0][0], b[0][1], b[1][0], b[1][1], c[0], c[1]}
+	inputs := [2]*big.Int{big.NewInt(4), big.NewInt(6)}
+
+	caller.set(t, reader.proofABI, "getProof", struct {
+		Submitter    common.Address
+		ProofType    string
+		Proof        [8]*big.Int
+		PublicInputs [2]*big.Int
+		IsVerified   bool
+		SubmittedAt  *big.Int
+		VerifiedAt   *big.Int
+		MetadataHash string
+	}{common.HexToAddress("0x1"), "data_integrity", proof, inputs, false, big.NewInt(1), big.NewInt(0), "QmMeta"})
+	caller.set(t, reader.verifierABI, "getVerificationParams", struct {
+		Alpha1 [2]*big.Int
+		Beta2  [2][2]*big.Int
+		Gamma2 [2][2]*big.Int
+		Delta2 [2][2]*big.Int
+		Ic     [][2]*big.Int
+	}{words(g1(2)), g2Words(g2(3)), g2Words(g2(5)), g2Words(g2(7)), [][2]*big.Int{words(g1(1)), words(g1(1)), words(g1(1))}})
+	caller.set(t, reader.verifierABI, "getVerificationResult", true, big.NewInt(1700000000))
+
+	ctx := context.Background()
+	submitted, err := reader.GetSubmittedProof(ctx, "0")
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0x1").Hex(), submitted.Submitter)
+	assert.Equal(t, "data_integrity", submitted.ProofType)
+	require.Len(t, submitted.PublicInputs, 2)
+
+	vk, err := reader.GetVerifyingKey(ctx, "data_integrity")
+	require.NoError(t, err)
+	assert.Len(t, vk.IC, 3)
+
+	// 解码后的数据可以直接通过校验，说明 G2 坐标顺序一致
+	require.NoError(t, verify.VerifyGroth16(vk, submitted.Proof, submitted.PublicInputs))
+
+	valid, ts, err := reader.GetVerificationResult(ctx, common.Hash{})
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, uint64(1700000000), ts)
+
+	_, err = reader.GetSubmittedProof(ctx, "not-a-number")
+	assert.Error(t, err)
+}
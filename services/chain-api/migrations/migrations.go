@@ -0,0 +1,10 @@
+// Package migrations 内嵌按数据库方言划分的版本化 SQL 迁移
+package migrations
+
+import "embed"
+
+// FS 以方言（sqlite、postgres）为目录，文件名为 <版本>_<名称>.up.sql 和 <版本>_<名称>.down.sql；
+// 已发布的迁移不可修改，结构变更需新增版本
+//
+//go:embed sqlite/*.sql postgres/*.sql
+var FS embed.FS
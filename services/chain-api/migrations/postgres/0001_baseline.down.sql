@@ -0,0 +1,37 @@
+DROP TABLE IF EXISTS "dead_letters";
+DROP TABLE IF EXISTS "token_approvals";
+DROP TABLE IF EXISTS "token_balances";
+DROP TABLE IF EXISTS "token_transfers";
+DROP TABLE IF EXISTS "collaborations";
+DROP TABLE IF EXISTS "reward_distributions";
+DROP TABLE IF EXISTS "ranking_refreshes";
+DROP TABLE IF EXISTS "influence_weights";
+DROP TABLE IF EXISTS "influence_changes";
+DROP TABLE IF EXISTS "verifier_constraints";
+DROP TABLE IF EXISTS "data_quality_metrics";
+DROP TABLE IF EXISTS "data_qualities";
+DROP TABLE IF EXISTS "constraint_evaluations";
+DROP TABLE IF EXISTS "validation_rules";
+DROP TABLE IF EXISTS "constraint_groups";
+DROP TABLE IF EXISTS "constraints";
+DROP TABLE IF EXISTS "proof_types";
+DROP TABLE IF EXISTS "proof_verifications";
+DROP TABLE IF EXISTS "proofs";
+DROP TABLE IF EXISTS "nft_owners";
+DROP TABLE IF EXISTS "nft_transfers";
+DROP TABLE IF EXISTS "research_metadata_updates";
+DROP TABLE IF EXISTS "research_revenues";
+DROP TABLE IF EXISTS "research_impact_changes";
+DROP TABLE IF EXISTS "research_reviews";
+DROP TABLE IF EXISTS "research_citations";
+DROP TABLE IF EXISTS "dataset_revenues";
+DROP TABLE IF EXISTS "dataset_quality_changes";
+DROP TABLE IF EXISTS "dataset_citations";
+DROP TABLE IF EXISTS "dataset_accesses";
+DROP TABLE IF EXISTS "reputation_changes";
+DROP TABLE IF EXISTS "user_profiles";
+DROP TABLE IF EXISTS "sync_cursors";
+DROP TABLE IF EXISTS "indexed_blocks";
+DROP TABLE IF EXISTS "event_logs";
+DROP TABLE IF EXISTS "dataset_records";
+DROP TABLE IF EXISTS "research_data";
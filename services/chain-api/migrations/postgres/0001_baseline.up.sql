@@ -0,0 +1,641 @@
+-- 基线结构：与引入版本化迁移前 AutoMigrate 创建的表一致，已有数据库可直接登记为已应用
+
+CREATE TABLE IF NOT EXISTS "research_data" (
+    "id" bigserial,
+    "token_id" text,
+    "title" text,
+    "authors" text,
+    "content_hash" text,
+    "metadata_hash" text,
+    "block_number" bigint,
+    "status" varchar(32) DEFAULT 'confirmed',
+    "owner" varchar(64),
+    "pub_type" smallint DEFAULT 0,
+    "pub_type_name" varchar(32),
+    "published_at" timestamptz,
+    "block_time" timestamptz,
+    "citation_count" bigint DEFAULT 0,
+    "review_count" bigint DEFAULT 0,
+    "average_score" decimal DEFAULT 0,
+    "impact_level" smallint DEFAULT 0,
+    "impact_level_name" varchar(32) DEFAULT 'low',
+    "total_revenue" varchar(78) DEFAULT '0',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_data_owner" ON "research_data" ("owner");
+CREATE INDEX IF NOT EXISTS "idx_research_data_block_number" ON "research_data" ("block_number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_research_data_token_id" ON "research_data" ("token_id");
+
+CREATE TABLE IF NOT EXISTS "dataset_records" (
+    "id" bigserial,
+    "dataset_id" varchar(255),
+    "title" text,
+    "description" text,
+    "owner" varchar(255),
+    "data_hash" text,
+    "block_number" bigint,
+    "status" varchar(32) DEFAULT 'confirmed',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dataset_records_owner" ON "dataset_records" ("owner");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_dataset_records_dataset_id" ON "dataset_records" ("dataset_id");
+CREATE INDEX IF NOT EXISTS "idx_dataset_records_block_number" ON "dataset_records" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "event_logs" (
+    "id" bigserial,
+    "tx_hash" varchar(255),
+    "log_index" bigint,
+    "block_number" bigint,
+    "event_name" varchar(255),
+    "entity_id" varchar(255),
+    "contract_addr" varchar(64),
+    "topic0" varchar(66),
+    "topics" text,
+    "data" text,
+    "block_hash" varchar(66),
+    "block_time" timestamptz,
+    "args" text,
+    "payload_raw" text,
+    "status" varchar(32) DEFAULT 'confirmed',
+    "processed" boolean DEFAULT false,
+    "attempts" bigint DEFAULT 0,
+    "last_error" text,
+    "next_attempt_at" timestamptz,
+    "dead_lettered" boolean DEFAULT false,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_event_logs_entity_id" ON "event_logs" ("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_dead_lettered" ON "event_logs" ("dead_lettered");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_next_attempt_at" ON "event_logs" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_status" ON "event_logs" ("status");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_topic0" ON "event_logs" ("topic0");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_contract_addr" ON "event_logs" ("contract_addr");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_event_name" ON "event_logs" ("event_name");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_block_number" ON "event_logs" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_event_logs_tx_hash" ON "event_logs" ("tx_hash");
+
+CREATE TABLE IF NOT EXISTS "indexed_blocks" (
+    "number" bigint,
+    "hash" varchar(66),
+    "parent_hash" varchar(66),
+    "created_at" timestamptz,
+    PRIMARY KEY ("number")
+);
+
+CREATE TABLE IF NOT EXISTS "sync_cursors" (
+    "contract_addr" varchar(64),
+    "last_block" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("contract_addr")
+);
+
+CREATE TABLE IF NOT EXISTS "user_profiles" (
+    "id" bigserial,
+    "wallet_address" varchar(64),
+    "name" text,
+    "role" smallint,
+    "role_name" varchar(32),
+    "registered" boolean,
+    "verification_status" varchar(32),
+    "requested_role" smallint,
+    "verification_req_id" varchar(78),
+    "verifier" varchar(64),
+    "verified_at" timestamptz,
+    "reputation" varchar(78) DEFAULT '0',
+    "access_roles" text,
+    "registered_at" timestamptz,
+    "block_number" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_profiles_block_number" ON "user_profiles" ("block_number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_profiles_wallet_address" ON "user_profiles" ("wallet_address");
+
+CREATE TABLE IF NOT EXISTS "reputation_changes" (
+    "id" bigserial,
+    "wallet_address" varchar(64),
+    "old_reputation" varchar(78),
+    "new_reputation" varchar(78),
+    "reason" text,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reputation_changes_block_number" ON "reputation_changes" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_reputation_changes_wallet_address" ON "reputation_changes" ("wallet_address");
+
+CREATE TABLE IF NOT EXISTS "dataset_accesses" (
+    "id" bigserial,
+    "dataset_id" varchar(255),
+    "user" varchar(64),
+    "price_paid" varchar(78),
+    "accessed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dataset_accesses_block_number" ON "dataset_accesses" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_dataset_accesses_user" ON "dataset_accesses" ("user");
+CREATE INDEX IF NOT EXISTS "idx_dataset_accesses_dataset_id" ON "dataset_accesses" ("dataset_id");
+
+CREATE TABLE IF NOT EXISTS "dataset_citations" (
+    "id" bigserial,
+    "dataset_id" varchar(255),
+    "researcher" varchar(64),
+    "publication_hash" text,
+    "cited_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dataset_citations_block_number" ON "dataset_citations" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_dataset_citations_researcher" ON "dataset_citations" ("researcher");
+CREATE INDEX IF NOT EXISTS "idx_dataset_citations_dataset_id" ON "dataset_citations" ("dataset_id");
+
+CREATE TABLE IF NOT EXISTS "dataset_quality_changes" (
+    "id" bigserial,
+    "dataset_id" varchar(255),
+    "old_level" smallint,
+    "new_level" smallint,
+    "level_name" varchar(32),
+    "verifier" varchar(64),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dataset_quality_changes_block_number" ON "dataset_quality_changes" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_dataset_quality_changes_dataset_id" ON "dataset_quality_changes" ("dataset_id");
+
+CREATE TABLE IF NOT EXISTS "dataset_revenues" (
+    "id" bigserial,
+    "dataset_id" varchar(255),
+    "owner" varchar(64),
+    "owner_share" varchar(78),
+    "platform_share" varchar(78),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dataset_revenues_block_number" ON "dataset_revenues" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_dataset_revenues_owner" ON "dataset_revenues" ("owner");
+CREATE INDEX IF NOT EXISTS "idx_dataset_revenues_dataset_id" ON "dataset_revenues" ("dataset_id");
+
+CREATE TABLE IF NOT EXISTS "research_citations" (
+    "id" bigserial,
+    "from_token_id" varchar(255),
+    "to_token_id" varchar(255),
+    "citer" varchar(64),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_citations_block_number" ON "research_citations" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_research_citations_to_token_id" ON "research_citations" ("to_token_id");
+CREATE INDEX IF NOT EXISTS "idx_research_citations_from_token_id" ON "research_citations" ("from_token_id");
+
+CREATE TABLE IF NOT EXISTS "research_reviews" (
+    "id" bigserial,
+    "token_id" varchar(255),
+    "reviewer" varchar(64),
+    "score" smallint,
+    "is_anonymous" boolean,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_reviews_block_number" ON "research_reviews" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_research_reviews_token_id" ON "research_reviews" ("token_id");
+
+CREATE TABLE IF NOT EXISTS "research_impact_changes" (
+    "id" bigserial,
+    "token_id" varchar(255),
+    "old_level" smallint,
+    "new_level" smallint,
+    "level_name" varchar(32),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_impact_changes_block_number" ON "research_impact_changes" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_research_impact_changes_token_id" ON "research_impact_changes" ("token_id");
+
+CREATE TABLE IF NOT EXISTS "research_revenues" (
+    "id" bigserial,
+    "token_id" varchar(255),
+    "authors" text,
+    "shares" text,
+    "total_amount" varchar(78),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_revenues_block_number" ON "research_revenues" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_research_revenues_token_id" ON "research_revenues" ("token_id");
+
+CREATE TABLE IF NOT EXISTS "research_metadata_updates" (
+    "id" bigserial,
+    "from_token_id" varchar(78),
+    "to_token_id" varchar(78),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_research_metadata_updates_block_number" ON "research_metadata_updates" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "nft_transfers" (
+    "id" bigserial,
+    "collection" varchar(32),
+    "token_id" varchar(78),
+    "from_address" varchar(64),
+    "to_address" varchar(64),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_nft_transfer_token" ON "nft_transfers" ("collection","token_id");
+CREATE INDEX IF NOT EXISTS "idx_nft_transfers_block_number" ON "nft_transfers" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_nft_transfers_to_address" ON "nft_transfers" ("to_address");
+CREATE INDEX IF NOT EXISTS "idx_nft_transfers_from_address" ON "nft_transfers" ("from_address");
+
+CREATE TABLE IF NOT EXISTS "nft_owners" (
+    "collection" varchar(32),
+    "token_id" varchar(78),
+    "owner" varchar(64),
+    "burned" boolean,
+    "block_number" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("collection","token_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_nft_owners_owner" ON "nft_owners" ("owner");
+CREATE INDEX IF NOT EXISTS "idx_nft_owners_block_number" ON "nft_owners" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "proofs" (
+    "id" bigserial,
+    "proof_id" varchar(78),
+    "submitter" varchar(64),
+    "proof_type" varchar(128),
+    "metadata_hash" text,
+    "status" varchar(32) DEFAULT 'submitted',
+    "verifier" varchar(64),
+    "submitted_at" timestamptz,
+    "verified_at" timestamptz,
+    "block_number" bigint,
+    "verified_block" bigint,
+    "tx_hash" varchar(66),
+    "proof_hash" varchar(66),
+    "check_status" varchar(32),
+    "check_reason" text,
+    "onchain_result" varchar(32),
+    "checked_at" timestamptz,
+    "trusted" boolean,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_proofs_proof_type" ON "proofs" ("proof_type");
+CREATE INDEX IF NOT EXISTS "idx_proofs_submitter" ON "proofs" ("submitter");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_proofs_proof_id" ON "proofs" ("proof_id");
+CREATE INDEX IF NOT EXISTS "idx_proofs_check_status" ON "proofs" ("check_status");
+CREATE INDEX IF NOT EXISTS "idx_proofs_proof_hash" ON "proofs" ("proof_hash");
+CREATE INDEX IF NOT EXISTS "idx_proofs_block_number" ON "proofs" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_proofs_status" ON "proofs" ("status");
+
+CREATE TABLE IF NOT EXISTS "proof_verifications" (
+    "id" bigserial,
+    "source" varchar(32),
+    "proof_id" varchar(78),
+    "proof_hash" varchar(66),
+    "is_valid" boolean,
+    "verifier" varchar(64),
+    "verified_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_proof_verifications_block_number" ON "proof_verifications" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_proof_verifications_proof_hash" ON "proof_verifications" ("proof_hash");
+CREATE INDEX IF NOT EXISTS "idx_proof_verifications_proof_id" ON "proof_verifications" ("proof_id");
+
+CREATE TABLE IF NOT EXISTS "proof_types" (
+    "name" varchar(128),
+    "verifier_contract" varchar(64),
+    "is_active" boolean,
+    "registered" boolean,
+    "registrant" varchar(64),
+    "block_number" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("name")
+);
+CREATE INDEX IF NOT EXISTS "idx_proof_types_block_number" ON "proof_types" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "constraints" (
+    "constraint_id" varchar(66),
+    "name" text,
+    "description" text,
+    "category" smallint,
+    "category_name" varchar(32),
+    "operator" smallint,
+    "operator_name" varchar(32),
+    "thresholds" text,
+    "priority" varchar(78),
+    "weight" varchar(78),
+    "applicable_fields" text,
+    "is_global" boolean,
+    "is_active" boolean,
+    "creator" varchar(64),
+    "block_number" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("constraint_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_constraints_block_number" ON "constraints" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_constraints_category_name" ON "constraints" ("category_name");
+
+CREATE TABLE IF NOT EXISTS "constraint_groups" (
+    "group_id" varchar(66),
+    "name" text,
+    "description" text,
+    "constraint_ids" text,
+    "min_satisfaction" varchar(78),
+    "total_weight" varchar(78),
+    "is_active" boolean,
+    "creator" varchar(64),
+    "block_number" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("group_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_constraint_groups_block_number" ON "constraint_groups" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "validation_rules" (
+    "rule_id" varchar(66),
+    "name" text,
+    "description" text,
+    "group_ids" text,
+    "min_score" varchar(78),
+    "is_active" boolean,
+    "creator" varchar(64),
+    "block_number" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("rule_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_validation_rules_block_number" ON "validation_rules" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "constraint_evaluations" (
+    "id" bigserial,
+    "constraint_id" varchar(66),
+    "result" boolean,
+    "score" varchar(78),
+    "evaluated_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_constraint_evaluations_block_number" ON "constraint_evaluations" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_constraint_evaluations_constraint_id" ON "constraint_evaluations" ("constraint_id");
+CREATE INDEX IF NOT EXISTS "idx_constraint_evaluations_tx_hash" ON "constraint_evaluations" ("tx_hash");
+
+CREATE TABLE IF NOT EXISTS "data_qualities" (
+    "id" bigserial,
+    "source" varchar(32),
+    "subject_id" varchar(66),
+    "data_hash" text,
+    "feature_hash" text,
+    "data_type" varchar(64),
+    "data_count" varchar(78),
+    "submitter" varchar(64),
+    "mean" varchar(78),
+    "standard_deviation" varchar(78),
+    "min_value" varchar(78),
+    "max_value" varchar(78),
+    "score" varchar(78),
+    "is_valid" boolean,
+    "verification_status" varchar(32),
+    "block_number" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_data_qualities_block_number" ON "data_qualities" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_data_qualities_submitter" ON "data_qualities" ("submitter");
+CREATE INDEX IF NOT EXISTS "idx_data_qualities_data_hash" ON "data_qualities" ("data_hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_data_quality_subject" ON "data_qualities" ("source","subject_id");
+
+CREATE TABLE IF NOT EXISTS "data_quality_metrics" (
+    "id" bigserial,
+    "source" varchar(32),
+    "subject_id" varchar(66),
+    "event_name" varchar(64),
+    "data_hash" text,
+    "feature_hash" text,
+    "data_type" varchar(64),
+    "data_count" varchar(78),
+    "submitter" varchar(64),
+    "mean" varchar(78),
+    "standard_deviation" varchar(78),
+    "min_value" varchar(78),
+    "max_value" varchar(78),
+    "score" varchar(78),
+    "is_valid" boolean,
+    "verification_status" varchar(32),
+    "recorded_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_data_quality_metrics_block_number" ON "data_quality_metrics" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_data_quality_metric_subject" ON "data_quality_metrics" ("source","subject_id");
+
+CREATE TABLE IF NOT EXISTS "verifier_constraints" (
+    "id" bigserial,
+    "constraint_type" varchar(64),
+    "threshold" varchar(78),
+    "description" text,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_verifier_constraints_block_number" ON "verifier_constraints" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_verifier_constraints_constraint_type" ON "verifier_constraints" ("constraint_type");
+
+CREATE TABLE IF NOT EXISTS "influence_changes" (
+    "id" bigserial,
+    "user_address" varchar(64),
+    "event_name" varchar(64),
+    "old_influence" varchar(78),
+    "new_influence" varchar(78),
+    "old_rank" varchar(78),
+    "new_rank" varchar(78),
+    "publication_score" varchar(78),
+    "review_score" varchar(78),
+    "data_contribution" varchar(78),
+    "collaboration_score" varchar(78),
+    "governance_score" varchar(78),
+    "changed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_influence_changes_block_number" ON "influence_changes" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_influence_changes_user" ON "influence_changes" ("user_address");
+
+CREATE TABLE IF NOT EXISTS "influence_weights" (
+    "id" bigserial,
+    "publication_weight" varchar(78),
+    "review_weight" varchar(78),
+    "data_weight" varchar(78),
+    "collaboration_weight" varchar(78),
+    "governance_weight" varchar(78),
+    "changed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_influence_weights_block_number" ON "influence_weights" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "ranking_refreshes" (
+    "id" bigserial,
+    "ranking_type" smallint,
+    "ranking_type_name" varchar(32),
+    "identifier" varchar(255),
+    "refreshed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_ranking_refreshes_block_number" ON "ranking_refreshes" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "reward_distributions" (
+    "id" bigserial,
+    "user_address" varchar(64),
+    "amount" varchar(78),
+    "reason" text,
+    "distributed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reward_distributions_block_number" ON "reward_distributions" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_reward_distributions_user" ON "reward_distributions" ("user_address");
+
+CREATE TABLE IF NOT EXISTS "collaborations" (
+    "id" bigserial,
+    "user1" varchar(64),
+    "user2" varchar(64),
+    "research_id" varchar(78),
+    "formed_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_collaborations_research_id" ON "collaborations" ("research_id");
+CREATE INDEX IF NOT EXISTS "idx_collaborations_user2" ON "collaborations" ("user2");
+CREATE INDEX IF NOT EXISTS "idx_collaborations_user1" ON "collaborations" ("user1");
+CREATE INDEX IF NOT EXISTS "idx_collaborations_block_number" ON "collaborations" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "token_transfers" (
+    "id" bigserial,
+    "from_address" varchar(64),
+    "to_address" varchar(64),
+    "value" varchar(78),
+    "transferred_at" timestamptz,
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_token_transfers_block_number" ON "token_transfers" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_token_transfers_to_address" ON "token_transfers" ("to_address");
+CREATE INDEX IF NOT EXISTS "idx_token_transfers_from_address" ON "token_transfers" ("from_address");
+
+CREATE TABLE IF NOT EXISTS "token_balances" (
+    "holder" varchar(64),
+    "balance" varchar(78),
+    "block_number" bigint,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("holder")
+);
+CREATE INDEX IF NOT EXISTS "idx_token_balances_block_number" ON "token_balances" ("block_number");
+
+CREATE TABLE IF NOT EXISTS "token_approvals" (
+    "id" bigserial,
+    "owner" varchar(64),
+    "spender" varchar(64),
+    "value" varchar(78),
+    "block_number" bigint,
+    "tx_hash" varchar(66),
+    "log_index" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_token_approvals_block_number" ON "token_approvals" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_token_approvals_owner" ON "token_approvals" ("owner");
+
+CREATE TABLE IF NOT EXISTS "dead_letters" (
+    "id" bigserial,
+    "event_log_id" bigint,
+    "source" varchar(32),
+    "event_name" varchar(255),
+    "tx_hash" varchar(255),
+    "log_index" bigint,
+    "block_number" bigint,
+    "contract_addr" varchar(64),
+    "attempts" bigint,
+    "error" text,
+    "stack" text,
+    "payload_raw" text,
+    "status" varchar(32) DEFAULT 'dead',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_event_name" ON "dead_letters" ("event_name");
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_source" ON "dead_letters" ("source");
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_event_log_id" ON "dead_letters" ("event_log_id");
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_status" ON "dead_letters" ("status");
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_block_number" ON "dead_letters" ("block_number");
+CREATE INDEX IF NOT EXISTS "idx_dead_letters_tx_hash" ON "dead_letters" ("tx_hash");
@@ -0,0 +1,21 @@
+-- 研究成果署名作者的规范化索引，替代对 authors JSON 文本的 LIKE 扫描
+
+CREATE TABLE "research_authors" (
+    "id" bigserial,
+    "token_id" varchar(255),
+    "author" varchar(255),
+    "position" bigint,
+    "block_number" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_research_authors_token_position" ON "research_authors" ("token_id","position");
+CREATE INDEX "idx_research_authors_author" ON "research_authors" ("author","token_id");
+CREATE INDEX "idx_research_authors_block_number" ON "research_authors" ("block_number");
+
+-- 回填已有研究成果：地址转小写，同一作者重复署名时保留第一次出现的位置
+INSERT INTO "research_authors" ("token_id", "author", "position", "block_number")
+SELECT r."token_id", lower(a.value), MIN(a.ordinality) - 1, r."block_number"
+FROM "research_data" r
+CROSS JOIN LATERAL jsonb_array_elements_text(r."authors"::jsonb) WITH ORDINALITY AS a(value, ordinality)
+WHERE r."authors" LIKE '[%'
+GROUP BY r."token_id", lower(a.value), r."block_number";
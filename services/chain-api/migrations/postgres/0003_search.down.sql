@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_dataset_records_search";
+DROP INDEX IF EXISTS "idx_research_data_search";
@@ -0,0 +1,11 @@
+-- 全文检索：研究成果标题与作者、数据集标题与描述的加权 tsvector 表达式索引
+-- 使用 simple 配置，不做词干化，地址和中英文混排标题按原词匹配；查询必须使用相同的表达式才能命中索引
+
+CREATE INDEX IF NOT EXISTS "idx_research_data_search" ON "research_data" USING GIN ((
+    setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce("authors", '')), 'B')
+));
+CREATE INDEX IF NOT EXISTS "idx_dataset_records_search" ON "dataset_records" USING GIN ((
+    setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce("description", '')), 'B')
+));
@@ -0,0 +1 @@
+-- 原始大小写无法恢复，小写地址在回退后仍可正常使用
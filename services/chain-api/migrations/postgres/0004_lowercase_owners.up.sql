@@ -0,0 +1,4 @@
+-- 持有人地址统一以小写存储：Transfer 投影写入小写地址，仅经创建事件写入的记录此前保留了校验和格式
+
+UPDATE "research_data" SET "owner" = lower("owner") WHERE "owner" <> lower("owner");
+UPDATE "dataset_records" SET "owner" = lower("owner") WHERE "owner" <> lower("owner");
@@ -0,0 +1,16 @@
+-- 排行榜排序键：左补零到 78 位的 uint256 数额，持有人和影响力排行榜在 SQL 中按索引键集分页
+-- influence_changes.is_latest 标记每个用户最新一条 InfluenceUpdated 记录
+
+ALTER TABLE "token_balances" ADD COLUMN IF NOT EXISTS "balance_key" varchar(78);
+UPDATE "token_balances" SET "balance_key" = lpad(COALESCE("balance", ''), 78, '0');
+CREATE INDEX IF NOT EXISTS "idx_token_balances_balance_key" ON "token_balances" ("balance_key");
+
+ALTER TABLE "influence_changes" ADD COLUMN IF NOT EXISTS "is_latest" boolean;
+ALTER TABLE "influence_changes" ADD COLUMN IF NOT EXISTS "influence_key" varchar(78);
+UPDATE "influence_changes" SET "influence_key" = lpad(COALESCE("new_influence", ''), 78, '0');
+UPDATE "influence_changes" SET "is_latest" = COALESCE("id" = (
+    SELECT c."id" FROM "influence_changes" c
+    WHERE c."user_address" = "influence_changes"."user_address" AND c."event_name" = 'InfluenceUpdated'
+    ORDER BY c."block_number" DESC, c."log_index" DESC LIMIT 1
+), false);
+CREATE INDEX IF NOT EXISTS "idx_influence_leaderboard" ON "influence_changes" ("is_latest", "influence_key");
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_research_data_search";
+CREATE INDEX IF NOT EXISTS "idx_research_data_search" ON "research_data" USING GIN ((
+    setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce("authors", '')), 'B')
+));
+ALTER TABLE "research_data" DROP COLUMN IF EXISTS "authors_text";
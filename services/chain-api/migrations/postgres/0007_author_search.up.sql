@@ -0,0 +1,15 @@
+-- 研究成果检索文本：按署名顺序拼接作者的显示名称（user_profiles.name）和地址，
+-- 由 repository 在作者或用户档案变更时维护；检索索引改为覆盖标题和该列
+
+ALTER TABLE "research_data" ADD COLUMN IF NOT EXISTS "authors_text" text;
+UPDATE "research_data" SET "authors_text" = COALESCE((
+    SELECT string_agg(trim(COALESCE(p."name", '') || ' ' || a."author"), ', ' ORDER BY a."position")
+    FROM "research_authors" a LEFT JOIN "user_profiles" p ON p."wallet_address" = a."author"
+    WHERE a."token_id" = "research_data"."token_id"
+), '');
+
+DROP INDEX IF EXISTS "idx_research_data_search";
+CREATE INDEX IF NOT EXISTS "idx_research_data_search" ON "research_data" USING GIN ((
+    setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce("authors_text", '')), 'B')
+));
@@ -0,0 +1,612 @@
+-- 基线结构：与引入版本化迁移前 AutoMigrate 创建的表一致，已有数据库可直接登记为已应用
+
+CREATE TABLE IF NOT EXISTS `research_data` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `token_id` text,
+    `title` text,
+    `authors` text,
+    `content_hash` text,
+    `metadata_hash` text,
+    `block_number` integer,
+    `status` text DEFAULT 'confirmed',
+    `owner` text,
+    `pub_type` integer DEFAULT 0,
+    `pub_type_name` text,
+    `published_at` datetime,
+    `block_time` datetime,
+    `citation_count` integer DEFAULT 0,
+    `review_count` integer DEFAULT 0,
+    `average_score` real DEFAULT 0,
+    `impact_level` integer DEFAULT 0,
+    `impact_level_name` text DEFAULT 'low',
+    `total_revenue` text DEFAULT '0',
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_data_block_number` ON `research_data`(`block_number`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_research_data_token_id` ON `research_data`(`token_id`);
+CREATE INDEX IF NOT EXISTS `idx_research_data_owner` ON `research_data`(`owner`);
+
+CREATE TABLE IF NOT EXISTS `dataset_records` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `dataset_id` text,
+    `title` text,
+    `description` text,
+    `owner` text,
+    `data_hash` text,
+    `block_number` integer,
+    `status` text DEFAULT 'confirmed',
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dataset_records_block_number` ON `dataset_records`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_records_owner` ON `dataset_records`(`owner`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_dataset_records_dataset_id` ON `dataset_records`(`dataset_id`);
+
+CREATE TABLE IF NOT EXISTS `event_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `tx_hash` text,
+    `log_index` integer,
+    `block_number` integer,
+    `event_name` text,
+    `entity_id` text,
+    `contract_addr` text,
+    `topic0` text,
+    `topics` text,
+    `data` text,
+    `block_hash` text,
+    `block_time` datetime,
+    `args` text,
+    `payload_raw` text,
+    `status` text DEFAULT 'confirmed',
+    `processed` numeric DEFAULT false,
+    `attempts` integer DEFAULT 0,
+    `last_error` text,
+    `next_attempt_at` datetime,
+    `dead_lettered` numeric DEFAULT false,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_contract_addr` ON `event_logs`(`contract_addr`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_block_number` ON `event_logs`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_dead_lettered` ON `event_logs`(`dead_lettered`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_next_attempt_at` ON `event_logs`(`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_status` ON `event_logs`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_topic0` ON `event_logs`(`topic0`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_entity_id` ON `event_logs`(`entity_id`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_event_name` ON `event_logs`(`event_name`);
+CREATE INDEX IF NOT EXISTS `idx_event_logs_tx_hash` ON `event_logs`(`tx_hash`);
+
+CREATE TABLE IF NOT EXISTS `indexed_blocks` (
+    `number` integer,
+    `hash` text,
+    `parent_hash` text,
+    `created_at` datetime,
+    PRIMARY KEY (`number`)
+);
+
+CREATE TABLE IF NOT EXISTS `sync_cursors` (
+    `contract_addr` text,
+    `last_block` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`contract_addr`)
+);
+
+CREATE TABLE IF NOT EXISTS `user_profiles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `wallet_address` text,
+    `name` text,
+    `role` integer,
+    `role_name` text,
+    `registered` numeric,
+    `verification_status` text,
+    `requested_role` integer,
+    `verification_req_id` text,
+    `verifier` text,
+    `verified_at` datetime,
+    `reputation` text DEFAULT '0',
+    `access_roles` text,
+    `registered_at` datetime,
+    `block_number` integer,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_user_profiles_block_number` ON `user_profiles`(`block_number`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_user_profiles_wallet_address` ON `user_profiles`(`wallet_address`);
+
+CREATE TABLE IF NOT EXISTS `reputation_changes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `wallet_address` text,
+    `old_reputation` text,
+    `new_reputation` text,
+    `reason` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_reputation_changes_block_number` ON `reputation_changes`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_reputation_changes_wallet_address` ON `reputation_changes`(`wallet_address`);
+
+CREATE TABLE IF NOT EXISTS `dataset_accesses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `dataset_id` text,
+    `user` text,
+    `price_paid` text,
+    `accessed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dataset_accesses_block_number` ON `dataset_accesses`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_accesses_user` ON `dataset_accesses`(`user`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_accesses_dataset_id` ON `dataset_accesses`(`dataset_id`);
+
+CREATE TABLE IF NOT EXISTS `dataset_citations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `dataset_id` text,
+    `researcher` text,
+    `publication_hash` text,
+    `cited_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dataset_citations_block_number` ON `dataset_citations`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_citations_researcher` ON `dataset_citations`(`researcher`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_citations_dataset_id` ON `dataset_citations`(`dataset_id`);
+
+CREATE TABLE IF NOT EXISTS `dataset_quality_changes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `dataset_id` text,
+    `old_level` integer,
+    `new_level` integer,
+    `level_name` text,
+    `verifier` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dataset_quality_changes_block_number` ON `dataset_quality_changes`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_quality_changes_dataset_id` ON `dataset_quality_changes`(`dataset_id`);
+
+CREATE TABLE IF NOT EXISTS `dataset_revenues` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `dataset_id` text,
+    `owner` text,
+    `owner_share` text,
+    `platform_share` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dataset_revenues_block_number` ON `dataset_revenues`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_revenues_owner` ON `dataset_revenues`(`owner`);
+CREATE INDEX IF NOT EXISTS `idx_dataset_revenues_dataset_id` ON `dataset_revenues`(`dataset_id`);
+
+CREATE TABLE IF NOT EXISTS `research_citations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `from_token_id` text,
+    `to_token_id` text,
+    `citer` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_citations_block_number` ON `research_citations`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_research_citations_to_token_id` ON `research_citations`(`to_token_id`);
+CREATE INDEX IF NOT EXISTS `idx_research_citations_from_token_id` ON `research_citations`(`from_token_id`);
+
+CREATE TABLE IF NOT EXISTS `research_reviews` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `token_id` text,
+    `reviewer` text,
+    `score` integer,
+    `is_anonymous` numeric,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_reviews_block_number` ON `research_reviews`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_research_reviews_token_id` ON `research_reviews`(`token_id`);
+
+CREATE TABLE IF NOT EXISTS `research_impact_changes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `token_id` text,
+    `old_level` integer,
+    `new_level` integer,
+    `level_name` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_impact_changes_block_number` ON `research_impact_changes`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_research_impact_changes_token_id` ON `research_impact_changes`(`token_id`);
+
+CREATE TABLE IF NOT EXISTS `research_revenues` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `token_id` text,
+    `authors` text,
+    `shares` text,
+    `total_amount` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_revenues_block_number` ON `research_revenues`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_research_revenues_token_id` ON `research_revenues`(`token_id`);
+
+CREATE TABLE IF NOT EXISTS `research_metadata_updates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `from_token_id` text,
+    `to_token_id` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_research_metadata_updates_block_number` ON `research_metadata_updates`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `nft_transfers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `collection` text,
+    `token_id` text,
+    `from_address` text,
+    `to_address` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_nft_transfers_block_number` ON `nft_transfers`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_nft_transfers_to_address` ON `nft_transfers`(`to_address`);
+CREATE INDEX IF NOT EXISTS `idx_nft_transfers_from_address` ON `nft_transfers`(`from_address`);
+CREATE INDEX IF NOT EXISTS `idx_nft_transfer_token` ON `nft_transfers`(`collection`,`token_id`);
+
+CREATE TABLE IF NOT EXISTS `nft_owners` (
+    `collection` text,
+    `token_id` text,
+    `owner` text,
+    `burned` numeric,
+    `block_number` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`collection`,`token_id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_nft_owners_block_number` ON `nft_owners`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_nft_owners_owner` ON `nft_owners`(`owner`);
+
+CREATE TABLE IF NOT EXISTS `proofs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `proof_id` text,
+    `submitter` text,
+    `proof_type` text,
+    `metadata_hash` text,
+    `status` text DEFAULT 'submitted',
+    `verifier` text,
+    `submitted_at` datetime,
+    `verified_at` datetime,
+    `block_number` integer,
+    `verified_block` integer,
+    `tx_hash` text,
+    `proof_hash` text,
+    `check_status` text,
+    `check_reason` text,
+    `onchain_result` text,
+    `checked_at` datetime,
+    `trusted` numeric,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_proofs_check_status` ON `proofs`(`check_status`);
+CREATE INDEX IF NOT EXISTS `idx_proofs_proof_hash` ON `proofs`(`proof_hash`);
+CREATE INDEX IF NOT EXISTS `idx_proofs_block_number` ON `proofs`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_proofs_status` ON `proofs`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_proofs_proof_type` ON `proofs`(`proof_type`);
+CREATE INDEX IF NOT EXISTS `idx_proofs_submitter` ON `proofs`(`submitter`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_proofs_proof_id` ON `proofs`(`proof_id`);
+
+CREATE TABLE IF NOT EXISTS `proof_verifications` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `source` text,
+    `proof_id` text,
+    `proof_hash` text,
+    `is_valid` numeric,
+    `verifier` text,
+    `verified_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_proof_verifications_block_number` ON `proof_verifications`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_proof_verifications_proof_hash` ON `proof_verifications`(`proof_hash`);
+CREATE INDEX IF NOT EXISTS `idx_proof_verifications_proof_id` ON `proof_verifications`(`proof_id`);
+
+CREATE TABLE IF NOT EXISTS `proof_types` (
+    `name` text,
+    `verifier_contract` text,
+    `is_active` numeric,
+    `registered` numeric,
+    `registrant` text,
+    `block_number` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`name`)
+);
+CREATE INDEX IF NOT EXISTS `idx_proof_types_block_number` ON `proof_types`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `constraints` (
+    `constraint_id` text,
+    `name` text,
+    `description` text,
+    `category` integer,
+    `category_name` text,
+    `operator` integer,
+    `operator_name` text,
+    `thresholds` text,
+    `priority` text,
+    `weight` text,
+    `applicable_fields` text,
+    `is_global` numeric,
+    `is_active` numeric,
+    `creator` text,
+    `block_number` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`constraint_id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_constraints_block_number` ON `constraints`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_constraints_category_name` ON `constraints`(`category_name`);
+
+CREATE TABLE IF NOT EXISTS `constraint_groups` (
+    `group_id` text,
+    `name` text,
+    `description` text,
+    `constraint_ids` text,
+    `min_satisfaction` text,
+    `total_weight` text,
+    `is_active` numeric,
+    `creator` text,
+    `block_number` integer,
+    `created_at` datetime,
+    PRIMARY KEY (`group_id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_constraint_groups_block_number` ON `constraint_groups`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `validation_rules` (
+    `rule_id` text,
+    `name` text,
+    `description` text,
+    `group_ids` text,
+    `min_score` text,
+    `is_active` numeric,
+    `creator` text,
+    `block_number` integer,
+    `created_at` datetime,
+    PRIMARY KEY (`rule_id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_validation_rules_block_number` ON `validation_rules`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `constraint_evaluations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `constraint_id` text,
+    `result` numeric,
+    `score` text,
+    `evaluated_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_constraint_evaluations_tx_hash` ON `constraint_evaluations`(`tx_hash`);
+CREATE INDEX IF NOT EXISTS `idx_constraint_evaluations_block_number` ON `constraint_evaluations`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_constraint_evaluations_constraint_id` ON `constraint_evaluations`(`constraint_id`);
+
+CREATE TABLE IF NOT EXISTS `data_qualities` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `source` text,
+    `subject_id` text,
+    `data_hash` text,
+    `feature_hash` text,
+    `data_type` text,
+    `data_count` text,
+    `submitter` text,
+    `mean` text,
+    `standard_deviation` text,
+    `min_value` text,
+    `max_value` text,
+    `score` text,
+    `is_valid` numeric,
+    `verification_status` text,
+    `block_number` integer,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_data_qualities_block_number` ON `data_qualities`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_data_qualities_submitter` ON `data_qualities`(`submitter`);
+CREATE INDEX IF NOT EXISTS `idx_data_qualities_data_hash` ON `data_qualities`(`data_hash`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_data_quality_subject` ON `data_qualities`(`source`,`subject_id`);
+
+CREATE TABLE IF NOT EXISTS `data_quality_metrics` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `source` text,
+    `subject_id` text,
+    `event_name` text,
+    `data_hash` text,
+    `feature_hash` text,
+    `data_type` text,
+    `data_count` text,
+    `submitter` text,
+    `mean` text,
+    `standard_deviation` text,
+    `min_value` text,
+    `max_value` text,
+    `score` text,
+    `is_valid` numeric,
+    `verification_status` text,
+    `recorded_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_data_quality_metric_subject` ON `data_quality_metrics`(`source`,`subject_id`);
+CREATE INDEX IF NOT EXISTS `idx_data_quality_metrics_block_number` ON `data_quality_metrics`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `verifier_constraints` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `constraint_type` text,
+    `threshold` text,
+    `description` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_verifier_constraints_block_number` ON `verifier_constraints`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_verifier_constraints_constraint_type` ON `verifier_constraints`(`constraint_type`);
+
+CREATE TABLE IF NOT EXISTS `influence_changes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_address` text,
+    `event_name` text,
+    `old_influence` text,
+    `new_influence` text,
+    `old_rank` text,
+    `new_rank` text,
+    `publication_score` text,
+    `review_score` text,
+    `data_contribution` text,
+    `collaboration_score` text,
+    `governance_score` text,
+    `changed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_influence_changes_user` ON `influence_changes`(`user_address`);
+CREATE INDEX IF NOT EXISTS `idx_influence_changes_block_number` ON `influence_changes`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `influence_weights` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `publication_weight` text,
+    `review_weight` text,
+    `data_weight` text,
+    `collaboration_weight` text,
+    `governance_weight` text,
+    `changed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_influence_weights_block_number` ON `influence_weights`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `ranking_refreshes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `ranking_type` integer,
+    `ranking_type_name` text,
+    `identifier` text,
+    `refreshed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_ranking_refreshes_block_number` ON `ranking_refreshes`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `reward_distributions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_address` text,
+    `amount` text,
+    `reason` text,
+    `distributed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_reward_distributions_block_number` ON `reward_distributions`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_reward_distributions_user` ON `reward_distributions`(`user_address`);
+
+CREATE TABLE IF NOT EXISTS `collaborations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user1` text,
+    `user2` text,
+    `research_id` text,
+    `formed_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_collaborations_user2` ON `collaborations`(`user2`);
+CREATE INDEX IF NOT EXISTS `idx_collaborations_user1` ON `collaborations`(`user1`);
+CREATE INDEX IF NOT EXISTS `idx_collaborations_block_number` ON `collaborations`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_collaborations_research_id` ON `collaborations`(`research_id`);
+
+CREATE TABLE IF NOT EXISTS `token_transfers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `from_address` text,
+    `to_address` text,
+    `value` text,
+    `transferred_at` datetime,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_token_transfers_block_number` ON `token_transfers`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_token_transfers_to_address` ON `token_transfers`(`to_address`);
+CREATE INDEX IF NOT EXISTS `idx_token_transfers_from_address` ON `token_transfers`(`from_address`);
+
+CREATE TABLE IF NOT EXISTS `token_balances` (
+    `holder` text,
+    `balance` text,
+    `block_number` integer,
+    `updated_at` datetime,
+    PRIMARY KEY (`holder`)
+);
+CREATE INDEX IF NOT EXISTS `idx_token_balances_block_number` ON `token_balances`(`block_number`);
+
+CREATE TABLE IF NOT EXISTS `token_approvals` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `owner` text,
+    `spender` text,
+    `value` text,
+    `block_number` integer,
+    `tx_hash` text,
+    `log_index` integer,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_token_approvals_block_number` ON `token_approvals`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_token_approvals_owner` ON `token_approvals`(`owner`);
+
+CREATE TABLE IF NOT EXISTS `dead_letters` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `event_log_id` integer,
+    `source` text,
+    `event_name` text,
+    `tx_hash` text,
+    `log_index` integer,
+    `block_number` integer,
+    `contract_addr` text,
+    `attempts` integer,
+    `error` text,
+    `stack` text,
+    `payload_raw` text,
+    `status` text DEFAULT 'dead',
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_status` ON `dead_letters`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_block_number` ON `dead_letters`(`block_number`);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_tx_hash` ON `dead_letters`(`tx_hash`);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_event_name` ON `dead_letters`(`event_name`);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_source` ON `dead_letters`(`source`);
+CREATE INDEX IF NOT EXISTS `idx_dead_letters_event_log_id` ON `dead_letters`(`event_log_id`);
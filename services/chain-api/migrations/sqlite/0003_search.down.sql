@@ -0,0 +1 @@
+-- FTS5 索引及触发器由 repository.ensureSearchIndex 管理，回退时无需变更
@@ -0,0 +1,2 @@
+-- 全文检索：SQLite 的 FTS5 索引依赖驱动的编译标签，由 repository.ensureSearchIndex 在启动时建立或清理；
+-- 此版本仅保持与 postgres 迁移的版本号一致
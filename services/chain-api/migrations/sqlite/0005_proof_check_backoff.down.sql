@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_proofs_next_check_at`;
+ALTER TABLE `proofs` DROP COLUMN `next_check_at`;
+ALTER TABLE `proofs` DROP COLUMN `check_attempts`;
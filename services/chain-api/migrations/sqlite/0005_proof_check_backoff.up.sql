@@ -0,0 +1,5 @@
+-- 证明自动核对的重试次数和下次核对时间，避免链上长期无记录的证明占满每轮的核对名额
+
+ALTER TABLE `proofs` ADD COLUMN `check_attempts` integer DEFAULT 0;
+ALTER TABLE `proofs` ADD COLUMN `next_check_at` datetime;
+CREATE INDEX IF NOT EXISTS `idx_proofs_next_check_at` ON `proofs`(`next_check_at`);
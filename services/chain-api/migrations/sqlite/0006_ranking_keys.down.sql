@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS `idx_influence_leaderboard`;
+ALTER TABLE `influence_changes` DROP COLUMN `influence_key`;
+ALTER TABLE `influence_changes` DROP COLUMN `is_latest`;
+DROP INDEX IF EXISTS `idx_token_balances_balance_key`;
+ALTER TABLE `token_balances` DROP COLUMN `balance_key`;
@@ -0,0 +1,16 @@
+-- 排行榜排序键：左补零到 78 位的 uint256 数额，持有人和影响力排行榜在 SQL 中按索引键集分页
+-- influence_changes.is_latest 标记每个用户最新一条 InfluenceUpdated 记录
+
+ALTER TABLE `token_balances` ADD COLUMN `balance_key` text;
+UPDATE `token_balances` SET `balance_key` = substr('000000000000000000000000000000000000000000000000000000000000000000000000000000' || COALESCE(`balance`, ''), -78, 78);
+CREATE INDEX IF NOT EXISTS `idx_token_balances_balance_key` ON `token_balances`(`balance_key`);
+
+ALTER TABLE `influence_changes` ADD COLUMN `is_latest` numeric;
+ALTER TABLE `influence_changes` ADD COLUMN `influence_key` text;
+UPDATE `influence_changes` SET `influence_key` = substr('000000000000000000000000000000000000000000000000000000000000000000000000000000' || COALESCE(`new_influence`, ''), -78, 78);
+UPDATE `influence_changes` SET `is_latest` = COALESCE(`id` = (
+    SELECT c.`id` FROM `influence_changes` c
+    WHERE c.`user_address` = `influence_changes`.`user_address` AND c.`event_name` = 'InfluenceUpdated'
+    ORDER BY c.`block_number` DESC, c.`log_index` DESC LIMIT 1
+), 0);
+CREATE INDEX IF NOT EXISTS `idx_influence_leaderboard` ON `influence_changes`(`is_latest`,`influence_key`);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS `research_search_ai`;
+DROP TRIGGER IF EXISTS `research_search_ad`;
+DROP TRIGGER IF EXISTS `research_search_au`;
+ALTER TABLE `research_data` DROP COLUMN `authors_text`;
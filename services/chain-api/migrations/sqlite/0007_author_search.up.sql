@@ -0,0 +1,17 @@
+-- 研究成果检索文本：按署名顺序拼接作者的显示名称（user_profiles.name）和地址，
+-- 由 repository 在作者或用户档案变更时维护
+-- 删除引用旧检索列的 FTS5 触发器，ensureSearchIndex 在启动时按新列重建索引
+
+DROP TRIGGER IF EXISTS `research_search_ai`;
+DROP TRIGGER IF EXISTS `research_search_ad`;
+DROP TRIGGER IF EXISTS `research_search_au`;
+
+ALTER TABLE `research_data` ADD COLUMN `authors_text` text;
+UPDATE `research_data` SET `authors_text` = COALESCE((
+    SELECT group_concat(e.`entry`, ', ') FROM (
+        SELECT trim(COALESCE(p.`name`, '') || ' ' || a.`author`) AS `entry`
+        FROM `research_authors` a LEFT JOIN `user_profiles` p ON p.`wallet_address` = a.`author`
+        WHERE a.`token_id` = `research_data`.`token_id`
+        ORDER BY a.`position`
+    ) e
+), '');
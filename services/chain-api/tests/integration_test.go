@@ -4,14 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"desci-backend/internal/api"
 	"desci-backend/internal/model"
 	"desci-backend/internal/repository"
 	"desci-backend/internal/service"
+	"desci-backend/internal/verify"
+	"desci-backend/internal/zkp"
+	"github.com/ethereum/go-ethereum/common"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,30 +29,36 @@ import (
 )
 
 // setupTestAPI 创建测试API环境
+// testAdminToken 测试环境的管理接口令牌
+const testAdminToken = "test-admin-token"
+
 func setupTestAPI(t *testing.T) (*gin.Engine, repository.IRepository) {
+	router, repo, _ := setupTestAPIWithService(t)
+	return router, repo
+}
+
+// setupTestAPIWithService 创建测试API环境，并返回路由使用的Service以便注入依赖
+func setupTestAPIWithService(t *testing.T) (*gin.Engine, repository.IRepository, *service.Service) {
 	// 创建测试数据库
 	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
-	// 自动迁移所有模型
-	err = gormDB.AutoMigrate(
-		&model.ResearchData{},
-		&model.DatasetRecord{},
-		&model.EventLog{},
-	)
+	// 执行版本化迁移
+	err = repository.Migrate(gormDB)
 	require.NoError(t, err)
 
 	repo := repository.NewTestRepository(gormDB)
 	svc := service.NewService(repo)
 
 	// 创建API handler
-	handler := api.NewHandler(svc)
+	handler := api.NewHandler(svc, repo)
+	handler.SetAdminToken(testAdminToken)
 
 	// 设置gin为测试模式
 	gin.SetMode(gin.TestMode)
 	router := handler.SetupRoutes()
 
-	return router, repo
+	return router, repo, svc
 }
 
 func TestHealthCheck(t *testing.T) {
@@ -60,6 +75,7 @@ func TestHealthCheck(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "ok", response["status"])
 	assert.Equal(t, "desci-backend", response["service"])
+	assert.Equal(t, "disabled", response["indexer_phase"])
 }
 
 func TestGetResearch_Success(t *testing.T) {
@@ -437,3 +453,1304 @@ func TestCompleteWorkflow(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, unprocessedEvents, 0)
 }
+
+func TestPendingResearch_ConfirmationFlow(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+	svc.SetConfirmations(6)
+
+	// 待确认的铸造事件
+	err := repo.InsertEventLog(&model.EventLog{
+		TxHash:      "0xpending",
+		BlockNumber: 100,
+		EventName:   "ResearchCreated",
+		EntityID:    "pending-1",
+		PayloadRaw:  `{"tokenId":"pending-1","title":"Pending Research","authors":["0xabc"]}`,
+		Status:      model.EventStatusPending,
+	})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/research/pending-1", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var research model.ResearchData
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &research))
+	assert.Equal(t, model.EventStatusPending, research.Status)
+	assert.Equal(t, "Pending Research", research.Title)
+
+	// 确认数不足时不物化
+	n, err := svc.PromoteConfirmedEvents(105)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = svc.PromoteConfirmedEvents(106)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := repo.GetResearchData("pending-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.EventStatusConfirmed, stored.Status)
+	assert.Equal(t, uint64(100), stored.BlockNumber)
+
+	pending, err := repo.ListPendingEvents(repository.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, pending.Items)
+}
+
+// 以非零确认深度运行时遗留的 pending 事件，在深度改为 0 后按当前链头全部提升
+func TestPendingResearch_PromotedWhenDepthDropsToZero(t *testing.T) {
+	_, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+	svc.SetConfirmations(0)
+
+	err := repo.InsertEventLog(&model.EventLog{
+		TxHash:      "0xleftover",
+		BlockNumber: 100,
+		EventName:   "ResearchCreated",
+		EntityID:    "leftover-1",
+		PayloadRaw:  `{"tokenId":"leftover-1","title":"Leftover Research","authors":["0xabc"]}`,
+		Status:      model.EventStatusPending,
+	})
+	require.NoError(t, err)
+
+	n, err := svc.PromoteConfirmedEvents(100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := repo.GetResearchData("leftover-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.EventStatusConfirmed, stored.Status)
+
+	pending, err := repo.ListPendingEvents(repository.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, pending.Items)
+}
+
+func TestResearchCreated_FullPayload(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	// contentHash 来自合约 researches(tokenId)，而不是交易哈希
+	eventLog := &model.EventLog{
+		TxHash:      "0xmint",
+		BlockNumber: 12,
+		EventName:   "ResearchCreated",
+		EntityID:    "77",
+		PayloadRaw: `{"tokenId":"77","title":"Full Payload","authors":["0xaaa","0xbbb"],` +
+			`"contentHash":"0x19eb617fadfd0bd2627cbb32b5a95a96e076f58b6fd7592d5cdd92e63f6c18a9",` +
+			`"metadataHash":"QmMeta","pubType":6,"timestamp":"1700000000","blockTimestamp":1700000012}`,
+		Status: model.EventStatusConfirmed,
+	}
+	require.NoError(t, repo.InsertEventLog(eventLog))
+	require.NoError(t, svc.ProcessEvent(eventLog))
+
+	stored, err := repo.GetResearchData("77")
+	require.NoError(t, err)
+	assert.Equal(t, model.StringArray{"0xaaa", "0xbbb"}, stored.Authors)
+	assert.Equal(t, "QmMeta", stored.MetadataHash)
+	assert.Equal(t, uint8(6), stored.PubType)
+	assert.Equal(t, "preprint", stored.PubTypeName)
+	require.NotNil(t, stored.PublishedAt)
+	assert.Equal(t, int64(1700000000), stored.PublishedAt.Unix())
+	require.NotNil(t, stored.BlockTime)
+	assert.Equal(t, int64(1700000012), stored.BlockTime.Unix())
+
+	jsonBody, _ := json.Marshal(map[string]string{"rawContent": "test data for hashing"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/research/77/verify", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["match"])
+}
+
+func TestUserProfile_LifecycleEvents(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+	addr := "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+
+	// 按链上顺序处理 DeSciRegistry 用户事件
+	events := []struct {
+		name    string
+		payload string
+	}{
+		{"UserRegistered", `{"user":"0x70997970C51812dc3A010C7d01b50e0d17dc79C8","name":"Alice","role":1,"timestamp":"1700000000"}`},
+		{"VerificationRequested", `{"requestId":"3","applicant":"0x70997970C51812dc3A010C7d01b50e0d17dc79C8","requestedRole":2,"timestamp":"1700000001"}`},
+		{"UserVerified", `{"user":"0x70997970C51812dc3A010C7d01b50e0d17dc79C8","role":2,"verifier":"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC","timestamp":"1700000100"}`},
+		{"RoleChanged", `{"user":"0x70997970C51812dc3A010C7d01b50e0d17dc79C8","oldRole":1,"newRole":2,"timestamp":"1700000100"}`},
+		{"ReputationUpdated", `{"user":"0x70997970C51812dc3A010C7d01b50e0d17dc79C8","oldReputation":"0","newReputation":"120","reason":"peer review"}`},
+	}
+	for i, e := range events {
+		eventLog := &model.EventLog{
+			TxHash:      "0xuser",
+			LogIndex:    uint(i),
+			BlockNumber: 200 + uint64(i),
+			EventName:   e.name,
+			EntityID:    addr,
+			PayloadRaw:  e.payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(e.name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/wallet/0x70997970C51812dc3A010C7d01b50e0d17dc79C8", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, addr, response["wallet_address"])
+	assert.Equal(t, "Alice", response["username"])
+	assert.Equal(t, "reviewer", response["user_role"])
+	assert.Equal(t, true, response["verified"])
+	assert.Equal(t, "3", response["verification_request_id"])
+	assert.Equal(t, "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", response["verifier"])
+	assert.Equal(t, "120", response["reputation"])
+
+	history := response["reputation_history"].([]interface{})
+	require.Len(t, history, 1)
+	assert.Equal(t, "peer review", history[0].(map[string]interface{})["reason"])
+}
+
+func TestUserProfile_NotFound(t *testing.T) {
+	router, _ := setupTestAPI(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/wallet/0x0000000000000000000000000000000000000001", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatasetActivity_Endpoints(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	events := []struct {
+		name    string
+		payload string
+	}{
+		{"DatasetAccessed", `{"datasetId":"7","user":"0xAbC0000000000000000000000000000000000001","pricePaid":"1000000000000000000","timestamp":"1700000000"}`},
+		{"DatasetCited", `{"datasetId":"7","researcher":"0xAbC0000000000000000000000000000000000002","publicationHash":"QmPaper","timestamp":"1700000050"}`},
+		{"QualityUpdated", `{"datasetId":"7","oldLevel":0,"newLevel":2,"verifier":"0xAbC0000000000000000000000000000000000003"}`},
+		{"QualityUpdated", `{"datasetId":"7","oldLevel":2,"newLevel":4,"verifier":"0xAbC0000000000000000000000000000000000004"}`},
+		{"DatasetRevenueDistributed", `{"datasetId":"7","owner":"0xAbC0000000000000000000000000000000000005","ownerShare":"18000000000000000000","platformShare":"2000000000000000000"}`},
+		{"DatasetRevenueDistributed", `{"datasetId":"7","owner":"0xAbC0000000000000000000000000000000000005","ownerShare":"9000000000000000000","platformShare":"1000000000000000000"}`},
+	}
+	for i, e := range events {
+		eventLog := &model.EventLog{
+			TxHash:      "0xdataset",
+			LogIndex:    uint(i),
+			BlockNumber: 300 + uint64(i),
+			EventName:   e.name,
+			EntityID:    "7",
+			PayloadRaw:  e.payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+		// 重复处理不产生重复记录
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	accesses := get("/api/datasets/7/accesses")
+	assert.Equal(t, float64(1), accesses["total_estimate"])
+	access := accesses["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "0xabc0000000000000000000000000000000000001", access["user"])
+	assert.Equal(t, "1000000000000000000", access["price_paid"])
+
+	citations := get("/api/datasets/7/citations")
+	assert.Equal(t, float64(1), citations["total_estimate"])
+	assert.Equal(t, "QmPaper", citations["items"].([]interface{})[0].(map[string]interface{})["publication_hash"])
+
+	quality := get("/api/datasets/7/quality")
+	assert.Equal(t, float64(4), quality["level"])
+	assert.Equal(t, "gold", quality["level_name"])
+	assert.Equal(t, "0xAbC0000000000000000000000000000000000004", quality["verifier"])
+	assert.Equal(t, float64(2), quality["total_estimate"])
+	assert.Len(t, quality["items"], 2)
+
+	// 翻页时当前等级仍取最新一条
+	firstQuality := get("/api/datasets/7/quality?limit=1&order=asc")
+	require.NotEmpty(t, firstQuality["next_cursor"])
+	assert.Equal(t, "gold", firstQuality["level_name"])
+	assert.Len(t, get("/api/datasets/7/quality?limit=1&cursor=" + firstQuality["next_cursor"].(string))["items"], 1)
+
+	revenue := get("/api/datasets/7/revenue")
+	assert.Equal(t, "27000000000000000000", revenue["total_owner_share"])
+	assert.Equal(t, "3000000000000000000", revenue["total_platform_share"])
+	assert.Equal(t, "30000000000000000000", revenue["total_revenue"])
+	assert.Equal(t, float64(2), revenue["distributions"])
+
+	// 无质量事件的数据集视为未验证
+	empty := get("/api/datasets/8/quality")
+	assert.Equal(t, "unverified", empty["level_name"])
+}
+
+func TestResearchActivity_Endpoints(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", Title: "Cited Paper", BlockNumber: 400}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "2", Title: "Citing Paper", BlockNumber: 401}))
+
+	events := []struct {
+		name    string
+		payload string
+	}{
+		{"CitationAdded", `{"fromTokenId":"2","toTokenId":"1","citer":"0xAbC0000000000000000000000000000000000001"}`},
+		{"ReviewSubmitted", `{"tokenId":"1","reviewer":"0xAbC0000000000000000000000000000000000002","score":8,"isAnonymous":false}`},
+		{"ReviewSubmitted", `{"tokenId":"1","reviewer":"0xAbC0000000000000000000000000000000000003","score":6,"isAnonymous":true}`},
+		{"ImpactLevelUpdated", `{"tokenId":"1","oldLevel":0,"newLevel":2}`},
+		{"ResearchRevenueDistributed", `{"tokenId":"1","authors":["0xAbC0000000000000000000000000000000000004"],"shares":["25000000000000000000"],"totalAmount":"25000000000000000000"}`},
+		{"MetadataUpdate", `{"_tokenId":"1"}`},
+	}
+	for i, e := range events {
+		eventLog := &model.EventLog{
+			TxHash:      "0xresearch",
+			LogIndex:    uint(i),
+			BlockNumber: 410 + uint64(i),
+			EventName:   e.name,
+			PayloadRaw:  e.payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(e.name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	citations := get("/api/research/1/citations")
+	assert.Equal(t, "cited_by", citations["direction"])
+	assert.Equal(t, float64(1), citations["total_estimate"])
+	assert.Equal(t, float64(0), get("/api/research/1/citations?direction=references")["total_estimate"])
+	assert.Len(t, get("/api/research/2/citations?direction=references")["items"], 1)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/research/1/citations?direction=sideways", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	reviews := get("/api/research/1/reviews")
+	require.Equal(t, float64(2), reviews["total_estimate"])
+	anonymous := reviews["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, true, anonymous["is_anonymous"])
+	assert.NotContains(t, anonymous, "reviewer")
+
+	impact := get("/api/research/1/impact")
+	assert.Equal(t, "high", impact["level_name"])
+	assert.Equal(t, float64(1), impact["total_estimate"])
+
+	revenue := get("/api/research/1/revenue")
+	assert.Equal(t, "25000000000000000000", revenue["total_revenue"])
+	assert.Equal(t, float64(1), revenue["total_estimate"])
+
+	// 统计字段随事件更新
+	research := get("/api/research/1")
+	assert.Equal(t, float64(1), research["citation_count"])
+	assert.Equal(t, float64(2), research["review_count"])
+	assert.Equal(t, float64(7), research["average_score"])
+	assert.Equal(t, "high", research["impact_level_name"])
+	assert.Equal(t, "25000000000000000000", research["total_revenue"])
+}
+
+func TestNFTOwnership_TransfersAndProvenance(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	bob := "0xAbC0000000000000000000000000000000000002"
+	process := func(i int, name, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:      "0xnft",
+			LogIndex:    uint(i),
+			BlockNumber: 500 + uint64(i),
+			EventName:   name,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	// 铸造 Transfer 先于 ResearchMinted
+	process(0, "ResearchTransfer", `{"from":"`+model.ZeroAddress+`","to":"`+alice+`","tokenId":"1"}`)
+	process(1, "ResearchCreated", `{"tokenId":"1","title":"Owned Paper","authors":["`+alice+`"]}`)
+	process(2, "ResearchTransfer", `{"from":"`+alice+`","to":"`+bob+`","tokenId":"1"}`)
+	process(3, "DatasetTransfer", `{"from":"`+model.ZeroAddress+`","to":"`+bob+`","tokenId":"9"}`)
+	process(4, "DatasetTransfer", `{"from":"`+bob+`","to":"`+model.ZeroAddress+`","tokenId":"9"}`)
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	ownership := get("/api/research/1/ownership")
+	assert.Equal(t, "0xabc0000000000000000000000000000000000002", ownership["owner"])
+	assert.Equal(t, false, ownership["burned"])
+	history := ownership["history"].([]interface{})
+	require.Len(t, history, 2)
+	assert.Equal(t, model.ZeroAddress, history[0].(map[string]interface{})["from"])
+
+	research := get("/api/research/1")
+	assert.Equal(t, "0xabc0000000000000000000000000000000000002", research["owner"])
+
+	dataset := get("/api/datasets/9/ownership")
+	assert.Equal(t, true, dataset["burned"])
+
+	// 已销毁的 token 不计入持有列表
+	tokens := get("/api/users/" + bob + "/tokens")
+	assert.Equal(t, float64(1), tokens["total_estimate"])
+	assert.Equal(t, float64(0), get("/api/users/" + alice + "/tokens?collection=research")["total_estimate"])
+
+	// 钱包用户路由不受影响
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/wallet/"+alice, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/research/404/ownership", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProofs_Lifecycle(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	verifier := "0xAbC0000000000000000000000000000000000009"
+	process := func(i int, name, entityID, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:       "0xproof",
+			LogIndex:     uint(i),
+			BlockNumber:  600 + uint64(i),
+			ContractAddr: "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0",
+			EventName:    name,
+			EntityID:     entityID,
+			PayloadRaw:   payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	process(0, "ProofTypeAdded", "data_integrity", `{"proofType":"data_integrity","verifierContract":"`+verifier+`"}`)
+	process(1, "ProofTypeRegistered", "data_integrity", `{"proofType":"data_integrity","registrant":"`+verifier+`"}`)
+	process(2, "ProofSubmitted", "0", `{"proofId":"0","submitter":"`+alice+`","proofType":"data_integrity","metadataHash":"QmProof0","blockTimestamp":1700000000}`)
+	process(3, "ProofSubmitted", "1", `{"proofId":"1","submitter":"`+alice+`","proofType":"data_integrity","metadataHash":"QmProof1","blockTimestamp":1700000010}`)
+	process(4, "ProofVerified", "0", `{"proofId":"0","isValid":true,"verifier":"`+verifier+`","blockTimestamp":1700000020}`)
+	process(5, "ProofVerified", "1", `{"proofId":"1","isValid":false,"verifier":"`+verifier+`","blockTimestamp":1700000030}`)
+	process(6, "ProofHashVerified", "0xfeed", `{"proofHash":"0xfeed","isValid":true,"timestamp":"1700000040"}`)
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	assert.Equal(t, float64(2), get("/api/proofs")["total_estimate"])
+	verified := get("/api/proofs?status=verified")
+	require.Equal(t, float64(1), verified["total_estimate"])
+	assert.Equal(t, "0", verified["items"].([]interface{})[0].(map[string]interface{})["proof_id"])
+	assert.Equal(t, float64(1), get("/api/proofs?status=rejected")["total_estimate"])
+
+	detail := get("/api/proofs/0")
+	proof := detail["proof"].(map[string]interface{})
+	assert.Equal(t, model.ProofStatusVerified, proof["status"])
+	assert.Equal(t, "QmProof0", proof["metadata_hash"])
+	assert.Equal(t, "0xabc0000000000000000000000000000000000009", proof["verifier"])
+	assert.NotEmpty(t, proof["submitted_at"])
+	assert.NotEmpty(t, proof["verified_at"])
+	assert.Len(t, detail["history"], 1)
+
+	userProofs := get("/api/users/" + alice + "/proofs")
+	assert.Equal(t, float64(2), userProofs["total_estimate"])
+
+	types := get("/api/proofs/types")
+	require.Equal(t, float64(1), types["total_estimate"])
+	proofType := types["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, true, proofType["is_active"])
+	assert.Equal(t, true, proofType["registered"])
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/proofs/404", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/proofs?status=unknown", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// fakeProofChain 固定的证明合约数据源
+type fakeProofChain struct {
+	proofs  map[string]*zkp.SubmittedProof
+	vk      *verify.VerifyingKey
+	onchain map[common.Hash]bool
+}
+
+func (f *fakeProofChain) GetSubmittedProof(ctx context.Context, proofID string) (*zkp.SubmittedProof, error) {
+	return f.proofs[proofID], nil
+}
+
+func (f *fakeProofChain) GetVerifyingKey(ctx context.Context, proofType string) (*verify.VerifyingKey, error) {
+	return f.vk, nil
+}
+
+func (f *fakeProofChain) GetVerificationResult(ctx context.Context, proofHash common.Hash) (bool, uint64, error) {
+	valid, ok := f.onchain[proofHash]
+	if !ok {
+		return false, 0, nil
+	}
+	return valid, 1700000000, nil
+}
+
+func g1Point(k int64) verify.G1Point {
+	b := new(bn256.G1).ScalarBaseMult(big.NewInt(k)).Marshal()
+	return verify.G1Point{new(big.Int).SetBytes(b[:32]), new(big.Int).SetBytes(b[32:])}
+}
+
+func g2Point(k int64) verify.G2Point {
+	b := new(bn256.G2).ScalarBaseMult(big.NewInt(k)).Marshal()
+	w := func(i int) *big.Int { return new(big.Int).SetBytes(b[i*32 : (i+1)*32]) }
+	return verify.G2Point{{w(1), w(0)}, {w(3), w(2)}}
+}
+
+func TestProofs_OffchainCheck(t *testing.T) {
+	router, repo, svc := setupTestAPIWithService(t)
+
+	// alpha=2, beta=3, gamma=5, delta=7, IC=[1,1,1]；输入 (4, 6) 时 A=68、B=1、C=1 满足配对等式
+	vk := &verify.VerifyingKey{
+		Alpha1: g1Point(2), Beta2: g2Point(3), Gamma2: g2Point(5), Delta2: g2Point(7),
+		IC: []verify.G1Point{g1Point(1), g1Point(1), g1Point(1)},
+	}
+	proof := verify.Groth16Proof{A: g1Point(68), B: g2Point(1), C: g1Point(1)}
+	submitter := "0xAbC0000000000000000000000000000000000001"
+	valid := &zkp.SubmittedProof{Submitter: submitter, ProofType: "data_integrity", Proof: proof, PublicInputs: []*big.Int{big.NewInt(4), big.NewInt(6)}}
+	tampered := &zkp.SubmittedProof{Submitter: submitter, ProofType: "data_integrity", Proof: proof, PublicInputs: []*big.Int{big.NewInt(5), big.NewInt(6)}}
+	pending := &zkp.SubmittedProof{Submitter: submitter, ProofType: "data_integrity", Proof: proof, PublicInputs: []*big.Int{big.NewInt(6), big.NewInt(4)}}
+
+	chain := &fakeProofChain{
+		proofs: map[string]*zkp.SubmittedProof{"0": valid, "1": tampered, "2": pending},
+		vk:     vk,
+		onchain: map[common.Hash]bool{
+			verify.Groth16ProofHash("data_integrity", proof, valid.PublicInputs):    true,
+			verify.Groth16ProofHash("data_integrity", proof, tampered.PublicInputs): false,
+		},
+	}
+
+	check := func(id string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/proofs/"+id+"/check", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	// 自动核对只处理由合约 ProofSubmitted 事件索引的证明
+	submit := func(id string) {
+		require.NoError(t, repo.InsertEventLog(&model.EventLog{TxHash: "0xsubmit" + id, BlockNumber: 800, EventName: "ProofSubmitted", EntityID: id,
+			ContractAddr: "0x9fe46736679d2d9a65f0992f2272de9f3c7fa6e0", Status: model.EventStatusConfirmed}))
+		require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: id, Submitter: submitter, ProofType: "data_integrity", Status: model.ProofStatusSubmitted, BlockNumber: 800, TxHash: "0xsubmit" + id}))
+	}
+	for _, id := range []string{"0", "1", "2"} {
+		submit(id)
+	}
+	require.NoError(t, repo.InsertProof(&model.ZKProof{ProofID: "9", ProofType: "data_integrity", Status: model.ProofStatusSubmitted}))
+
+	// 未配置合约时无法校验
+	code, _ := check("0")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	svc.SetProofChain(chain)
+
+	code, checked := check("0")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, model.ProofCheckValid, checked["check_status"])
+	assert.Equal(t, model.ProofCheckValid, checked["onchain_result"])
+	assert.Equal(t, true, checked["trusted"])
+	assert.NotEmpty(t, checked["proof_hash"])
+
+	code, checked = check("1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, model.ProofCheckInvalid, checked["check_status"])
+	assert.Contains(t, checked["check_reason"], "pairing check failed")
+	assert.Equal(t, false, checked["trusted"])
+
+	// 链下通过但链上尚未记录，不标记为可信，等待下一轮核对
+	code, checked = check("2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, model.ProofCheckValid, checked["check_status"])
+	assert.Equal(t, model.ProofCheckUnknown, checked["onchain_result"])
+	assert.Equal(t, false, checked["trusted"])
+
+	// 从未校验过的新证明排在等待链上记录的证明之前
+	chain.proofs["3"] = &zkp.SubmittedProof{Submitter: submitter, ProofType: "data_integrity", Proof: proof, PublicInputs: []*big.Int{big.NewInt(7), big.NewInt(8)}}
+	submit("3")
+	toCheck, err := repo.ListProofsToCheck(time.Now(), 20, 10)
+	require.NoError(t, err)
+	require.Len(t, toCheck, 2)
+	assert.Equal(t, "3", toCheck[0].ProofID)
+	assert.Equal(t, "2", toCheck[1].ProofID)
+
+	// 链上仍无记录的证明推迟到退避时间之后再核对
+	svc.SetReplayBackoff(time.Minute, time.Hour)
+	n, err := svc.CheckPendingProofs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	toCheck, err = repo.ListProofsToCheck(time.Now(), 20, 10)
+	require.NoError(t, err)
+	assert.Empty(t, toCheck)
+	toCheck, err = repo.ListProofsToCheck(time.Now().Add(2*time.Minute), 20, 10)
+	require.NoError(t, err)
+	require.Len(t, toCheck, 2)
+	assert.Equal(t, 1, toCheck[0].CheckAttempts)
+
+	// 达到重试上限后不再自动核对
+	require.NoError(t, repo.UpdateProof("2", map[string]interface{}{"check_attempts": 20}))
+	toCheck, err = repo.ListProofsToCheck(time.Now().Add(2*time.Minute), 20, 10)
+	require.NoError(t, err)
+	require.Len(t, toCheck, 1)
+	assert.Equal(t, "3", toCheck[0].ProofID)
+
+	code, _ = check("404")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// 链上不存在的证明拒绝核对
+	code, _ = check("9")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// 记录的提交者和类型与链上不一致时以链上为准
+	require.NoError(t, repo.UpdateProof("0", map[string]interface{}{"submitter": "0xf00", "proof_type": "fake"}))
+	code, checked = check("0")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, strings.ToLower(submitter), checked["submitter"])
+	assert.Equal(t, "data_integrity", checked["proof_type"])
+
+	// 校验接口需要管理令牌
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/proofs/0/check", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestConstraints_Endpoints(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	process := func(i int, txHash, name, entityID, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:      txHash,
+			LogIndex:    uint(i),
+			BlockNumber: 700 + uint64(i),
+			EventName:   name,
+			EntityID:    entityID,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		if svc.HandlesEvent(name) {
+			require.NoError(t, svc.ProcessEvent(eventLog))
+		}
+	}
+
+	process(0, "0xc0", "ConstraintCreated", "0xc1", `{"constraintId":"0xc1","name":"row count","category":2,"creator":"`+alice+`","operator":6,"thresholds":["10","20"],"weight":"1","isActive":true}`)
+	process(1, "0xc0", "ConstraintCreated", "0xc2", `{"constraintId":"0xc2","name":"csv format","category":1,"creator":"`+alice+`","operator":0,"thresholds":["1"],"isActive":true}`)
+	process(2, "0xc0", "ConstraintUpdated", "0xc2", `{"constraintId":"0xc2","operator":0,"thresholds":["2"],"isActive":true}`)
+	process(3, "0xc0", "ConstraintGroupCreated", "0xg1", `{"groupId":"0xg1","name":"basic","creator":"`+alice+`","constraintIds":["0xc1","0xc2"],"minSatisfaction":"50","isActive":true}`)
+	process(4, "0xc0", "ValidationRuleCreated", "0xr1", `{"ruleId":"0xr1","name":"publishable","creator":"`+alice+`","groupIds":["0xg1"],"minScore":"50","isActive":true}`)
+
+	// 评估与数据集事件在同一交易中，按交易关联到数据集
+	process(5, "0xd1", "QualityUpdated", "7", `{"datasetId":"7","newScore":"80"}`)
+	process(6, "0xd1", "ConstraintEvaluated", "0xc1", `{"constraintId":"0xc1","result":true,"score":"100","blockTimestamp":1700000000}`)
+	process(7, "0xd1", "ConstraintEvaluated", "0xc2", `{"constraintId":"0xc2","result":false,"score":"0","blockTimestamp":1700000000}`)
+	process(8, "0xe1", "ConstraintEvaluated", "0xc2", `{"constraintId":"0xc2","result":true,"score":"100","blockTimestamp":1700000010}`)
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	assert.Equal(t, float64(2), get("/api/constraints")["total_estimate"])
+	ranged := get("/api/constraints?category=range")
+	require.Equal(t, float64(1), ranged["total_estimate"])
+	assert.Equal(t, "between", ranged["items"].([]interface{})[0].(map[string]interface{})["operator_name"])
+
+	detail := get("/api/constraints/0xc2")
+	constraint := detail["constraint"].(map[string]interface{})
+	assert.Equal(t, "csv format", constraint["name"])
+	assert.Equal(t, []interface{}{"2"}, constraint["thresholds"])
+	assert.Len(t, detail["evaluations"], 2)
+
+	assert.Equal(t, float64(1), get("/api/constraints/groups")["total_estimate"])
+	assert.Equal(t, float64(1), get("/api/constraints/rules")["total_estimate"])
+
+	dataset := get("/api/datasets/7/constraints")
+	assert.Equal(t, float64(2), dataset["total_estimate"])
+	// 规则得分按全部评估计算，不受分页影响
+	assert.Equal(t, dataset["rules"], get("/api/datasets/7/constraints?limit=1")["rules"])
+	rules := dataset["rules"].([]interface{})
+	require.Len(t, rules, 1)
+	rule := rules[0].(map[string]interface{})
+	assert.Equal(t, float64(50), rule["score"])
+	assert.Equal(t, true, rule["passed"])
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/constraints/0x404", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatasetQuality_Features(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	process := func(i int, txHash, name, entityID, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:      txHash,
+			LogIndex:    uint(i),
+			BlockNumber: 800 + uint64(i),
+			EventName:   name,
+			EntityID:    entityID,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	// 数据集载荷与监听器解码 DatasetUploaded 的结果一致：ipfsHash 来自 getDataset（见 listener 包的端到端测试）
+	process(10, "0xu9", "DatasetCreated", "9", `{"datasetId":"9","owner":"`+alice+`","title":"Cohort","description":"genome cohort","ipfsHash":"QmData9"}`)
+
+	// 验证器记录通过数据哈希关联
+	process(0, "0xv0", "ConstraintAdded", "min_score", `{"constraintType":"min_score","threshold":"60","description":"minimum score"}`)
+	process(1, "0xv1", "DataSubmitted", "0xd9", `{"dataId":"0xd9","submitter":"`+alice+`","dataType":"genomic","dataHash":"QmData9","blockTimestamp":1700000000}`)
+	process(2, "0xv2", "FeaturesExtracted", "0xd9", `{"dataId":"0xd9","featureHash":"0xfeed","mean":"50","standardDeviation":"5","minValue":"10","maxValue":"90","dataCount":"100"}`)
+	process(3, "0xv3", "DataVerified", "0xd9", `{"dataId":"0xd9","isValid":true,"score":"100","status":"zkp_verified"}`)
+
+	// 特征提取器记录通过同一交易中的数据集事件关联
+	process(4, "0xf1", "QualityUpdated", "9", `{"datasetId":"9","oldLevel":0,"newLevel":2,"verifier":"`+alice+`"}`)
+	process(5, "0xf1", "FeaturesCalculated", "0xf9", `{"featureId":"0xf9","calculator":"`+alice+`","dataType":1,"dataCount":"40","featureHash":"QmFeatures"}`)
+	process(6, "0xf2", "StatisticalMetricsUpdated", "0xf9", `{"featureId":"0xf9","mean":"20","standardDeviation":"2","minValue":"1","maxValue":"30"}`)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/datasets/9/quality", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var quality map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &quality))
+
+	assert.Equal(t, "standard", quality["level_name"])
+	features := quality["features"].([]interface{})
+	require.Len(t, features, 2)
+	bySource := map[string]map[string]interface{}{}
+	for _, f := range features {
+		feature := f.(map[string]interface{})
+		bySource[feature["source"].(string)] = feature
+	}
+
+	verifier := bySource[model.DataQualitySourceVerifier]
+	require.NotNil(t, verifier)
+	assert.Equal(t, "0xd9", verifier["subject_id"])
+	assert.Equal(t, "genomic", verifier["data_type"])
+	assert.Equal(t, "50", verifier["mean"])
+	assert.Equal(t, "90", verifier["max_value"])
+	assert.Equal(t, "100", verifier["score"])
+	assert.Equal(t, true, verifier["is_valid"])
+	assert.Equal(t, "zkp_verified", verifier["verification_status"])
+	assert.Len(t, verifier["history"], 3)
+
+	extractor := bySource[model.DataQualitySourceExtractor]
+	require.NotNil(t, extractor)
+	assert.Equal(t, "statistical", extractor["data_type"])
+	assert.Equal(t, "40", extractor["data_count"])
+	assert.Equal(t, "20", extractor["mean"])
+	assert.Equal(t, "2", extractor["standard_deviation"])
+	assert.Equal(t, "0xabc0000000000000000000000000000000000001", extractor["submitter"])
+
+	thresholds := quality["thresholds"].([]interface{})
+	require.Len(t, thresholds, 1)
+	assert.Equal(t, "60", thresholds[0].(map[string]interface{})["threshold"])
+}
+
+func TestInfluenceAndRewards_Endpoints(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	bob := "0xAbC0000000000000000000000000000000000002"
+	process := func(i int, name, entityID, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:      "0xinfluence",
+			LogIndex:    uint(i),
+			BlockNumber: 900 + uint64(i),
+			EventName:   name,
+			EntityID:    entityID,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	process(0, "WeightsUpdated", "", `{"publicationWeight":"30","reviewWeight":"20","dataWeight":"20","collaborationWeight":"15","governanceWeight":"15","blockTimestamp":1700000000}`)
+	process(1, "WeightsUpdated", "", `{"publicationWeight":"40","reviewWeight":"20","dataWeight":"20","collaborationWeight":"10","governanceWeight":"10","blockTimestamp":1700000010}`)
+	process(2, "InfluenceUpdated", strings.ToLower(alice), `{"user":"`+alice+`","oldInfluence":"0","newInfluence":"100","newRank":"0","publicationScore":"80","blockTimestamp":1700000020}`)
+	process(3, "InfluenceUpdated", strings.ToLower(bob), `{"user":"`+bob+`","oldInfluence":"0","newInfluence":"300","newRank":"0","blockTimestamp":1700000030}`)
+	process(4, "RankingUpdated", "global", `{"rankingType":0,"identifier":"global","timestamp":"1700000040"}`)
+	process(5, "InfluenceUpdated", strings.ToLower(alice), `{"user":"`+alice+`","oldInfluence":"100","newInfluence":"200","newRank":"2","blockTimestamp":1700000050}`)
+	process(6, "RewardDistributed", strings.ToLower(alice), `{"user":"`+alice+`","amount":"10000000000000000000","reason":"Research publication","blockTimestamp":1700000060}`)
+	process(7, "RewardDistributed", strings.ToLower(alice), `{"user":"`+alice+`","amount":"5000000000000000000","reason":"Peer review","blockTimestamp":1700000070}`)
+	process(8, "RewardDistributed", strings.ToLower(bob), `{"user":"`+bob+`","amount":"1000000000000000000","reason":"Dataset upload","blockTimestamp":1700000080}`)
+	process(9, "CollaborationFormed", "42", `{"user1":"`+alice+`","user2":"`+bob+`","researchId":"42","blockTimestamp":1700000090}`)
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	leaderboard := get("/api/influence/leaderboard")
+	require.Equal(t, float64(2), leaderboard["total_estimate"])
+	leaders := leaderboard["items"].([]interface{})
+	assert.Equal(t, strings.ToLower(bob), leaders[0].(map[string]interface{})["user"])
+	assert.Equal(t, "200", leaders[1].(map[string]interface{})["new_influence"])
+
+	influence := get("/api/influence/users/" + alice)
+	assert.Equal(t, float64(2), influence["total_estimate"])
+	current := influence["current"].(map[string]interface{})
+	assert.Equal(t, "2", current["new_rank"])
+	assert.Equal(t, "0", current["old_rank"])
+
+	weights := get("/api/influence/weights")
+	assert.Equal(t, float64(2), weights["total_estimate"])
+	assert.Equal(t, "40", weights["current"].(map[string]interface{})["publication_weight"])
+
+	rankings := get("/api/influence/rankings")
+	require.Equal(t, float64(1), rankings["total_estimate"])
+	assert.Equal(t, "global", rankings["items"].([]interface{})[0].(map[string]interface{})["ranking_type_name"])
+
+	collaborations := get("/api/influence/collaborations?address=" + bob)
+	require.Equal(t, float64(1), collaborations["total_estimate"])
+	assert.Equal(t, "42", collaborations["items"].([]interface{})[0].(map[string]interface{})["research_id"])
+
+	assert.Equal(t, float64(3), get("/api/rewards")["total_estimate"])
+	rewards := get("/api/rewards/" + alice)
+	assert.Equal(t, "15000000000000000000", rewards["total_rewards"])
+	assert.Equal(t, float64(2), rewards["distributions"])
+}
+
+func TestTokenLedger_Endpoints(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xAbC0000000000000000000000000000000000001"
+	bob := "0xAbC0000000000000000000000000000000000002"
+	process := func(i int, name, payload string) {
+		eventLog := &model.EventLog{
+			TxHash:      "0xtoken",
+			LogIndex:    uint(i),
+			BlockNumber: 1000 + uint64(i),
+			EventName:   name,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.True(t, svc.HandlesEvent(name))
+		require.NoError(t, svc.ProcessEvent(eventLog))
+	}
+
+	process(0, "TokenTransfer", `{"from":"`+model.ZeroAddress+`","to":"`+alice+`","value":"5000000000000000000000","blockTimestamp":1700000000}`)
+	process(1, "TokenTransfer", `{"from":"`+alice+`","to":"`+bob+`","value":"1500000000000000000000","blockTimestamp":1700000010}`)
+	process(2, "TokenApproval", `{"owner":"`+alice+`","spender":"`+bob+`","value":"100","blockTimestamp":1700000020}`)
+	process(3, "TokenApproval", `{"owner":"`+alice+`","spender":"`+bob+`","value":"0","blockTimestamp":1700000030}`)
+
+	get := func(path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	holders := get("/api/token/holders")
+	require.Equal(t, float64(2), holders["total_estimate"])
+	assert.Equal(t, strings.ToLower(alice), holders["items"].([]interface{})[0].(map[string]interface{})["holder"])
+	assert.Equal(t, "5000000000000000000000", holders["supply"].(map[string]interface{})["total_supply"])
+
+	balance := get("/api/token/balances/" + bob)
+	assert.Equal(t, "1500000000000000000000", balance["balance"])
+	account := get("/api/token/balances/" + alice)
+	assert.Equal(t, "3500000000000000000000", account["balance"])
+	allowances := account["allowances"].([]interface{})
+	require.Len(t, allowances, 1)
+	assert.Equal(t, "0", allowances[0].(map[string]interface{})["value"])
+
+	assert.Equal(t, "0", get("/api/token/balances/0x0000000000000000000000000000000000000009")["balance"])
+	assert.Equal(t, float64(2), get("/api/token/transfers")["total_estimate"])
+	assert.Equal(t, float64(1), get("/api/token/transfers?address=" + bob)["total_estimate"])
+}
+
+func TestService_SubscribeCustomEvent(t *testing.T) {
+	_, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	eventLog := &model.EventLog{TxHash: "0xcustom", EventName: "GrantAwarded", PayloadRaw: `{"amount":"10"}`}
+	assert.False(t, svc.HandlesEvent("GrantAwarded"))
+	require.NoError(t, svc.ProcessEvent(eventLog))
+
+	// 同一事件的多个订阅者按订阅顺序执行
+	var calls []string
+	svc.Subscribe(func(e *model.EventLog) error {
+		calls = append(calls, "first:"+e.TxHash)
+		return nil
+	}, "GrantAwarded")
+	svc.Subscribe(func(e *model.EventLog) error {
+		calls = append(calls, "second:"+e.TxHash)
+		return nil
+	}, "GrantAwarded", "GrantRevoked")
+
+	require.True(t, svc.HandlesEvent("GrantAwarded"))
+	require.True(t, svc.HandlesEvent("GrantRevoked"))
+	require.NoError(t, svc.ProcessEvent(eventLog))
+	assert.Equal(t, []string{"first:0xcustom", "second:0xcustom"}, calls)
+}
+
+func TestReplay_RetriesFailedEvents(t *testing.T) {
+	_, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+	svc.SetReplayBackoff(time.Minute, time.Hour)
+
+	// 第一次处理失败，之后成功
+	calls := 0
+	svc.Subscribe(func(e *model.EventLog) error {
+		calls++
+		if calls == 1 {
+			return errors.New("rpc unavailable")
+		}
+		return nil
+	}, "GrantAwarded")
+
+	eventLog := &model.EventLog{TxHash: "0xreplay", BlockNumber: 10, EventName: "GrantAwarded", Status: model.EventStatusConfirmed, PayloadRaw: `{}`}
+	require.NoError(t, repo.InsertEventLog(eventLog))
+	require.Error(t, svc.ApplyEvent(eventLog))
+
+	events, err := repo.GetEventsByBlockRange(10, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.False(t, events[0].Processed)
+	assert.Equal(t, 1, events[0].Attempts)
+	assert.Equal(t, "rpc unavailable", events[0].LastError)
+	require.NotNil(t, events[0].NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *events[0].NextAttemptAt, 5*time.Second)
+
+	// 退避期内不重试，force 忽略退避
+	result, err := svc.ReplayEvents(0, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Processed)
+	result, err = svc.ReplayEvents(0, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Processed)
+
+	events, err = repo.GetEventsByBlockRange(10, 10)
+	require.NoError(t, err)
+	assert.True(t, events[0].Processed)
+	assert.Empty(t, events[0].LastError)
+	assert.Equal(t, 1, events[0].Attempts)
+}
+
+// 入库或确认器正在物化的事件已被领取，重放任务不会同时处理，也不会累加失败次数
+func TestReplay_SkipsClaimedEvents(t *testing.T) {
+	_, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	calls := 0
+	svc.Subscribe(func(e *model.EventLog) error {
+		calls++
+		return nil
+	}, "GrantAwarded")
+
+	eventLog := &model.EventLog{TxHash: "0xclaimed", BlockNumber: 10, EventName: "GrantAwarded", Status: model.EventStatusConfirmed, PayloadRaw: `{}`}
+	require.NoError(t, repo.InsertEventLog(eventLog))
+
+	now := time.Now()
+	claimed, err := repo.ClaimEvent(eventLog.ID, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// 同一事件不能被再次领取
+	claimed, err = repo.ClaimEvent(eventLog.ID, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	result, err := svc.ReplayEvents(0, false)
+	require.NoError(t, err)
+	assert.Zero(t, result.Processed)
+	assert.Zero(t, result.Failed)
+	assert.Zero(t, calls)
+
+	// 领取方中途退出时，租约到期后由重放任务接手
+	claimed, err = repo.ClaimEvent(eventLog.ID, now.Add(2*time.Minute), now.Add(-time.Second))
+	require.NoError(t, err)
+	require.True(t, claimed)
+	result, err = svc.ReplayEvents(0, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, 1, calls)
+
+	events, err := repo.GetEventsByBlockRange(10, 10)
+	require.NoError(t, err)
+	assert.True(t, events[0].Processed)
+}
+
+func TestReplay_RebuildRange(t *testing.T) {
+	router, repo := setupTestAPI(t)
+	svc := service.NewService(repo)
+
+	alice := "0xabc0000000000000000000000000000000000001"
+	bob := "0xabc0000000000000000000000000000000000002"
+	transfers := []string{
+		`{"from":"` + model.ZeroAddress + `","to":"` + alice + `","value":"1000"}`,
+		`{"from":"` + alice + `","to":"` + bob + `","value":"400"}`,
+		`{"from":"` + bob + `","to":"` + alice + `","value":"100"}`,
+	}
+	for i, payload := range transfers {
+		eventLog := &model.EventLog{
+			TxHash:      "0xrebuild",
+			LogIndex:    uint(i),
+			BlockNumber: 100 + uint64(i)*10,
+			EventName:   "TokenTransfer",
+			Status:      model.EventStatusConfirmed,
+			PayloadRaw:  payload,
+		}
+		require.NoError(t, repo.InsertEventLog(eventLog))
+		require.NoError(t, svc.ApplyEvent(eventLog))
+	}
+
+	balance := func(address string) string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/token/balances/"+address, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response["balance"].(string)
+	}
+	require.Equal(t, "700", balance(alice))
+	require.Equal(t, "300", balance(bob))
+
+	// 重建中间区块：派生记录被清空后按 event_logs 重新物化，余额保持一致
+	result, err := svc.RebuildRange(110, 115)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Reset)
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, "700", balance(alice))
+	assert.Equal(t, "300", balance(bob))
+
+	transfersInRange, err := repo.ListTokenTransfers(bob, repository.PageRequest{})
+	require.NoError(t, err)
+	assert.Len(t, transfersInRange.Items, 2)
+}
+
+func TestDeadLetters_RetryAndDiscard(t *testing.T) {
+	router, repo, svc := setupTestAPIWithService(t)
+	svc.SetDeadLetterAttempts(2)
+
+	// 处理器在修复前一直 panic
+	fixed := false
+	svc.Subscribe(func(e *model.EventLog) error {
+		if !fixed {
+			panic("unexpected payload")
+		}
+		return nil
+	}, "GrantAwarded")
+
+	eventLog := &model.EventLog{TxHash: "0xdlq", BlockNumber: 20, EventName: "GrantAwarded", Status: model.EventStatusConfirmed, PayloadRaw: `{"grant":1}`}
+	require.NoError(t, repo.InsertEventLog(eventLog))
+	require.Error(t, svc.ApplyEvent(eventLog))
+	result, err := svc.ReplayEvents(0, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+
+	// 达到上限后进入死信队列，重放任务不再重试
+	result, err = svc.ReplayEvents(0, true)
+	require.NoError(t, err)
+	assert.Zero(t, result.Failed)
+
+	call := func(method, path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, response := call("GET", "/api/admin/dead-letters")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, float64(1), response["total_estimate"])
+	letter := response["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "panic: unexpected payload", letter["error"])
+	assert.Equal(t, float64(2), letter["attempts"])
+	assert.Equal(t, `{"grant":1}`, letter["payload_raw"])
+	path := "/api/admin/dead-letters/" + strconv.Itoa(int(letter["id"].(float64)))
+
+	code, response = call("GET", path)
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, response["stack"], "handler: ")
+	assert.Contains(t, response["stack"], "goroutine")
+
+	// 修复前重试仍然失败，死信记录最新的尝试次数
+	code, response = call("POST", path+"/retry")
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, float64(3), response["dead_letter"].(map[string]interface{})["attempts"])
+
+	fixed = true
+	code, response = call("POST", path+"/retry")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, model.DeadLetterStatusResolved, response["status"])
+
+	events, err := repo.GetEventsByBlockRange(20, 20)
+	require.NoError(t, err)
+	assert.True(t, events[0].Processed)
+	assert.False(t, events[0].DeadLettered)
+
+	code, _ = call("POST", path+"/discard")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = call("GET", "/api/admin/dead-letters/999")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestDeadLetters_ListenerIngestRetry(t *testing.T) {
+	_, repo, svc := setupTestAPIWithService(t)
+
+	// 监听器入库前失败的事件以完整的 ParsedEvent 保存
+	event := &model.ParsedEvent{TxHash: "0xingest", LogIndex: 2, Block: 30, EventName: "GrantAwarded", Args: map[string]interface{}{"grant": "1"}}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	letter := &model.DeadLetter{Source: model.DeadLetterSourceListener, EventName: event.EventName, TxHash: event.TxHash, LogIndex: event.LogIndex, BlockNumber: event.Block, Attempts: 1, Error: "database is locked", PayloadRaw: string(payload)}
+	require.NoError(t, repo.InsertDeadLetter(letter))
+
+	retried, err := svc.RetryDeadLetter(letter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeadLetterStatusResolved, retried.Status)
+
+	events, err := repo.GetEventsByBlockRange(30, 30)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint(2), events[0].LogIndex)
+	assert.JSONEq(t, `{"blockTimestamp":0,"grant":"1"}`, events[0].PayloadRaw)
+
+	discarded, err := svc.DiscardDeadLetter(letter.ID)
+	assert.ErrorIs(t, err, service.ErrDeadLetterClosed)
+	assert.Equal(t, model.DeadLetterStatusResolved, discarded.Status)
+}
+
+func TestListEndpoints_CursorPagination(t *testing.T) {
+	router, repo := setupTestAPI(t)
+
+	author := "0xAbC0000000000000000000000000000000000001"
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, repo.InsertResearchData(&model.ResearchData{
+			TokenID:     strconv.Itoa(i),
+			Authors:     model.StringArray{author},
+			BlockNumber: uint64(i),
+		}))
+	}
+
+	get := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	tokenIDs := func(response map[string]interface{}) []string {
+		var ids []string
+		for _, item := range response["items"].([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["token_id"].(string))
+		}
+		return ids
+	}
+
+	// 每条列表路由使用相同的分页结构
+	for _, path := range []string{
+		"/api/research/latest?sort=block&limit=2",
+		"/api/research/by-author/" + author + "?limit=2",
+		"/api/hybrid/nfts?sort=block&limit=2",
+	} {
+		code, first := get(path)
+		require.Equal(t, http.StatusOK, code, path)
+		assert.Equal(t, float64(3), first["total_estimate"], path)
+		assert.Equal(t, []string{"3", "2"}, tokenIDs(first), path)
+		require.NotEmpty(t, first["next_cursor"], path)
+
+		code, second := get(path + "&cursor=" + first["next_cursor"].(string))
+		require.Equal(t, http.StatusOK, code, path)
+		assert.Equal(t, []string{"1"}, tokenIDs(second), path)
+		assert.Equal(t, "", second["next_cursor"], path)
+	}
+
+	code, response := get("/api/research/latest?cursor=bogus")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "invalid cursor", response["error"])
+	code, _ = get("/api/token/transfers?sort=value")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, response = get("/api/datasets?wallet_address=0xowner")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response["items"])
+	assert.Equal(t, float64(0), response["total_estimate"])
+}
+
+func TestSearchEndpoint(t *testing.T) {
+	router, repo := setupTestAPI(t)
+
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "1", Title: "Protein folding", Authors: model.StringArray{"alice"}, Owner: "0xa", BlockNumber: 10}))
+	require.NoError(t, repo.InsertResearchData(&model.ResearchData{TokenID: "2", Title: "Climate models", Authors: model.StringArray{"bob"}, Owner: "0xb", BlockNumber: 20}))
+	require.NoError(t, repo.InsertDatasetRecord(&model.DatasetRecord{DatasetID: "ds-1", Title: "Protein structures", Description: "Cryo-EM maps", Owner: "0xa", BlockNumber: 15}))
+
+	get := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, response := get("/api/search?q=protein&limit=1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "protein", response["query"])
+	assert.Equal(t, float64(2), response["total_estimate"])
+	items := response["items"].([]interface{})
+	require.Len(t, items, 1)
+	first := items[0].(map[string]interface{})
+	assert.Contains(t, first["title_highlight"], "<mark>Protein</mark>")
+	require.NotEmpty(t, response["next_cursor"])
+
+	code, response = get("/api/search?q=protein&limit=1&cursor=" + response["next_cursor"].(string))
+	require.Equal(t, http.StatusOK, code)
+	second := response["items"].([]interface{})[0].(map[string]interface{})
+	assert.NotEqual(t, first["id"], second["id"])
+	assert.Equal(t, "", response["next_cursor"])
+
+	code, response = get("/api/search?q=protein&type=dataset&owner=0xA&from_block=12&to_block=20")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response["items"], 1)
+	assert.Equal(t, "ds-1", response["items"].([]interface{})[0].(map[string]interface{})["id"])
+
+	for _, path := range []string{"/api/search", "/api/search?q=protein&type=user", "/api/search?q=protein&from_block=x", "/api/search?q=protein&order=asc"} {
+		code, _ = get(path)
+		assert.Equal(t, http.StatusBadRequest, code, path)
+	}
+}
+
+func TestAdminRoutes_RequireToken(t *testing.T) {
+	router, repo, svc := setupTestAPIWithService(t)
+	require.NoError(t, repo.InsertDeadLetter(&model.DeadLetter{Source: model.DeadLetterSourceListener, EventName: "GrantAwarded", TxHash: "0xauth", Error: "boom"}))
+
+	call := func(router http.Handler, method, path, auth string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/admin/dead-letters"},
+		{"GET", "/api/admin/dead-letters/1"},
+		{"POST", "/api/admin/dead-letters/1/retry"},
+		{"POST", "/api/admin/dead-letters/1/discard"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, call(router, route.method, route.path, ""), route.path)
+		assert.Equal(t, http.StatusUnauthorized, call(router, route.method, route.path, "Bearer wrong"), route.path)
+		assert.Equal(t, http.StatusUnauthorized, call(router, route.method, route.path, testAdminToken), route.path)
+	}
+	// 未授权的调用不会改变死信状态
+	letter, err := repo.GetDeadLetter(1)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeadLetterStatusDead, letter.Status)
+	assert.Equal(t, http.StatusOK, call(router, "GET", "/api/admin/dead-letters", "Bearer "+testAdminToken))
+
+	// 未配置令牌时管理接口全部拒绝
+	disabled := api.NewHandler(svc, repo).SetupRoutes()
+	assert.Equal(t, http.StatusForbidden, call(disabled, "GET", "/api/admin/dead-letters", ""))
+	assert.Equal(t, http.StatusForbidden, call(disabled, "POST", "/api/admin/dead-letters/1/discard", "Bearer "))
+}
+
+// 模拟接口需要管理令牌，且模拟的证明不进入证明投影，不会抢占链上的 proofId
+func TestSimulateProofEvent_RequiresAdminAndSkipsProjection(t *testing.T) {
+	router, repo := setupTestAPI(t)
+
+	simulate := func(auth, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/events/simulate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	body := `{"eventName":"ProofSubmitted","proofId":5,"submitter":"0xAbC0000000000000000000000000000000000001","blockNumber":10,"txHash":"0xsim"}`
+
+	assert.Equal(t, http.StatusUnauthorized, simulate("", body))
+	assert.Equal(t, http.StatusBadRequest, simulate("Bearer "+testAdminToken, `{"eventName":"ProofVerified","proofId":5}`))
+	assert.Equal(t, http.StatusOK, simulate("Bearer "+testAdminToken, body))
+
+	_, err := repo.GetProof("5")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}